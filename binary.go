@@ -0,0 +1,146 @@
+package fuego
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// FileResponse is a binary response with metadata.
+// Controllers can return it (or directly an [io.Reader], [io.ReadSeeker], [fs.File] or []byte)
+// to send binary content instead of a serialized value.
+// Example:
+//
+//	func downloadReport(c fuego.ContextNoBody) (*fuego.FileResponse, error) {
+//		f, err := os.Open("report.pdf")
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &fuego.FileResponse{Content: f, Name: "report.pdf", ContentType: "application/pdf"}, nil
+//	}
+//
+// If Content implements [io.Seeker], Range and conditional requests are supported (see [http.ServeContent]).
+// If Content implements [io.Closer], it is closed once the response is sent.
+type FileResponse struct {
+	// Content of the file. Implement [io.Seeker] to support Range requests.
+	Content io.Reader
+	// Name of the file, used for the Content-Disposition header and to infer the Content-Type.
+	Name string
+	// Content-Type of the file. If empty, inferred from the Name extension, then from the content.
+	ContentType string
+	// Last modification time, used for the Last-Modified header and If-Modified-Since/If-Range requests.
+	ModTime time.Time
+	// Size of the content in bytes. Only used when Content is not seekable, to set the Content-Length header.
+	Size int64
+	// If true, the Content-Disposition is "inline" (displayed by the browser) instead of "attachment" (downloaded).
+	Inline bool
+}
+
+var (
+	readerType       = reflect.TypeFor[io.Reader]()
+	bytesType        = reflect.TypeFor[[]byte]()
+	fileResponseType = reflect.TypeFor[FileResponse]()
+)
+
+// isBinaryType returns true if the type is sent as binary by [Send].
+func isBinaryType(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Pointer && t.Elem() == fileResponseType {
+		return true
+	}
+	return t == fileResponseType || t == bytesType || t.Implements(readerType)
+}
+
+// isBinary returns true if the value is sent as binary by [Send].
+func isBinary(ans any) bool {
+	return isBinaryType(reflect.TypeOf(ans))
+}
+
+// toFileResponse converts a binary value to a [FileResponse], using [fs.File] information if available.
+func toFileResponse(ans any) FileResponse {
+	switch v := ans.(type) {
+	case FileResponse:
+		return v
+	case *FileResponse:
+		if v == nil {
+			return FileResponse{}
+		}
+		return *v
+	case []byte:
+		return FileResponse{Content: bytes.NewReader(v)}
+	case fs.File:
+		file := FileResponse{Content: v}
+		if info, err := v.Stat(); err == nil {
+			file.Name = info.Name()
+			file.ModTime = info.ModTime()
+			file.Size = info.Size()
+		}
+		return file
+	case io.Reader:
+		return FileResponse{Content: v}
+	}
+	return FileResponse{}
+}
+
+// SendBinary sends binary content: a [FileResponse], an [io.Reader], an [io.ReadSeeker], an [fs.File] or []byte.
+// It sets the Content-Type, Content-Disposition, Content-Length and Last-Modified headers when the information is available.
+// Seekable content is sent with [http.ServeContent], which handles Range, If-Range and conditional requests.
+func SendBinary(w http.ResponseWriter, r *http.Request, ans any) error {
+	file := toFileResponse(ans)
+	if file.Content == nil {
+		return nil
+	}
+	if closer, ok := file.Content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	contentType := file.ContentType
+	if contentType == "" && file.Name != "" {
+		contentType = mime.TypeByExtension(filepath.Ext(file.Name))
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	if file.Name != "" {
+		disposition := "attachment"
+		if file.Inline {
+			disposition = "inline"
+		}
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": file.Name}))
+	}
+
+	if seeker, ok := file.Content.(io.ReadSeeker); ok {
+		// Sniffs the content type if unknown
+		http.ServeContent(w, r, file.Name, file.ModTime, seeker)
+		return nil
+	}
+
+	if contentType == "" {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	if !file.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", file.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if file.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	}
+
+	_, err := io.Copy(w, file.Content)
+	return err
+}
+
+// newBinarySchema documents a binary response.
+func newBinarySchema() *openapi3.Schema {
+	return openapi3.NewStringSchema().WithFormat("binary")
+}
@@ -0,0 +1,152 @@
+package fuego
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendBinary(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("[]byte", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, []byte("hello"))
+		require.NoError(t, err)
+		require.Equal(t, "hello", w.Body.String())
+		require.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "5", w.Header().Get("Content-Length"))
+	})
+
+	t.Run("non seekable reader", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, io.MultiReader(strings.NewReader("hello")))
+		require.NoError(t, err)
+		require.Equal(t, "hello", w.Body.String())
+		require.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("FileResponse with metadata", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, &FileResponse{
+			Content: strings.NewReader("a,b\n1,2\n"),
+			Name:    "export.csv",
+			ModTime: modTime,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "a,b\n1,2\n", w.Body.String())
+		require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, `attachment; filename=export.csv`, w.Header().Get("Content-Disposition"))
+		require.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", w.Header().Get("Last-Modified"))
+		require.Equal(t, "8", w.Header().Get("Content-Length"))
+	})
+
+	t.Run("inline FileResponse with explicit content type", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, FileResponse{
+			Content:     io.MultiReader(strings.NewReader("%PDF")),
+			Name:        "report.pdf",
+			ContentType: "application/pdf",
+			Inline:      true,
+			Size:        4,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		require.Equal(t, `inline; filename=report.pdf`, w.Header().Get("Content-Disposition"))
+		require.Equal(t, "4", w.Header().Get("Content-Length"))
+	})
+
+	t.Run("Range request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Range", "bytes=6-10")
+
+		err := Send(w, r, &FileResponse{Content: strings.NewReader("hello world"), Name: "hello.txt"})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusPartialContent, w.Code)
+		require.Equal(t, "world", w.Body.String())
+		require.Equal(t, "bytes 6-10/11", w.Header().Get("Content-Range"))
+	})
+
+	t.Run("If-Range with outdated date sends the whole content", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Range", "bytes=6-10")
+		r.Header.Set("If-Range", modTime.Add(-time.Hour).Format(http.TimeFormat))
+
+		err := Send(w, r, &FileResponse{Content: strings.NewReader("hello world"), ModTime: modTime})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "hello world", w.Body.String())
+	})
+
+	t.Run("fs.File", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"image.svg": &fstest.MapFile{Data: []byte("<svg></svg>"), ModTime: modTime},
+		}
+		f, err := fsys.Open("image.svg")
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+
+		err = Send(w, r, f)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Equal(t, `attachment; filename=image.svg`, w.Header().Get("Content-Disposition"))
+	})
+}
+
+func TestBinaryController(t *testing.T) {
+	s := NewServer()
+
+	route := Get(s, "/file", func(c ContextNoBody) (fs.File, error) {
+		return fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("content")}}.Open("a.txt")
+	})
+
+	pdfRoute := Get(s, "/pdf", func(c ContextNoBody) (*FileResponse, error) {
+		return &FileResponse{Content: strings.NewReader("%PDF"), ContentType: "application/pdf"}, nil
+	}, OptionAddResponse(http.StatusOK, "PDF report", Response{Type: FileResponse{}, ContentTypes: []string{"application/pdf"}}))
+
+	t.Run("sends the file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/file", nil)
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "content", w.Body.String())
+	})
+
+	t.Run("documents binary responses", func(t *testing.T) {
+		content := route.Operation.Responses.Value("200").Value.Content
+		require.Len(t, content, 1)
+		schema := content.Get("application/octet-stream").Schema.Value
+		assert.True(t, schema.Type.Is("string"))
+		assert.Equal(t, "binary", schema.Format)
+	})
+
+	t.Run("documents binary responses with custom content type", func(t *testing.T) {
+		content := pdfRoute.Operation.Responses.Value("200").Value.Content
+		require.Len(t, content, 1)
+		schema := content.Get("application/pdf").Schema.Value
+		assert.Equal(t, "binary", schema.Format)
+	})
+}
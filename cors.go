@@ -0,0 +1,310 @@
+package fuego
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CORSConfig is the Cross-Origin Resource Sharing policy of routes. See [OptionCORS].
+type CORSConfig struct {
+	// Origins allowed to call the routes, like "https://example.com".
+	// "*" allows all origins, and a single wildcard matches subdomains, like "https://*.example.com".
+	// Defaults to all origins if AllowedOrigins and AllowOriginFunc are both empty.
+	AllowedOrigins []string
+	// AllowOriginFunc decides if an origin is allowed, in addition to AllowedOrigins.
+	AllowOriginFunc func(origin string) bool
+	// Request headers allowed in the requests. Defaults to the headers requested by the preflight.
+	AllowedHeaders []string
+	// Response headers readable by the browser, in addition to the response headers documented on the route,
+	// for example with [OptionResponseHeader].
+	ExposedHeaders []string
+	// If true, the browser sends the cookies and the Authorization header with the requests.
+	// The allowed origins must then be explicit: AllowedOrigins without "*", or AllowOriginFunc.
+	AllowCredentials bool
+	// How long the browser can cache the result of a preflight request. 0 lets the browser decide.
+	MaxAge time.Duration
+}
+
+// OptionCORS sets the Cross-Origin Resource Sharing policy of the route, or of all the routes of a group.
+// Use it with [WithRouteOptions] to set a server-wide policy, and on groups for per-group policies.
+//
+//   - Preflight requests (OPTIONS with Access-Control-Request-Method) are answered for the path of the route,
+//     without running the route middlewares, with the methods registered on the path
+//     whose policy allows the origin in Access-Control-Allow-Methods.
+//   - Responses to allowed origins get Access-Control-Allow-Origin, and the response headers documented on the route,
+//     for example with [OptionResponseHeader], in Access-Control-Expose-Headers.
+//
+// It replaces a global CORS middleware registered with [WithGlobalMiddlewares] for the net/http server.
+// It panics if AllowCredentials is set without explicit origins, as any website could then
+// make requests with the cookies of the users.
+func OptionCORS(config CORSConfig) RouteOption {
+	if config.AllowCredentials && (slices.Contains(config.AllowedOrigins, "*") ||
+		len(config.AllowedOrigins) == 0 && config.AllowOriginFunc == nil) {
+		panic("CORS: AllowCredentials requires explicit AllowedOrigins, without \"*\", or an AllowOriginFunc")
+	}
+	return func(r *BaseRoute) {
+		r.CORS = &config
+	}
+}
+
+// allowsOrigin reports whether the origin is allowed by the policy.
+func (c *CORSConfig) allowsOrigin(origin string) bool {
+	if len(c.AllowedOrigins) == 0 && c.AllowOriginFunc == nil {
+		return true
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if prefix, suffix, found := strings.Cut(allowed, "*"); found &&
+			len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+			return true
+		}
+	}
+	return c.AllowOriginFunc != nil && c.AllowOriginFunc(origin)
+}
+
+// allowsAllOrigins reports whether the policy can answer with the "*" origin.
+func (c *CORSConfig) allowsAllOrigins() bool {
+	if c.AllowCredentials || c.AllowOriginFunc != nil {
+		return false
+	}
+	return len(c.AllowedOrigins) == 0 || slices.Contains(c.AllowedOrigins, "*")
+}
+
+// setAllowOrigin sets the Access-Control-Allow-Origin and Access-Control-Allow-Credentials headers
+// if the origin is allowed, and reports whether it is.
+func (c *CORSConfig) setAllowOrigin(header http.Header, origin string) bool {
+	if c.allowsAllOrigins() {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return true
+	}
+
+	AddVary(header, "Origin")
+	if !c.allowsOrigin(origin) {
+		return false
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+	if c.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
+// corsMiddleware adds the CORS headers to the responses of the route.
+// The exposed headers are read from the operation on the first request,
+// once the route is documented with the headers set by its options, like ETag or Deprecation.
+func corsMiddleware(config *CORSConfig, operation *openapi3.Operation) func(http.Handler) http.Handler {
+	exposed := sync.OnceValue(func() string {
+		return strings.Join(exposedHeaders(config, operation), ", ")
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin != "" && config.setAllowOrigin(w.Header(), origin) && exposed() != "" {
+				w.Header().Set("Access-Control-Expose-Headers", exposed())
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// exposedHeaders returns the response headers to expose to the browser:
+// the ones of the policy and the ones documented on the operation.
+func exposedHeaders(config *CORSConfig, operation *openapi3.Operation) []string {
+	headers := []string{}
+	add := func(name string) {
+		name = http.CanonicalHeaderKey(name)
+		if !slices.Contains(headers, name) {
+			headers = append(headers, name)
+		}
+	}
+
+	for _, name := range config.ExposedHeaders {
+		add(name)
+	}
+
+	if operation != nil && operation.Responses != nil {
+		documented := []string{}
+		for _, response := range operation.Responses.Map() {
+			if response.Value == nil {
+				continue
+			}
+			for name := range response.Value.Headers {
+				documented = append(documented, name)
+			}
+		}
+		slices.Sort(documented)
+		for _, name := range documented {
+			add(name)
+		}
+	}
+
+	return headers
+}
+
+// corsRoutes keeps the routes registered on each path, to answer the preflight requests of the routes with a CORS policy.
+// It is shared by the server and its groups.
+type corsRoutes struct {
+	mu    sync.RWMutex
+	paths map[string]*corsPath
+}
+
+// corsPath is the OPTIONS handler of a path.
+type corsPath struct {
+	routes *corsRoutes
+	// methods registered on the path, in registration order. "" is a route matching all methods.
+	methods []string
+	// policies of the methods, nil if the route has no CORS policy.
+	policies map[string]*CORSConfig
+	// handlers of the routes answering the OPTIONS requests that are not preflight requests.
+	options, any http.Handler
+	// mounted is true once the path is registered on the mux.
+	mounted bool
+}
+
+func newCORSRoutes() *corsRoutes {
+	return &corsRoutes{paths: map[string]*corsPath{}}
+}
+
+// register records the route, and mounts the OPTIONS handler of its path on the mux
+// if the path has a CORS policy or an OPTIONS route. The OPTIONS routes are served by this handler.
+// Reports whether the route is handled, so that it must not be registered on the mux.
+func (c *corsRoutes) register(mux *http.ServeMux, method, path string, policy *CORSConfig, handler http.Handler) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := c.paths[path]
+	if p == nil {
+		p = &corsPath{routes: c, policies: map[string]*CORSConfig{}}
+		c.paths[path] = p
+	}
+
+	if !slices.Contains(p.methods, method) {
+		p.methods = append(p.methods, method)
+	}
+	p.policies[method] = policy
+
+	switch method {
+	case http.MethodOptions:
+		p.options = handler
+	case "":
+		p.any = handler
+	}
+
+	if !p.mounted && (policy != nil || method == http.MethodOptions) {
+		p.mounted = true
+		mux.Handle(http.MethodOptions+" "+path, p)
+	}
+
+	return method == http.MethodOptions
+}
+
+func (p *corsPath) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.routes.mu.RLock()
+	origin := r.Header.Get("Origin")
+	requestedMethod := r.Header.Get("Access-Control-Request-Method")
+	if origin != "" && requestedMethod != "" {
+		if policy := p.policy(requestedMethod); policy != nil {
+			p.preflight(w, r, policy, origin)
+			p.routes.mu.RUnlock()
+			return
+		}
+	}
+	options, anyMethod, allowed := p.options, p.any, p.allowedMethods("")
+	p.routes.mu.RUnlock()
+
+	switch {
+	case options != nil:
+		options.ServeHTTP(w, r)
+	case anyMethod != nil:
+		anyMethod.ServeHTTP(w, r)
+	default:
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// policy returns the CORS policy of the route handling the method on the path.
+func (p *corsPath) policy(method string) *CORSConfig {
+	if method == http.MethodHead && !slices.Contains(p.methods, http.MethodHead) {
+		method = http.MethodGet
+	}
+	if slices.Contains(p.methods, method) {
+		return p.policies[method]
+	}
+	return p.policies[""]
+}
+
+// allowedMethods returns the methods registered on the path.
+// If origin is not empty, only the methods whose policy allows the origin are returned.
+func (p *corsPath) allowedMethods(origin string) []string {
+	methods := []string{}
+	add := func(method string) {
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+
+	for _, method := range p.methods {
+		if origin != "" {
+			policy := p.policies[method]
+			if policy == nil || !policy.allowsOrigin(origin) {
+				continue
+			}
+		}
+		switch method {
+		case "":
+			for _, m := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+				add(m)
+			}
+		case http.MethodGet:
+			add(http.MethodGet)
+			add(http.MethodHead)
+		default:
+			add(method)
+		}
+	}
+	if origin == "" {
+		add(http.MethodOptions)
+	}
+
+	return methods
+}
+
+// preflight answers a preflight request.
+func (p *corsPath) preflight(w http.ResponseWriter, r *http.Request, policy *CORSConfig, origin string) {
+	header := w.Header()
+	AddVary(header, "Access-Control-Request-Method")
+	AddVary(header, "Access-Control-Request-Headers")
+
+	if !policy.setAllowOrigin(header, origin) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	header.Set("Access-Control-Allow-Methods", strings.Join(p.allowedMethods(origin), ", "))
+
+	switch requested := r.Header.Get("Access-Control-Request-Headers"); {
+	case len(policy.AllowedHeaders) > 0 && !slices.Contains(policy.AllowedHeaders, "*"):
+		header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+	case requested != "":
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+
+	if policy.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
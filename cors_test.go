@@ -0,0 +1,98 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSConfigAllowsOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CORSConfig
+		allowed []string
+		denied  []string
+	}{
+		{
+			name:    "all origins by default",
+			config:  CORSConfig{},
+			allowed: []string{"https://example.com", "http://localhost:3000"},
+		},
+		{
+			name:    "exact origins",
+			config:  CORSConfig{AllowedOrigins: []string{"https://example.com"}},
+			allowed: []string{"https://example.com", "https://EXAMPLE.com"},
+			denied:  []string{"https://example.com.evil.com", "http://example.com", "https://api.example.com"},
+		},
+		{
+			name:    "subdomain wildcard",
+			config:  CORSConfig{AllowedOrigins: []string{"https://*.example.com"}},
+			allowed: []string{"https://api.example.com", "https://a.b.example.com"},
+			denied:  []string{"https://example.com", "https://.example.com", "https://evilexample.com", "http://api.example.com"},
+		},
+		{
+			name: "origin func",
+			config: CORSConfig{
+				AllowedOrigins:  []string{"https://example.com"},
+				AllowOriginFunc: func(origin string) bool { return strings.HasPrefix(origin, "http://localhost:") },
+			},
+			allowed: []string{"https://example.com", "http://localhost:5173"},
+			denied:  []string{"https://localhost:5173"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, origin := range tt.allowed {
+				assert.True(t, tt.config.allowsOrigin(origin), origin)
+			}
+			for _, origin := range tt.denied {
+				assert.False(t, tt.config.allowsOrigin(origin), origin)
+			}
+		})
+	}
+}
+
+func TestCORSConfigAllowsAllOrigins(t *testing.T) {
+	assert.True(t, (&CORSConfig{}).allowsAllOrigins())
+	assert.True(t, (&CORSConfig{AllowedOrigins: []string{"*"}}).allowsAllOrigins())
+	assert.False(t, (&CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}).allowsAllOrigins(), "the origin is reflected with credentials")
+	assert.False(t, (&CORSConfig{AllowedOrigins: []string{"https://example.com"}}).allowsAllOrigins())
+}
+
+func TestOptionCORSCredentials(t *testing.T) {
+	assert.Panics(t, func() { OptionCORS(CORSConfig{AllowCredentials: true}) }, "all origins by default")
+	assert.Panics(t, func() {
+		OptionCORS(CORSConfig{AllowedOrigins: []string{"https://example.com", "*"}, AllowCredentials: true})
+	})
+	assert.NotPanics(t, func() {
+		OptionCORS(CORSConfig{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true})
+	})
+	assert.NotPanics(t, func() {
+		OptionCORS(CORSConfig{AllowOriginFunc: func(string) bool { return true }, AllowCredentials: true})
+	})
+}
+
+func TestCORSExposesDocumentedHeaders(t *testing.T) {
+	s := NewServer()
+	Get(s, "/pets", func(c ContextNoBody) (string, error) {
+		return "pets", nil
+	},
+		OptionCORS(CORSConfig{ExposedHeaders: []string{"X-Request-Id"}}),
+		OptionETag(),
+		OptionDeprecation(Deprecation{Sunset: time.Now().Add(time.Hour), Link: "https://example.com/deprecation"}),
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/pets", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "X-Request-Id, Deprecation, Etag, Link, Sunset", w.Header().Get("Access-Control-Expose-Headers"))
+}
@@ -0,0 +1,471 @@
+package fuego
+
+import (
+	"context"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentTypeCSV is the content type used to send and read lists of structs as CSV.
+const ContentTypeCSV = "text/csv"
+
+// csvMaxDepth limits the flattening of nested structs, to avoid infinite recursion on recursive types.
+const csvMaxDepth = 5
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// csvColumn is a column of a CSV document, mapped to a (possibly nested) struct field.
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// isCSVStruct returns true if the type is a struct (or a pointer to a struct) that can be a CSV row.
+func isCSVStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !t.Implements(textMarshalerType) && !reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// csvElemType returns the row type of a type that can be sent as CSV:
+// slices and arrays of structs, and streams of structs (see [SendStream]).
+func csvElemType(t reflect.Type) (reflect.Type, bool) {
+	if t == nil {
+		return nil, false
+	}
+	elemType, ok := streamElemType(t)
+	if !ok {
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil, false
+		}
+		elemType = t.Elem()
+	}
+	if !isCSVStruct(elemType) {
+		return nil, false
+	}
+	return elemType, true
+}
+
+// csvColumns returns the columns of a row type.
+// Column names come from the `csv` tag, then the `json` tag, then the field name.
+// Nested structs are flattened with dot-separated names, embedded structs are flattened without prefix.
+func csvColumns(t reflect.Type) []csvColumn {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return appendCSVColumns(nil, t, "", nil, csvMaxDepth)
+}
+
+func appendCSVColumns(columns []csvColumn, t reflect.Type, prefix string, index []int, maxDepth int) []csvColumn {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("csv"), ",")
+		if name == "" {
+			name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+		}
+		if name == "-" {
+			continue
+		}
+
+		fieldIndex := append(slices.Clone(index), i)
+		nested := isCSVStruct(field.Type) && maxDepth > 0
+		if field.Anonymous && name == "" && nested {
+			columns = appendCSVColumns(columns, derefType(field.Type), prefix, fieldIndex, maxDepth-1)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if nested {
+			columns = appendCSVColumns(columns, derefType(field.Type), prefix+name+".", fieldIndex, maxDepth-1)
+			continue
+		}
+		columns = append(columns, csvColumn{name: prefix + name, index: fieldIndex})
+	}
+	return columns
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// csvField returns the field at the given index, or an invalid value if a nested pointer is nil.
+func csvField(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// csvFieldAlloc returns the field at the given index, allocating nested nil pointers.
+func csvFieldAlloc(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// formatCSVValue formats a field as a CSV cell.
+// Primitive types are formatted as text, [encoding.TextMarshaler] is used if implemented, and other types are written as JSON.
+func formatCSVValue(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, bitSize(v.Kind())), nil
+	}
+
+	data, err := json.Marshal(v.Interface())
+	return string(data), err
+}
+
+// parseCSVValue parses a CSV cell into a field. It is the inverse of [formatCSVValue].
+// Empty cells leave the field to its zero value.
+func parseCSVValue(v reflect.Value, cell string) error {
+	if cell == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(cell))
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return setParamValue(v, cell, v.Kind())
+	}
+
+	return json.Unmarshal([]byte(cell), v.Addr().Interface())
+}
+
+// SendCSV sends a slice of structs as CSV.
+// The first row contains the column names, taken from the `csv` or `json` tags.
+// Nested structs are flattened with dot-separated column names (ex: "address.city").
+// Rows are written and flushed progressively, so large slices do not need to be buffered.
+func SendCSV(w http.ResponseWriter, r *http.Request, ans any) error {
+	v := reflect.ValueOf(ans)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return NotAcceptableError{
+			Detail: fmt.Sprintf("cannot serialize type %T to CSV: expected a slice of structs", ans),
+		}
+	}
+	elemType, ok := csvElemType(v.Type())
+	if !ok {
+		return NotAcceptableError{
+			Detail: fmt.Sprintf("cannot serialize type %T to CSV: expected a slice of structs", ans),
+		}
+	}
+
+	return writeCSV(w, r, elemType, false, func(yield func(any, error) bool) {
+		for i := range v.Len() {
+			if !yield(v.Index(i).Interface(), nil) {
+				return
+			}
+		}
+	})
+}
+
+// writeCSV writes rows as CSV, flushing periodically.
+// Errors happening before the first row are returned. After that, if streamed is true,
+// they are reported in the [StreamErrorTrailer] trailer.
+func writeCSV(w http.ResponseWriter, r *http.Request, elemType reflect.Type, streamed bool, rows func(yield func(any, error) bool)) error {
+	ctx := requestContext(r)
+	columns := csvColumns(elemType)
+	csvWriter := csv.NewWriter(w)
+	flusher, _ := w.(http.Flusher)
+
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", ContentTypeCSV+"; charset=utf-8")
+		if streamed {
+			w.Header().Add("Trailer", StreamErrorTrailer)
+		}
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.name
+		}
+		return csvWriter.Write(header)
+	}
+
+	record := make([]string, len(columns))
+	lastFlush := time.Now()
+	unflushed := 0
+
+	var rowsErr error
+	rows(func(row any, err error) bool {
+		if err == nil {
+			err = fillCSVRecord(record, columns, reflect.ValueOf(row))
+		}
+		if err != nil {
+			rowsErr = err
+			return false
+		}
+
+		if !started {
+			if err = start(); err != nil {
+				return false
+			}
+		}
+		if err = csvWriter.Write(record); err != nil {
+			slog.DebugContext(ctx, "CSV response interrupted", "error", err)
+			return false
+		}
+
+		unflushed++
+		if unflushed >= streamFlushEvery || time.Since(lastFlush) >= streamFlushInterval {
+			csvWriter.Flush()
+			if flusher != nil {
+				flusher.Flush()
+			}
+			lastFlush = time.Now()
+			unflushed = 0
+		}
+		return true
+	})
+
+	if !started {
+		if rowsErr != nil {
+			return rowsErr
+		}
+		if err := start(); err != nil {
+			return err
+		}
+	} else if rowsErr != nil && streamed {
+		setStreamErrorTrailer(ctx, w, rowsErr)
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		slog.DebugContext(ctx, "CSV response interrupted", "error", err)
+	}
+	return nil
+}
+
+// fillCSVRecord fills the record with the cells of a row.
+func fillCSVRecord(record []string, columns []csvColumn, row reflect.Value) error {
+	for i, column := range columns {
+		cell, err := formatCSVValue(csvField(row, column.index))
+		if err != nil {
+			return fmt.Errorf("cannot serialize column %s to CSV: %w", column.name, err)
+		}
+		record[i] = cell
+	}
+	return nil
+}
+
+// ReadCSV reads the request body as CSV into a slice of structs.
+// Can be used independently of Fuego framework.
+// Customizable by modifying ReadOptions.
+func ReadCSV[B any](ctx context.Context, input io.Reader) (B, error) {
+	return readCSV[B](ctx, input, ReadOptions)
+}
+
+// readCSV reads the request body as CSV into a slice of structs.
+// The first row must contain the column names, as written by [SendCSV].
+// Each row is transformed and validated: the errors of all rows are reported together,
+// with the line of the row in the CSV document in the [ErrorItem] (the header is line 1).
+func readCSV[B any](ctx context.Context, input io.Reader, options readOptions) (B, error) {
+	var body B
+
+	bodyType := reflect.TypeFor[B]()
+	elemType, ok := csvElemType(bodyType)
+	if !ok || bodyType.Kind() != reflect.Slice {
+		return body, BadRequestError{
+			Title:  "Decoding Failed",
+			Detail: fmt.Sprintf("cannot decode CSV request body into %T: expected a slice of structs", body),
+		}
+	}
+
+	reader := csv.NewReader(input)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return TransformAndValidate(ctx, body)
+	}
+	if err != nil {
+		return body, csvDecodingError(err)
+	}
+
+	allColumns := csvColumns(elemType)
+	columns := make([]*csvColumn, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // UTF-8 BOM added by some spreadsheet software
+		}
+		index := slices.IndexFunc(allColumns, func(column csvColumn) bool { return column.name == name })
+		if index >= 0 {
+			columns[i] = &allColumns[index]
+			continue
+		}
+		if options.DisallowUnknownFields {
+			return body, BadRequestError{
+				Title:  "Decoding Failed",
+				Detail: "unknown CSV column: " + name,
+				Errors: []ErrorItem{{Name: name, Reason: "unknown column", More: map[string]any{"row": 1}}},
+			}
+		}
+	}
+
+	rows := reflect.MakeSlice(bodyType, 0, 0)
+	var errorItems []ErrorItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return body, csvDecodingError(err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := reflect.New(derefType(elemType))
+		for i, cell := range record {
+			if columns[i] == nil || cell == "" {
+				continue
+			}
+			err := parseCSVValue(csvFieldAlloc(row.Elem(), columns[i].index), cell)
+			if err != nil {
+				errorItems = append(errorItems, csvErrorItem(line, columns[i].name, "cannot parse "+strconv.Quote(cell)+": "+err.Error(), nil))
+			}
+		}
+		errorItems = append(errorItems, transformAndValidateCSVRow(ctx, line, row)...)
+
+		if elemType.Kind() == reflect.Pointer {
+			rows = reflect.Append(rows, row)
+		} else {
+			rows = reflect.Append(rows, row.Elem())
+		}
+	}
+	body = rows.Interface().(B)
+	slog.DebugContext(ctx, "Decoded CSV body", "rows", rows.Len())
+
+	if len(errorItems) > 0 {
+		return body, HTTPError{
+			Err:    errors.New("invalid CSV rows"),
+			Status: http.StatusBadRequest,
+			Title:  "Validation Error",
+			Detail: fmt.Sprintf("%d errors in CSV request body", len(errorItems)),
+			Errors: errorItems,
+		}
+	}
+
+	return TransformAndValidate(ctx, body)
+}
+
+// transformAndValidateCSVRow transforms and validates a row, and returns its errors.
+func transformAndValidateCSVRow(ctx context.Context, line int, row reflect.Value) []ErrorItem {
+	if inTransformer, ok := row.Interface().(InTransformer); ok {
+		if err := inTransformer.InTransform(ctx); err != nil {
+			return []ErrorItem{csvErrorItem(line, "transformation", "transformation failed: "+err.Error(), nil)}
+		}
+	}
+
+	err := validate(row.Elem().Interface())
+	if err == nil {
+		return nil
+	}
+	var validationError HTTPError
+	if !errors.As(err, &validationError) {
+		return []ErrorItem{csvErrorItem(line, "validation", err.Error(), nil)}
+	}
+	errorItems := make([]ErrorItem, 0, len(validationError.Errors))
+	for _, item := range validationError.Errors {
+		errorItems = append(errorItems, csvErrorItem(line, item.Name, item.Reason, item.More))
+	}
+	return errorItems
+}
+
+// csvErrorItem returns an [ErrorItem] for a row, with the line number in its name and in More["row"].
+func csvErrorItem(line int, name, reason string, more map[string]any) ErrorItem {
+	more = maps.Clone(more)
+	if more == nil {
+		more = make(map[string]any, 1)
+	}
+	more["row"] = line
+	return ErrorItem{
+		Name:   fmt.Sprintf("row %d: %s", line, name),
+		Reason: reason,
+		More:   more,
+	}
+}
+
+func csvDecodingError(err error) error {
+	return BadRequestError{
+		Title:  "Decoding Failed",
+		Err:    err,
+		Detail: "cannot decode CSV request body: " + err.Error(),
+	}
+}
+
+// withCSVContentType adds [ContentTypeCSV] to the content types if the type can be sent or read as CSV.
+// Empty content types are kept empty, as they already mean "*/*".
+func withCSVContentType(contentTypes []string, t reflect.Type) []string {
+	if len(contentTypes) == 0 || slices.Contains(contentTypes, ContentTypeCSV) {
+		return contentTypes
+	}
+	if _, ok := csvElemType(t); !ok || t.Kind() != reflect.Slice {
+		return contentTypes
+	}
+	return append(slices.Clone(contentTypes), ContentTypeCSV)
+}
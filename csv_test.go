@@ -0,0 +1,173 @@
+package fuego
+
+import (
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type csvAddress struct {
+	City    string `json:"city"`
+	Country string `json:"country,omitempty"`
+}
+
+type csvPet struct {
+	ID        int         `json:"id"`
+	Name      string      `json:"name" csv:"pet_name" validate:"required"`
+	Tags      []string    `json:"tags"`
+	Address   csvAddress  `json:"address"`
+	Previous  *csvAddress `json:"previous"`
+	BirthDate time.Time   `json:"birth_date"`
+	Weight    *float64    `json:"weight"`
+	Secret    string      `json:"-"`
+}
+
+func TestSendCSV(t *testing.T) {
+	weight := 3.5
+	pets := []csvPet{
+		{ID: 1, Name: "Rex", Tags: []string{"dog", "good boy"}, Address: csvAddress{City: "Paris", Country: "France"}, BirthDate: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), Weight: &weight},
+		{ID: 2, Name: "Felix, the cat", Previous: &csvAddress{City: "Lyon"}},
+	}
+
+	t.Run("slice of structs", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/csv")
+
+		err := Send(w, r, pets)
+		require.NoError(t, err)
+		require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, strings.Join([]string{
+			"id,pet_name,tags,address.city,address.country,previous.city,previous.country,birth_date,weight",
+			`1,Rex,"[""dog"",""good boy""]",Paris,France,,,2020-01-02T00:00:00Z,3.5`,
+			`2,"Felix, the cat",null,,,Lyon,,0001-01-01T00:00:00Z,`,
+		}, "\n")+"\n", w.Body.String())
+	})
+
+	t.Run("empty slice writes the header", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/csv")
+
+		err := Send(w, r, []*csvAddress{})
+		require.NoError(t, err)
+		require.Equal(t, "city,country\n", w.Body.String())
+	})
+
+	t.Run("not a slice of structs", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/csv")
+
+		err := Send(w, r, []string{"a", "b"})
+		require.ErrorAs(t, err, &NotAcceptableError{})
+	})
+
+	t.Run("stream of structs", func(t *testing.T) {
+		seq := func(yield func(csvAddress, error) bool) {
+			if !yield(csvAddress{City: "Paris"}, nil) {
+				return
+			}
+			yield(csvAddress{}, errors.New("database is down"))
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/csv")
+
+		err := Send(w, r, iter.Seq2[csvAddress, error](seq))
+		require.NoError(t, err)
+		require.Equal(t, "city,country\nParis,\n", w.Body.String())
+		require.Equal(t, "500 Internal Server Error", w.Header().Get(StreamErrorTrailer))
+	})
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Run("reads rows", func(t *testing.T) {
+		input := "\ufeffpet_name,id,address.city,previous.city,tags,birth_date,weight\n" +
+			`Rex,1,Paris,,"[""dog""]",2020-01-02T00:00:00Z,3.5` + "\n" +
+			"Felix,2,,Lyon,,,\n"
+
+		pets, err := ReadCSV[[]csvPet](t.Context(), strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, pets, 2)
+
+		assert.Equal(t, "Rex", pets[0].Name)
+		assert.Equal(t, 1, pets[0].ID)
+		assert.Equal(t, "Paris", pets[0].Address.City)
+		assert.Nil(t, pets[0].Previous)
+		assert.Equal(t, []string{"dog"}, pets[0].Tags)
+		assert.Equal(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), pets[0].BirthDate)
+		require.NotNil(t, pets[0].Weight)
+		assert.InDelta(t, 3.5, *pets[0].Weight, 0.001)
+
+		require.NotNil(t, pets[1].Previous)
+		assert.Equal(t, "Lyon", pets[1].Previous.City)
+		assert.Nil(t, pets[1].Weight)
+	})
+
+	t.Run("reports errors with row numbers", func(t *testing.T) {
+		input := "pet_name,id\nRex,1\n,2\nFelix,three\n"
+
+		_, err := ReadCSV[[]*csvPet](t.Context(), strings.NewReader(input))
+
+		var httpError HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusBadRequest, httpError.StatusCode())
+		require.Len(t, httpError.Errors, 2)
+		assert.Equal(t, "row 3: csvPet.Name", httpError.Errors[0].Name)
+		assert.Equal(t, 3, httpError.Errors[0].More["row"])
+		assert.Equal(t, "row 4: id", httpError.Errors[1].Name)
+		assert.Equal(t, 4, httpError.Errors[1].More["row"])
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		_, err := ReadCSV[[]csvPet](t.Context(), strings.NewReader("pet_name,color\nRex,brown\n"))
+		require.ErrorAs(t, err, &BadRequestError{})
+		require.ErrorContains(t, err, "unknown CSV column: color")
+	})
+
+	t.Run("malformed CSV", func(t *testing.T) {
+		_, err := ReadCSV[[]csvPet](t.Context(), strings.NewReader("pet_name,id\nRex\n"))
+		require.ErrorAs(t, err, &BadRequestError{})
+	})
+
+	t.Run("not a slice of structs", func(t *testing.T) {
+		_, err := ReadCSV[csvPet](t.Context(), strings.NewReader("pet_name\nRex\n"))
+		require.ErrorAs(t, err, &BadRequestError{})
+	})
+}
+
+func TestCSVController(t *testing.T) {
+	s := NewServer()
+
+	route := Post(s, "/pets/import", func(c ContextWithBody[[]csvAddress]) ([]csvAddress, error) {
+		return c.Body()
+	})
+
+	t.Run("reads and sends CSV", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/pets/import", strings.NewReader("city,country\nParis,France\n"))
+		r.Header.Set("Content-Type", "text/csv")
+		r.Header.Set("Accept", "text/csv")
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "city,country\nParis,France\n", w.Body.String())
+	})
+
+	t.Run("documents the CSV response", func(t *testing.T) {
+		content := route.Operation.Responses.Value("200").Value.Content
+		require.Contains(t, content, "application/json")
+		require.Contains(t, content, ContentTypeCSV)
+		assert.Equal(t, content["application/json"].Schema.Ref, content[ContentTypeCSV].Schema.Ref)
+	})
+}
@@ -2,6 +2,7 @@ package fuego
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -122,6 +123,16 @@ type Context[B, P any] interface {
 	// Alias to http.ResponseWriter.WriteHeader.
 	SetStatus(code int)
 
+	// URLFor builds the URL of the route with the given name, see [Engine.URLFor].
+	// Example:
+	//   fuego.Post(s, "/recipes", func(c fuego.ContextWithBody[Recipe]) (any, error) {
+	//   	recipe, _ := rs.CreateRecipe(c.Context(), c.MustBody())
+	//   	url, err := c.URLFor("getRecipe", fuego.PathArgs{"id": recipe.ID}, nil)
+	//   	...
+	//   	return c.Redirect(http.StatusSeeOther, url)
+	//   })
+	URLFor(name string, args PathArgs, query any) (string, error)
+
 	// Redirect redirects to the given url with the given status code.
 	// Example:
 	//   fuego.Get(s, "/recipes", func(c fuego.ContextNoBody) (any, error) {
@@ -191,11 +202,25 @@ func (c netHttpContext[B, P]) SetStatus(code int) {
 	c.Res.WriteHeader(code)
 }
 
+// routeErrorHandler returns the error handler of the route, set with [OptionErrorHandler]. Used by [Flow].
+func (c netHttpContext[B, P]) routeErrorHandler() func(context.Context, error) error {
+	return c.route.ErrorHandler
+}
+
+func (c netHttpContext[B, P]) routeETag() bool {
+	return c.route.ETag
+}
+
+func (c netHttpContext[B, P]) routeCurrentETag() func(*http.Request) (string, error) {
+	return c.route.CurrentETag
+}
+
 // readOptions are options for reading the request body.
 type readOptions struct {
 	MaxBodySize           int64
 	DisallowUnknownFields bool
 	LogBody               bool
+	JSONCodec             JSONCodec // Defaults to [StdJSONCodec]
 }
 
 func (c netHttpContext[B, P]) Redirect(code int, location string) (any, error) {
@@ -503,37 +528,68 @@ func (c *netHttpContext[B, P]) MustParams() P {
 	return params
 }
 
-// Serialize serializes the given data to the response. It uses the Content-Type header to determine the serialization format.
+// Serialize serializes the given data to the response. It uses the Accept header to determine the serialization format,
+// among the response content types of the route.
 func (c netHttpContext[B, P]) Serialize(data any) error {
-	// facilitate user-defined content type serialization
-	for _, contentType := range parseAcceptHeader(c.Req.Header) {
-		serdes, ok := c.route.contentTypeSerDes[contentType]
-		if !ok {
-			continue
-		}
+	accept := c.Req.Header.Get("Accept")
+	offers := c.route.responseContentTypes(data)
 
-		bytes, err := serdes.Serialize(data)
-		if err != nil {
+	// facilitate user-defined content type serialization
+	if len(c.route.contentTypeSerDes) > 0 {
+		contentType := NegotiateContentType(accept, offers)
+		if serdes, ok := c.route.contentTypeSerDes[contentType]; ok {
+			bytes, err := serdes.Serialize(data)
+			if err != nil {
+				return err
+			}
+			AddVary(c.Res.Header(), "Accept")
+			c.Res.Header().Set("Content-Type", contentType)
+			_, err = c.Res.Write(bytes)
 			return err
 		}
-		c.Res.Header().Set("Content-Type", contentType)
-		_, err = c.Res.Write(bytes)
-		return err
 	}
 
 	if c.serializer == nil {
-		return Send(c.Res, c.Req, data)
+		return send(c.Res, c.Req, data, offers)
+	}
+	if c.route.restrictResponseContentTypes && NegotiateContentType(accept, offers) == "" {
+		return notAcceptable(accept, offers)
 	}
 	return c.serializer(c.Res, c.Req, data)
 }
 
-// SerializeError serializes the given error to the response. It uses the Content-Type header to determine the serialization format.
+// SerializeError serializes the given error to the response. It uses the Accept header to determine the serialization format,
+// among the response content types of the route, and falls back to JSON.
 func (c netHttpContext[B, P]) SerializeError(err error) {
-	if c.errorSerializer == nil {
+	offers := c.route.errorContentTypes()
+
+	// facilitate user-defined content type serialization
+	if len(c.route.contentTypeSerDes) > 0 {
+		contentType := NegotiateContentType(c.Req.Header.Get("Accept"), offers)
+		if serdes, ok := c.route.contentTypeSerDes[contentType]; ok {
+			if bytes, serializationErr := serdes.Serialize(err); serializationErr == nil {
+				status := http.StatusInternalServerError
+				var errorStatus ErrorWithStatus
+				if errors.As(err, &errorStatus) {
+					status = errorStatus.StatusCode()
+				}
+				AddVary(c.Res.Header(), "Accept")
+				c.Res.Header().Set("Content-Type", contentType)
+				c.Res.WriteHeader(status)
+				_, _ = c.Res.Write(bytes)
+				return
+			}
+		}
+	}
+
+	switch {
+	case c.errorSerializer != nil:
+		c.errorSerializer(c.Res, c.Req, err)
+	case c.route.restrictResponseContentTypes:
+		sendError(c.Res, c.Req, err, offers)
+	default:
 		SendError(c.Res, c.Req, err)
-		return
 	}
-	c.errorSerializer(c.Res, c.Req, err)
 }
 
 // SetDefaultStatusCode sets the default status code of the response.
@@ -550,13 +606,32 @@ func body[B, P any](c netHttpContext[B, P]) (B, error) {
 	}
 
 	timeDeserialize := time.Now()
+	body, err := decodeBody(c)
+
+	deserialize := Timing{"deserialize", "controller > deserialize", time.Since(timeDeserialize)}
+	AddTiming(c.Req.Context(), deserialize)
+	observePhase(c.Req.Context(), deserialize.Name, timeDeserialize, deserialize.Dur)
 
+	return body, err
+}
+
+// decodeBody decodes the request body according to its content type.
+func decodeBody[B, P any](c netHttpContext[B, P]) (B, error) {
 	var body B
 	var err error
 	contentType := c.Req.Header.Get("Content-Type")
 
+	// patch documents are only read from their own media type, see [MergePatch] and [JSONPatch]
+	if _, ok := any(&body).(patchBody); ok {
+		return readPatch[B](c.Req.Context(), c.Req.Body, contentType, c.readOptions)
+	}
+
 	// facilitate user-defined content type deserialization
 	if serdes, ok := c.route.contentTypeSerDes[contentType]; ok {
+		if typedDeserializer, ok := serdes.(TypedDeserializer); ok {
+			return read[B](c.Req.Context(), typedDecoder{ctx: c.Req.Context(), input: c.Req.Body, deserializer: typedDeserializer})
+		}
+
 		bodyDeserialized, err := serdes.Deserialize(c.Req.Context(), c.Req.Body)
 		if err != nil {
 			return body, err
@@ -579,6 +654,10 @@ func body[B, P any](c netHttpContext[B, P]) (B, error) {
 		body, err = readXML[B](c.Req.Context(), c.Req.Body, c.readOptions)
 	case "application/x-yaml", "text/yaml; charset=utf-8", "application/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
 		body, err = readYAML[B](c.Req.Context(), c.Req.Body, c.readOptions)
+	case ContentTypeMergePatch, ContentTypeJSONPatch:
+		body, err = readPatch[B](c.Req.Context(), c.Req.Body, contentType, c.readOptions)
+	case ContentTypeCSV, "text/csv; charset=utf-8":
+		body, err = readCSV[B](c.Req.Context(), c.Req.Body, c.readOptions)
 	case "application/octet-stream":
 		// Read c.Req Body to bytes
 		bytes, err := io.ReadAll(c.Req.Body)
@@ -594,7 +673,5 @@ func body[B, P any](c netHttpContext[B, P]) (B, error) {
 		body, err = readJSON[B](c.Req.Context(), c.Req.Body, c.readOptions)
 	}
 
-	c.Res.Header().Add("Server-Timing", Timing{"deserialize", "controller > deserialize", time.Since(timeDeserialize)}.String())
-
 	return body, err
 }
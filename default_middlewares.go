@@ -116,14 +116,18 @@ func logRequest(requestID string, r *http.Request) {
 }
 
 func logResponse(r *http.Request, rw *responseWriter, requestID string, duration time.Duration) {
-	slog.Info("outgoing response", //nolint:gosec // G706: Path might be sensible to injection depending on the slog handler. Not the case for slog.JSONHandler.
+	attrs := []any{
 		"status_code", rw.status,
 		"method", r.Method,
 		"path", r.URL.Path,
 		"duration_ms", duration.Milliseconds(),
 		"request_id", requestID,
 		"remote_addr", r.RemoteAddr,
-	)
+	}
+	if timings := Timings(r.Context()); len(timings) > 0 {
+		attrs = append(attrs, "server_timing", formatTimings(timings))
+	}
+	slog.Info("outgoing response", attrs...) //nolint:gosec // G706: Path might be sensible to injection depending on the slog handler. Not the case for slog.JSONHandler.
 }
 
 type defaultLogger struct {
@@ -151,6 +155,7 @@ func (l defaultLogger) middleware(next http.Handler) http.Handler {
 		w.Header().Set("X-Request-ID", requestID)
 
 		wrapped := newResponseWriter(w)
+		r = r.WithContext(withServerTimings(r.Context()))
 
 		if !l.s.loggingConfig.DisableRequest {
 			logRequest(requestID, r)
@@ -0,0 +1,155 @@
+package fuego
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Deprecation describes the deprecation of a route, see [OptionDeprecation].
+type Deprecation struct {
+	// Date from which the route is deprecated, sent in the Deprecation header (RFC 9745).
+	// Defaults to the registration time of the route.
+	Date time.Time
+
+	// Date after which the route will not be served anymore, sent in the Sunset header (RFC 8594).
+	Sunset time.Time
+
+	// Link to the route replacing the deprecated one, sent in the Link header with the "successor-version" relation.
+	Successor string
+
+	// Link to the documentation of the deprecation, sent in the Link header with the "deprecation" relation.
+	Link string
+
+	// If true, the route answers 410 Gone after the sunset date instead of calling the controller.
+	GoneAfterSunset bool
+
+	// calls counts the calls to the route, see [Deprecation.Calls].
+	calls *atomic.Int64
+}
+
+// Calls returns the number of calls to the deprecated route since the server started.
+//
+//	for _, route := range s.Routes() {
+//		if route.Deprecation != nil {
+//			fmt.Println(route.Method, route.Path, route.Deprecation.Calls())
+//		}
+//	}
+func (d *Deprecation) Calls() int64 {
+	if d.calls == nil {
+		return 0
+	}
+	return d.calls.Load()
+}
+
+// sunset reports whether the sunset date has passed.
+func (d *Deprecation) sunset(now time.Time) bool {
+	return !d.Sunset.IsZero() && now.After(d.Sunset)
+}
+
+// OptionDeprecation marks the route as deprecated, like [OptionDeprecated], and signals it to the clients at runtime:
+//
+//   - The responses have the Deprecation, Sunset and Link headers, documented in the OpenAPI spec.
+//   - Each call is logged with the identity of the caller (remote address, user agent and token subject),
+//     and counted, see [Deprecation.Calls].
+//   - With GoneAfterSunset, the route answers 410 Gone after the sunset date, documented in the OpenAPI spec.
+//
+// Example:
+//
+//	fuego.Get(s, "/pets", listPetsV1, fuego.OptionDeprecation(fuego.Deprecation{
+//		Date:      time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+//		Sunset:    time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+//		Successor: "/v2/pets",
+//	}))
+func OptionDeprecation(deprecation Deprecation) RouteOption {
+	return func(r *BaseRoute) {
+		d := deprecation
+		if d.Date.IsZero() {
+			d.Date = time.Now()
+		}
+		d.calls = new(atomic.Int64)
+		r.Deprecation = &d
+		r.Operation.Deprecated = true
+		r.Middlewares = append(r.Middlewares, d.middleware)
+	}
+}
+
+// documentDeprecation documents the deprecation headers and the 410 Gone response of a deprecated route,
+// once its default status code is known.
+func documentDeprecation(route *BaseRoute) {
+	d := route.Deprecation
+	statusCodes := ParamStatusCodes(route.DefaultStatusCode)
+	OptionResponseHeader("Deprecation", "Date from which the operation is deprecated (RFC 9745)",
+		ParamExample("deprecation", formatDeprecationDate(d.Date)), statusCodes)(route)
+	if !d.Sunset.IsZero() {
+		OptionResponseHeader("Sunset", "Date after which the operation will not be available (RFC 8594)",
+			ParamExample("sunset", d.Sunset.UTC().Format(http.TimeFormat)), statusCodes)(route)
+	}
+	if d.Successor != "" || d.Link != "" {
+		OptionResponseHeader("Link", "Replacement of the operation and documentation of its deprecation", statusCodes)(route)
+	}
+	if d.GoneAfterSunset && !d.Sunset.IsZero() {
+		OptionAddResponse(http.StatusGone, "Gone _(after the sunset date)_", Response{Type: HTTPError{}})(route)
+	}
+}
+
+func (d *Deprecation) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.calls.Add(1)
+		slog.WarnContext(r.Context(), "deprecated route called", callerAttrs(r)...)
+
+		setDeprecationHeaders(w.Header(), d.Date, d.Sunset, d.Link)
+		if d.Successor != "" {
+			w.Header().Add("Link", "<"+d.Successor+`>; rel="successor-version"`)
+		}
+
+		if d.GoneAfterSunset && d.sunset(time.Now()) {
+			SendError(w, r, HTTPError{
+				Status: http.StatusGone,
+				Title:  "Gone",
+				Detail: fmt.Sprintf("%s %s is not available since %s", r.Method, r.URL.Path, d.Sunset.UTC().Format(http.TimeFormat)),
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// callerAttrs returns the log attributes identifying the route and the caller of a request.
+func callerAttrs(r *http.Request) []any {
+	attrs := []any{
+		"method", r.Method,
+		"route", r.Pattern,
+		"path", r.URL.Path,
+		"remote_addr", r.RemoteAddr,
+		"user_agent", r.UserAgent(),
+	}
+	if claims, err := TokenFromContext(r.Context()); err == nil {
+		if subject, err := claims.GetSubject(); err == nil && subject != "" {
+			attrs = append(attrs, "subject", subject)
+		}
+	}
+	return attrs
+}
+
+// setDeprecationHeaders sets the Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers.
+func setDeprecationHeaders(header http.Header, deprecation, sunset time.Time, link string) {
+	if !deprecation.IsZero() {
+		header.Set("Deprecation", formatDeprecationDate(deprecation))
+	}
+	if !sunset.IsZero() {
+		header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+	if link != "" {
+		header.Add("Link", "<"+link+`>; rel="deprecation"; type="text/html"`)
+	}
+}
+
+// formatDeprecationDate formats the date as a structured field date, like @1688169599.
+func formatDeprecationDate(date time.Time) string {
+	return "@" + strconv.FormatInt(date.Unix(), 10)
+}
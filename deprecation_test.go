@@ -0,0 +1,141 @@
+package fuego
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thejerf/slogassert"
+)
+
+func TestOptionDeprecation(t *testing.T) {
+	date := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	past := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+
+	s := NewServer()
+	Get(s, "/pets", func(c ContextNoBody) (string, error) {
+		return "pets", nil
+	}, OptionDeprecation(Deprecation{
+		Date:            date,
+		Sunset:          future,
+		Successor:       "/v2/pets",
+		Link:            "https://example.com/migrate",
+		GoneAfterSunset: true,
+	}))
+	Get(s, "/owners", func(c ContextNoBody) (string, error) {
+		return "owners", nil
+	}, OptionDeprecation(Deprecation{Sunset: past, GoneAfterSunset: true}))
+	Get(s, "/toys", func(c ContextNoBody) (string, error) {
+		return "toys", nil
+	}, OptionDeprecation(Deprecation{Sunset: past}))
+	Post(s, "/toys", func(c ContextNoBody) (string, error) {
+		return "toy", nil
+	}, OptionDeprecation(Deprecation{Successor: "/v2/toys"}), OptionDefaultStatusCode(http.StatusCreated))
+
+	t.Run("headers", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/pets", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "@1767225600", w.Header().Get("Deprecation"))
+		assert.Equal(t, future.UTC().Format(http.TimeFormat), w.Header().Get("Sunset"))
+		assert.Equal(t, []string{
+			`<https://example.com/migrate>; rel="deprecation"; type="text/html"`,
+			`</v2/pets>; rel="successor-version"`,
+		}, w.Header().Values("Link"))
+	})
+
+	t.Run("Deprecation header without date", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/toys", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NotEmpty(t, w.Header().Get("Deprecation"), "deprecated since the registration of the route")
+		assert.Equal(t, `</v2/toys>; rel="successor-version"`, w.Header().Get("Link"))
+
+		r = httptest.NewRequest(http.MethodGet, "/toys", nil)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.NotEmpty(t, w.Header().Get("Deprecation"))
+	})
+
+	t.Run("gone after sunset", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/owners", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusGone, w.Code)
+		assert.Equal(t, past.UTC().Format(http.TimeFormat), w.Header().Get("Sunset"))
+	})
+
+	t.Run("still served after sunset without GoneAfterSunset", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/toys", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("calls are counted", func(t *testing.T) {
+		route, ok := s.RouteByOperationID("GET_/pets")
+		require.True(t, ok)
+		require.NotNil(t, route.Deprecation)
+		before := route.Deprecation.Calls()
+
+		r := httptest.NewRequest(http.MethodGet, "/pets", nil)
+		s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+		assert.Equal(t, before+1, route.Deprecation.Calls())
+		assert.Zero(t, (&Deprecation{}).Calls())
+	})
+
+	t.Run("calls are logged with the caller", func(t *testing.T) {
+		handler := slogassert.New(t, slog.LevelWarn, nil)
+		previous := slog.Default()
+		slog.SetDefault(slog.New(handler))
+		defer slog.SetDefault(previous)
+
+		r := httptest.NewRequest(http.MethodGet, "/toys", nil)
+		r.Header.Set("User-Agent", "old-client/1.0")
+		s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+		handler.AssertSomePrecise(slogassert.LogMessageMatch{
+			Message: "deprecated route called",
+			Level:   slog.LevelWarn,
+			Attrs: map[string]any{
+				"method":     http.MethodGet,
+				"route":      "GET /toys",
+				"user_agent": "old-client/1.0",
+			},
+			AllAttrsMatch: false,
+		})
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/pets").Get
+		assert.True(t, operation.Deprecated)
+
+		headers := operation.Responses.Value("200").Value.Headers
+		assert.Contains(t, headers, "Deprecation")
+		assert.Contains(t, headers, "Sunset")
+		assert.Contains(t, headers, "Link")
+		assert.NotNil(t, operation.Responses.Value("410"))
+
+		operation = s.OpenAPI.Description().Paths.Find("/toys").Get
+		assert.Contains(t, operation.Responses.Value("200").Value.Headers, "Deprecation")
+		assert.Nil(t, operation.Responses.Value("410"))
+
+		// Documented on the default status code, whatever the order of the options
+		operation = s.OpenAPI.Description().Paths.Find("/toys").Post
+		assert.Contains(t, operation.Responses.Value("201").Value.Headers, "Deprecation")
+		assert.Contains(t, operation.Responses.Value("201").Value.Headers, "Link")
+		assert.Nil(t, operation.Responses.Value("200"))
+	})
+}
@@ -1,6 +1,7 @@
 package fuego
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -9,8 +10,10 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"github.com/gorilla/schema"
 	"gopkg.in/yaml.v3"
@@ -40,13 +43,52 @@ func ReadJSON[B any](ctx context.Context, input io.Reader) (B, error) {
 // or as a method of Context.
 // It will also read strings.
 func readJSON[B any](ctx context.Context, input io.Reader, options readOptions) (B, error) {
-	// Deserialize the request body.
-	dec := json.NewDecoder(input)
-	if options.DisallowUnknownFields {
-		dec.DisallowUnknownFields()
+	return readJSONInto(ctx, input, options, *new(B))
+}
+
+// readJSONInto reads the request body as JSON into a copy of body, like readJSON.
+// The fields of body absent from the JSON are kept.
+func readJSONInto[B any](ctx context.Context, input io.Reader, options readOptions, body B) (B, error) {
+	codec := jsonCodecOrDefault(options.JSONCodec)
+
+	if options.DisallowUnknownFields && !rejectsUnknownFields(codec) {
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return body, BadRequestError{
+				Title:  "Decoding Failed",
+				Err:    err,
+				Detail: "cannot read request body: " + err.Error(),
+			}
+		}
+		if err := checkUnknownFields[B](data); err != nil {
+			return body, err
+		}
+		input = bytes.NewReader(data)
 	}
 
-	return read[B](ctx, dec)
+	// Deserialize the request body.
+	dec := codec.NewDecoder(input, JSONDecoderOptions{
+		DisallowUnknownFields: options.DisallowUnknownFields,
+	})
+
+	return readInto(ctx, dec, body)
+}
+
+// checkUnknownFields rejects the JSON bodies with keys not matching any field of B, with encoding/json,
+// for the codecs not implementing [UnknownFieldsRejecter]. The other decoding errors are left to the codec.
+func checkUnknownFields[B any](data []byte) error {
+	var probe B
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&probe)
+	if err != nil && strings.HasPrefix(err.Error(), "json: unknown field ") {
+		return BadRequestError{
+			Title:  "Decoding Failed",
+			Err:    err,
+			Detail: "cannot decode request body: " + err.Error(),
+		}
+	}
+	return nil
 }
 
 // ReadXML reads the request body as XML.
@@ -91,9 +133,23 @@ type decoder interface {
 	Decode(v any) error
 }
 
+// typedDecoder adapts a [TypedDeserializer] to the decoder interface.
+type typedDecoder struct {
+	ctx          context.Context
+	input        io.Reader
+	deserializer TypedDeserializer
+}
+
+func (d typedDecoder) Decode(v any) error {
+	return d.deserializer.DeserializeInto(d.ctx, d.input, v)
+}
+
 func read[B any](ctx context.Context, dec decoder) (B, error) {
-	var body B
+	return readInto(ctx, dec, *new(B))
+}
 
+// readInto decodes into a copy of body, then transforms and validates it.
+func readInto[B any](ctx context.Context, dec decoder, body B) (B, error) {
 	err := dec.Decode(&body)
 	if err != nil && !errors.Is(err, io.EOF) {
 		return body, BadRequestError{
@@ -107,6 +163,38 @@ func read[B any](ctx context.Context, dec decoder) (B, error) {
 	return TransformAndValidate(ctx, body)
 }
 
+// readPatch reads a [MergePatch] or [JSONPatch] request body with the JSON codec of the request.
+// The body type must match the content type, otherwise a 415 Unsupported Media Type error is returned:
+// patch documents sent as application/json are refused.
+func readPatch[B any](ctx context.Context, input io.Reader, contentType string, options readOptions) (B, error) {
+	var body B
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	patch, ok := any(&body).(patchBody)
+	if !ok || patch.contentType() != mediaType {
+		return body, HTTPError{
+			Title:  "Unsupported Media Type",
+			Status: http.StatusUnsupportedMediaType,
+			Detail: fmt.Sprintf("cannot read %s request body into %T", contentType, body),
+		}
+	}
+
+	// The context and the options are used to decode the operations of a JSON Patch, and by Apply
+	patch.setReadOptions(ctx, options)
+	dec := jsonCodecOrDefault(options.JSONCodec).NewDecoder(input, JSONDecoderOptions{
+		DisallowUnknownFields: options.DisallowUnknownFields,
+	})
+	if err := dec.Decode(patch); err != nil && !errors.Is(err, io.EOF) {
+		return body, BadRequestError{
+			Title:  "Decoding Failed",
+			Err:    err,
+			Detail: "cannot decode request body: " + err.Error(),
+		}
+	}
+	slog.DebugContext(ctx, "Decoded patch", "body", body)
+
+	return body, patch.check()
+}
+
 // ReadString reads the request body as string.
 // Can be used independently of Fuego framework.
 // Customizable by modifying ReadOptions.
@@ -2,12 +2,13 @@ package fuego
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3gen"
@@ -33,6 +34,7 @@ func NewEngine(options ...EngineOption) *Engine {
 		OpenAPI:              NewOpenAPI(),
 		ErrorHandler:         ErrorHandler,
 		responseContentTypes: defaultResponseContentTypes,
+		contentTypeSerDes:    make(map[string]SerDes),
 	}
 	for _, option := range options {
 		option(e)
@@ -47,6 +49,36 @@ type Engine struct {
 
 	requestContentTypes  []string
 	responseContentTypes []string
+
+	// If true, the routes only send the response content types, see [WithResponseContentType].
+	restrictResponseContentTypes bool
+
+	// Serialization/deserialization for various content types, for all routes
+	contentTypeSerDes map[string]SerDes
+
+	// JSON codec used for request bodies, responses, errors and the OpenAPI spec. Defaults to [StdJSONCodec].
+	jsonCodec JSONCodec
+
+	// If true, timings are not written in the Server-Timing header. See [WithoutServerTiming].
+	disableServerTiming bool
+
+	// Registered routes, see [Engine.Routes].
+	routes   []BaseRoute
+	routesMu sync.RWMutex
+
+	// Versions of the API, see [Version].
+	versions   []*versionedAPI
+	versionsMu sync.RWMutex
+
+	// Lifecycle hooks, see [Engine.OnStart] and [Engine.OnShutdown].
+	startHooks    []LifecycleHook
+	shutdownHooks []LifecycleHook
+	hooksMu       sync.Mutex
+	ready         atomic.Bool
+
+	// Health checks, see [Engine.AddHealthCheck].
+	healthChecks []*healthCheck
+	healthMu     sync.RWMutex
 }
 
 type OpenAPIConfig struct {
@@ -108,8 +140,54 @@ func WithRequestContentType(consumes ...string) EngineOption {
 
 // WithResponseContentType sets content types of the returned body.
 // By default, the returned content-types' are application/json and application/xml
+// in the OpenAPI spec, and all the content types supported by [Send] are negotiated.
+// Once set, only these content types, and the ones of the SerDes, are negotiated with the Accept header:
+// other requests get a 406 Not Acceptable error.
 func WithResponseContentType(consumes ...string) func(*Engine) {
-	return func(e *Engine) { e.responseContentTypes = consumes }
+	return func(e *Engine) {
+		e.responseContentTypes = consumes
+		e.restrictResponseContentTypes = true
+	}
+}
+
+// WithoutServerTiming disables the Server-Timing header and trailer, for example in production
+// to avoid exposing the durations of the internal operations.
+// Timings are still recorded, and logged by the default logger, see [AddTiming].
+func WithoutServerTiming() EngineOption {
+	return func(e *Engine) { e.disableServerTiming = true }
+}
+
+// WithSerDes registers a custom serializer and deserializer for a content type, for all the routes of the engine.
+// It is used to deserialize request bodies with this Content-Type, and to serialize responses and errors
+// when this content type is asked in the Accept header.
+// The content type is also documented in the request and response content of every operation.
+// Route-level SerDes set with [OptionWithContentTypeSerDes] take precedence.
+// This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
+func WithSerDes(contentType string, serdes SerDes) EngineOption {
+	return func(e *Engine) {
+		if e.contentTypeSerDes == nil {
+			e.contentTypeSerDes = make(map[string]SerDes)
+		}
+		e.contentTypeSerDes[contentType] = serdes
+	}
+}
+
+// WithJSONCodec sets the [JSONCodec] used to read JSON request bodies, to send JSON responses and errors,
+// and to marshal the OpenAPI spec. Defaults to [StdJSONCodec].
+// The DisallowUnknownFields server option is passed to the codec.
+// If the codec does not implement [UnknownFieldsRejecter], Fuego rejects the unknown fields itself.
+//
+//	fuego.NewServer(
+//		fuego.WithEngineOptions(
+//			fuego.WithJSONCodec(fuego.StdJSONCodec{UseNumber: true}),
+//		),
+//	)
+//
+// This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
+func WithJSONCodec(codec JSONCodec) EngineOption {
+	return func(e *Engine) {
+		e.jsonCodec = codec
+	}
 }
 
 type MiddlewareConfig struct {
@@ -201,31 +279,38 @@ func (e *Engine) SpecHandler() func(c ContextNoBody) (openapi3.T, error) {
 }
 
 // OutputOpenAPISpec takes the OpenAPI spec and outputs it to a JSON file
+// The OpenAPI descriptions of the versions of the API, if any, are also output, see [Version].
 func (e *Engine) OutputOpenAPISpec() *openapi3.T {
-	e.OpenAPI.computeTags()
+	e.outputVersionsOpenAPI()
+	return e.outputOpenAPI(e.OpenAPI)
+}
+
+// outputOpenAPI finalizes the given OpenAPI description and outputs it to its JSON file.
+func (e *Engine) outputOpenAPI(openAPI *OpenAPI) *openapi3.T {
+	openAPI.computeTags()
 	// resolve schema refs after initial
 	// spec generation
-	e.OpenAPI.resolveSchemaRefs()
+	openAPI.resolveSchemaRefs()
 
 	// Validate
-	err := e.OpenAPI.Description().Validate(context.Background())
+	err := openAPI.Description().Validate(context.Background())
 	if err != nil {
 		slog.Error("Error validating spec", "error", err)
 	}
 
 	// Marshal spec to JSON
-	jsonSpec, err := e.marshalSpec()
+	jsonSpec, err := e.marshalSpec(openAPI)
 	if err != nil {
 		slog.Error("Error marshaling spec to JSON", "error", err)
 	}
 
-	if !e.OpenAPI.Config.DisableLocalSave {
-		err := e.saveOpenAPIToFile(e.OpenAPI.Config.JSONFilePath, jsonSpec)
+	if !openAPI.Config.DisableLocalSave {
+		err := e.saveOpenAPIToFile(openAPI.Config.JSONFilePath, jsonSpec)
 		if err != nil {
-			slog.Error("Error saving spec to local path", "error", err, "path", e.OpenAPI.Config.JSONFilePath)
+			slog.Error("Error saving spec to local path", "error", err, "path", openAPI.Config.JSONFilePath)
 		}
 	}
-	return e.OpenAPI.Description()
+	return openAPI.Description()
 }
 
 func (e *Engine) saveOpenAPIToFile(jsonSpecLocalPath string, jsonSpec []byte) error {
@@ -251,11 +336,12 @@ func (e *Engine) saveOpenAPIToFile(jsonSpecLocalPath string, jsonSpec []byte) er
 	return nil
 }
 
-func (e *Engine) marshalSpec() ([]byte, error) {
-	if e.OpenAPI.Config.PrettyFormatJSON {
-		return json.MarshalIndent(e.OpenAPI.Description(), "", "\t")
+func (e *Engine) marshalSpec(openAPI *OpenAPI) ([]byte, error) {
+	codec := jsonCodecOrDefault(e.jsonCodec)
+	if openAPI.Config.PrettyFormatJSON {
+		return codec.MarshalIndent(openAPI.Description(), "", "\t")
 	}
-	return json.Marshal(e.OpenAPI.Description())
+	return codec.Marshal(openAPI.Description())
 }
 
 func (e *Engine) printOpenAPIMessage(msg string) {
@@ -2,8 +2,13 @@ package fuego
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -132,3 +137,99 @@ func TestWithResponseContentType(t *testing.T) {
 		require.NotContains(t, s.OpenAPI.Description().Components.RequestBodies, "ReqBody")
 	})
 }
+
+// typedSerDes is a JSON-based [SerDes] that implements [TypedDeserializer].
+type typedSerDes struct{}
+
+func (typedSerDes) Serialize(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (typedSerDes) Deserialize(_ context.Context, input io.Reader) (any, error) {
+	var v any
+	err := json.NewDecoder(input).Decode(&v)
+	return v, err
+}
+
+func (typedSerDes) DeserializeInto(_ context.Context, input io.Reader, v any) error {
+	return json.NewDecoder(input).Decode(v)
+}
+
+// textSerDes reads and writes raw strings.
+type textSerDes struct{}
+
+func (textSerDes) Serialize(v any) ([]byte, error) {
+	return []byte(fmt.Sprint(v)), nil
+}
+
+func (textSerDes) Deserialize(_ context.Context, input io.Reader) (any, error) {
+	b, err := io.ReadAll(input)
+	return string(b), err
+}
+
+func TestWithSerDes(t *testing.T) {
+	const contentType = "application/vnd.typed"
+
+	type Pet struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	s := NewServer(WithEngineOptions(
+		WithRequestContentType("application/json"),
+		WithSerDes(contentType, typedSerDes{}),
+	))
+	route := Post(s, "/pets", func(c ContextWithBody[Pet]) (Pet, error) {
+		return c.Body()
+	})
+	Post(s, "/overridden", func(c ContextWithBody[string]) (string, error) {
+		return c.Body()
+	}, OptionWithContentTypeSerDes(contentType, textSerDes{}))
+
+	t.Run("deserializes into the body type", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"Rex"}`))
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set("Accept", contentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, contentType, w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"name":"Rex"}`, w.Body.String())
+	})
+
+	t.Run("validates the body and serializes the error", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{}`))
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set("Accept", "text/html;q=0.5, "+contentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, contentType, w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), `"status":400`)
+	})
+
+	t.Run("route SerDes takes precedence", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/overridden", strings.NewReader("a=b"))
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set("Accept", contentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "a=b", w.Body.String())
+	})
+
+	t.Run("documents the content type", func(t *testing.T) {
+		requestContent := route.Operation.RequestBody.Value.Content
+		assert.Len(t, requestContent, 2)
+		assert.Contains(t, requestContent, contentType)
+
+		responseContent := route.Operation.Responses.Value("200").Value.Content
+		assert.Len(t, responseContent, 3)
+		assert.Contains(t, responseContent, contentType)
+	})
+}
@@ -172,6 +172,34 @@ func (e ConflictError) StatusCode() int { return http.StatusConflict }
 
 func (e ConflictError) Unwrap() error { return HTTPError(e) }
 
+// PreconditionFailedError is an error used to return a 412 status code.
+type PreconditionFailedError HTTPError
+
+var _ ErrorWithStatus = PreconditionFailedError{}
+
+func (e PreconditionFailedError) Error() string {
+	e.Status = http.StatusPreconditionFailed
+	return HTTPError(e).Error()
+}
+
+func (e PreconditionFailedError) StatusCode() int { return http.StatusPreconditionFailed }
+
+func (e PreconditionFailedError) Unwrap() error { return HTTPError(e) }
+
+// ServiceUnavailableError is an error used to return a 503 status code.
+type ServiceUnavailableError HTTPError
+
+var _ ErrorWithStatus = ServiceUnavailableError{}
+
+func (e ServiceUnavailableError) Error() string {
+	e.Status = http.StatusServiceUnavailable
+	return HTTPError(e).Error()
+}
+
+func (e ServiceUnavailableError) StatusCode() int { return http.StatusServiceUnavailable }
+
+func (e ServiceUnavailableError) Unwrap() error { return HTTPError(e) }
+
 // InternalServerError is an error used to return a 500 status code.
 type InternalServerError = HTTPError
 
@@ -111,6 +111,28 @@ func TestErrorHandler(t *testing.T) {
 		require.Equal(t, http.StatusConflict, errHTTP.StatusCode())
 	})
 
+	t.Run("precondition failed error", func(t *testing.T) {
+		err := PreconditionFailedError{
+			Err: errors.New("modified"),
+		}
+		var errHTTP HTTPError
+		require.ErrorAs(t, ErrorHandler(context.Background(), err), &errHTTP)
+		require.ErrorContains(t, err, "modified")
+		require.ErrorContains(t, errHTTP, "412")
+		require.Equal(t, http.StatusPreconditionFailed, errHTTP.StatusCode())
+	})
+
+	t.Run("service unavailable error", func(t *testing.T) {
+		err := ServiceUnavailableError{
+			Err: errors.New("timeout"),
+		}
+		var errHTTP HTTPError
+		require.ErrorAs(t, ErrorHandler(context.Background(), err), &errHTTP)
+		require.ErrorContains(t, err, "timeout")
+		require.ErrorContains(t, errHTTP, "503")
+		require.Equal(t, http.StatusServiceUnavailable, errHTTP.StatusCode())
+	})
+
 	t.Run("unauthorized error", func(t *testing.T) {
 		err := UnauthorizedError{
 			Err: errors.New("coucou"),
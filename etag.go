@@ -0,0 +1,280 @@
+package fuego
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ETagger is implemented by response values that know their own version,
+// like a revision number or an updated-at timestamp.
+// When a controller of a route with [OptionETag] returns an ETagger, its ETag is sent in the ETag header,
+// and GET requests with a matching If-None-Match header get a 304 Not Modified without serializing the value.
+// The value is quoted if needed. Prefix it with "W/" for a weak ETag.
+type ETagger interface {
+	ETag() string
+}
+
+// OptionETag enables ETags and conditional requests on the route.
+//
+//   - 200 responses to GET and HEAD requests get a strong ETag: the one of the [ETagger], or a hash of the serialized body.
+//   - If-None-Match matching the ETag returns 304 Not Modified, without a body.
+//   - On PUT, PATCH and DELETE, a non-matching If-Match header returns 412 Precondition Failed.
+//     Use [OptionIfMatch] to check it before the controller, or call [CheckIfMatch] in the controller
+//     with the current ETag of the resource before modifying it.
+//
+// The ETag response header and the If-None-Match or If-Match request headers are documented in the OpenAPI spec.
+func OptionETag() RouteOption {
+	return func(r *BaseRoute) {
+		if r.ETag {
+			return
+		}
+		r.ETag = true
+		r.Middlewares = append(r.Middlewares, etagMiddleware)
+	}
+}
+
+// OptionIfMatch enables [OptionETag] on the route, and checks the If-Match header of PUT, PATCH and DELETE requests
+// before the controller: currentETag returns the current ETag of the resource targeted by the request,
+// and a non-matching If-Match header returns 412 Precondition Failed without calling the controller.
+//
+//	fuego.Put(s, "/pets/{id}", updatePet, fuego.OptionIfMatch(func(r *http.Request) (string, error) {
+//		pet, err := store.GetPet(r.PathValue("id"))
+//		return pet.ETag(), err
+//	}))
+func OptionIfMatch(currentETag func(r *http.Request) (string, error)) RouteOption {
+	return func(r *BaseRoute) {
+		OptionETag()(r)
+		r.CurrentETag = currentETag
+	}
+}
+
+// routeETagCtx is implemented by the contexts of the adaptors supporting [OptionETag] and [OptionIfMatch].
+type routeETagCtx interface {
+	routeETag() bool
+	routeCurrentETag() func(r *http.Request) (string, error)
+}
+
+// routeHasETag reports whether the route of the context has [OptionETag].
+func routeHasETag(ctx any) bool {
+	routeCtx, ok := ctx.(routeETagCtx)
+	return ok && routeCtx.routeETag()
+}
+
+// CheckIfMatch checks the If-Match request header against the current ETag of the resource,
+// for optimistic concurrency control. Call it before modifying the resource, or use [OptionIfMatch].
+// It returns nil if the header is absent or matches, and a [PreconditionFailedError] otherwise.
+// The comparison ignores the W/ prefix, added by compression middlewares to the ETags of compressed responses.
+//
+//	pet, err := store.GetPet(c.PathParam("id"))
+//	...
+//	if err := fuego.CheckIfMatch(c.Request(), pet.ETag()); err != nil {
+//		return Pet{}, err
+//	}
+func CheckIfMatch(r *http.Request, currentETag string) error {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || etagMatches(ifMatch, formatETag(currentETag), true) {
+		return nil
+	}
+	return PreconditionFailedError{
+		Title:  "Precondition Failed",
+		Detail: "the resource has been modified since it was fetched: If-Match " + ifMatch + " does not match the current ETag " + formatETag(currentETag),
+	}
+}
+
+// checkRouteIfMatch checks the If-Match header of PUT, PATCH and DELETE requests on routes with [OptionIfMatch].
+func checkRouteIfMatch(ctx any, r *http.Request) error {
+	routeCtx, ok := ctx.(routeETagCtx)
+	if !ok || routeCtx.routeCurrentETag() == nil || r.Header.Get("If-Match") == "" {
+		return nil
+	}
+	switch r.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return nil
+	}
+
+	currentETag, err := routeCtx.routeCurrentETag()(r)
+	if err != nil {
+		return err
+	}
+	return CheckIfMatch(r, currentETag)
+}
+
+// formatETag quotes the entity tag if needed.
+func formatETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header matches the ETag.
+// The weak comparison ignores the W/ prefix, the strong comparison requires two strong ETags (RFC 9110 §8.8.3.2).
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		switch {
+		case candidate == "*":
+			return true
+		case weak && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/"):
+			return true
+		case !weak && candidate == etag && !strings.HasPrefix(etag, "W/"):
+			return true
+		}
+	}
+	return false
+}
+
+// notModified reports whether a GET or HEAD request already has the representation with this ETag.
+func notModified(r *http.Request, etag string) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	return ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true)
+}
+
+// etagOf returns the ETag of a controller response, if it implements [ETagger].
+func etagOf(ans any) (string, bool) {
+	etagger, ok := ans.(ETagger)
+	if !ok {
+		return "", false
+	}
+	if v := reflect.ValueOf(ans); v.Kind() == reflect.Ptr && v.IsNil() {
+		return "", false
+	}
+	return formatETag(etagger.ETag()), true
+}
+
+// etagMiddleware buffers 200 responses to GET and HEAD requests to set their ETag,
+// and answers 304 Not Modified when the If-None-Match header matches.
+func etagMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ew := &etagResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		ew.finish(r)
+	})
+}
+
+// etagResponseWriter buffers the body of 200 responses.
+// Other responses, and flushed or hijacked ones, are written as is.
+type etagResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	passthrough bool
+}
+
+func (ew *etagResponseWriter) WriteHeader(code int) {
+	if ew.passthrough || (code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols) {
+		ew.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if ew.status != 0 {
+		return
+	}
+	ew.status = code
+	if code != http.StatusOK {
+		ew.passthrough = true
+		ew.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (ew *etagResponseWriter) Write(p []byte) (int, error) {
+	if ew.status == 0 {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.passthrough {
+		return ew.ResponseWriter.Write(p)
+	}
+	return ew.body.Write(p)
+}
+
+// Flush gives up on the ETag: the response is being streamed.
+func (ew *etagResponseWriter) Flush() {
+	if ew.status == 0 {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if !ew.passthrough {
+		ew.passthrough = true
+		ew.ResponseWriter.WriteHeader(ew.status)
+		_, _ = ew.ResponseWriter.Write(ew.body.Bytes())
+		ew.body.Reset()
+	}
+	_ = http.NewResponseController(ew.ResponseWriter).Flush()
+}
+
+func (ew *etagResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	ew.passthrough = true
+	return http.NewResponseController(ew.ResponseWriter).Hijack()
+}
+
+func (ew *etagResponseWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
+
+func (ew *etagResponseWriter) finish(r *http.Request) {
+	if ew.passthrough || ew.status == 0 {
+		return
+	}
+
+	header := ew.Header()
+	etag := header.Get("ETag")
+	if etag == "" {
+		sum := sha256.Sum256(ew.body.Bytes())
+		etag = `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+		header.Set("ETag", etag)
+	}
+
+	if notModified(r, etag) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		ew.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	ew.ResponseWriter.WriteHeader(ew.status)
+	_, _ = ew.ResponseWriter.Write(ew.body.Bytes())
+}
+
+// documentETag documents the conditional request headers and responses of a route with [OptionETag].
+func documentETag(openapi *OpenAPI, route *BaseRoute) {
+	etagHeader := &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+		Description: "Version of the representation, for conditional requests",
+		Schema:      openapi3.NewStringSchema().NewRef(),
+	}}}
+
+	if response := route.Operation.Responses.Value(strconv.Itoa(route.DefaultStatusCode)); response != nil {
+		if response.Value.Headers == nil {
+			response.Value.Headers = openapi3.Headers{}
+		}
+		response.Value.Headers["ETag"] = etagHeader
+	}
+
+	switch route.Method {
+	case http.MethodGet, http.MethodHead:
+		OptionHeader("If-None-Match", "Returns 304 Not Modified if the ETag of the representation matches")(route)
+		if route.Operation.Responses.Value("304") == nil {
+			notModified := openapi3.NewResponse().WithDescription("Not Modified")
+			notModified.Headers = openapi3.Headers{"ETag": etagHeader}
+			route.Operation.AddResponse(http.StatusNotModified, notModified)
+		}
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		OptionHeader("If-Match", "Returns 412 Precondition Failed if the ETag of the resource does not match")(route)
+		addResponseIfNotSet(openapi, route.Operation, http.StatusPreconditionFailed, "Precondition Failed", Response{Type: HTTPError{}})
+	}
+}
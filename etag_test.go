@@ -0,0 +1,192 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionedPet struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+func (p versionedPet) ETag() string { return "v" + strconv.Itoa(p.Version) }
+
+func TestETag(t *testing.T) {
+	s := NewServer()
+
+	Get(s, "/hashed", func(c ContextNoBody) (ans, error) {
+		return ans{Ans: "Hello World"}, nil
+	}, OptionETag())
+	Get(s, "/versioned", func(c ContextNoBody) (versionedPet, error) {
+		return versionedPet{Name: "Rex", Version: 2}, nil
+	}, OptionETag())
+	Get(s, "/versioned/no-etag", func(c ContextNoBody) (versionedPet, error) {
+		return versionedPet{Name: "Rex", Version: 2}, nil
+	})
+	Get(s, "/error", func(c ContextNoBody) (ans, error) {
+		return ans{}, NotFoundError{Title: "Not Found"}
+	}, OptionETag())
+	putRoute := Put(s, "/versioned", func(c ContextNoBody) (versionedPet, error) {
+		current := versionedPet{Name: "Rex", Version: 2}
+		if err := CheckIfMatch(c.Request(), current.ETag()); err != nil {
+			return versionedPet{}, err
+		}
+		current.Version++
+		return current, nil
+	}, OptionETag())
+
+	get := func(t *testing.T, path, ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("hashes the serialized body", func(t *testing.T) {
+		w := get(t, "/hashed", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		require.Regexp(t, `^"[A-Za-z0-9_-]{43}"$`, etag)
+		require.JSONEq(t, `{"ans":"Hello World"}`, w.Body.String())
+
+		require.Equal(t, etag, get(t, "/hashed", "").Header().Get("ETag"), "the ETag is stable")
+	})
+
+	t.Run("If-None-Match returns 304", func(t *testing.T) {
+		etag := get(t, "/hashed", "").Header().Get("ETag")
+
+		w := get(t, "/hashed", `"other", W/`+etag)
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+		require.Empty(t, w.Header().Get("Content-Type"))
+		require.Equal(t, etag, w.Header().Get("ETag"))
+
+		require.Equal(t, http.StatusOK, get(t, "/hashed", `"other"`).Code)
+	})
+
+	t.Run("errors have no ETag", func(t *testing.T) {
+		w := get(t, "/error", "*")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Empty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("ETagger skips serialization", func(t *testing.T) {
+		w := get(t, "/versioned", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `"v2"`, w.Header().Get("ETag"))
+
+		w = get(t, "/versioned", `"v2"`)
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+	})
+
+	t.Run("ETagger needs the option", func(t *testing.T) {
+		w := get(t, "/versioned/no-etag", `"v2"`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("If-Match", func(t *testing.T) {
+		put := func(ifMatch string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodPut, "/versioned", nil)
+			if ifMatch != "" {
+				r.Header.Set("If-Match", ifMatch)
+			}
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+			return w
+		}
+
+		w := put(`"v2"`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `"v3"`, w.Header().Get("ETag"))
+
+		w = put(`"v1"`)
+		require.Equal(t, http.StatusPreconditionFailed, w.Code)
+		require.Contains(t, w.Body.String(), "Precondition Failed")
+
+		require.Equal(t, http.StatusOK, put(`W/"v2"`).Code, "compression middlewares make the ETags weak")
+		require.Equal(t, http.StatusOK, put("*").Code)
+		require.Equal(t, http.StatusOK, put("").Code)
+	})
+
+	t.Run("OptionIfMatch checks If-Match before the controller", func(t *testing.T) {
+		calls := 0
+		Delete(s, "/pets/{id}", func(c ContextNoBody) (any, error) {
+			calls++
+			return nil, nil
+		}, OptionIfMatch(func(r *http.Request) (string, error) {
+			if r.PathValue("id") != "1" {
+				return "", NotFoundError{Title: "Pet Not Found"}
+			}
+			return versionedPet{Version: 2}.ETag(), nil
+		}))
+
+		del := func(path, ifMatch string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodDelete, path, nil)
+			if ifMatch != "" {
+				r.Header.Set("If-Match", ifMatch)
+			}
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+			return w
+		}
+
+		require.Equal(t, http.StatusPreconditionFailed, del("/pets/1", `"v1"`).Code)
+		require.Equal(t, http.StatusNotFound, del("/pets/2", `"v1"`).Code)
+		require.Equal(t, 0, calls)
+
+		require.Equal(t, http.StatusOK, del("/pets/1", `W/"v2"`).Code)
+		require.Equal(t, http.StatusOK, del("/pets/1", "").Code)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("documents the conditional requests", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/hashed").Get
+		require.NotNil(t, operation.Parameters.GetByInAndName("header", "If-None-Match"))
+		assert.Contains(t, operation.Responses.Value("200").Value.Headers, "ETag")
+		assert.Contains(t, operation.Responses.Value("304").Value.Headers, "ETag")
+
+		operation = putRoute.Operation
+		require.NotNil(t, operation.Parameters.GetByInAndName("header", "If-Match"))
+		assert.Contains(t, operation.Responses.Value("200").Value.Headers, "ETag")
+		assert.NotNil(t, operation.Responses.Value("412"))
+	})
+}
+
+func TestETagMatches(t *testing.T) {
+	require.True(t, etagMatches(`"a", "b"`, `"b"`, false))
+	require.True(t, etagMatches(`*`, `"b"`, false))
+	require.False(t, etagMatches(`W/"b"`, `"b"`, false))
+	require.False(t, etagMatches(`"b"`, `W/"b"`, false))
+	require.True(t, etagMatches(`W/"b"`, `"b"`, true))
+	require.False(t, etagMatches(`"a"`, `"b"`, true))
+
+	require.Equal(t, `"v1"`, formatETag("v1"))
+	require.Equal(t, `W/"v1"`, formatETag(`W/"v1"`))
+}
+
+func TestETagStreamingGivesUp(t *testing.T) {
+	handler := etagMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("first"))
+		http.NewResponseController(w).Flush()
+		_, _ = w.Write([]byte(" second"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.True(t, w.Flushed)
+	require.Empty(t, w.Header().Get("ETag"))
+	require.Equal(t, "first second", w.Body.String())
+}
@@ -0,0 +1,65 @@
+// Package cbor provides a CBOR (RFC 8949) serializer and deserializer for fuego.
+// Struct fields are encoded with their json tag names, so the same types can be used for JSON and CBOR.
+package cbor
+
+import (
+	"context"
+	"io"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/go-fuego/fuego"
+)
+
+// ContentType is the media type handled by [SerDes].
+const ContentType = "application/cbor"
+
+// SerDes serializes and deserializes CBOR.
+// It implements [fuego.SerDes] and [fuego.TypedDeserializer].
+type SerDes struct {
+	// DisallowUnknownFields makes the deserialization fail when the input contains fields not present in the body type.
+	DisallowUnknownFields bool
+}
+
+var (
+	_ fuego.SerDes            = SerDes{}
+	_ fuego.TypedDeserializer = SerDes{}
+)
+
+// Serialize encodes v to CBOR.
+func (s SerDes) Serialize(v any) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+// Deserialize decodes CBOR to a generic value (maps, slices and primitive types).
+func (s SerDes) Deserialize(ctx context.Context, input io.Reader) (any, error) {
+	var v any
+	err := s.DeserializeInto(ctx, input, &v)
+	return v, err
+}
+
+// DeserializeInto decodes CBOR into v.
+func (s SerDes) DeserializeInto(_ context.Context, input io.Reader, v any) error {
+	options := cbor.DecOptions{
+		// Generic maps are decoded as map[string]any, like JSON, instead of map[any]any.
+		DefaultMapType: reflect.TypeFor[map[string]any](),
+	}
+	if s.DisallowUnknownFields {
+		options.ExtraReturnErrors = cbor.ExtraDecErrorUnknownField
+	}
+	dm, err := options.DecMode()
+	if err != nil {
+		return err
+	}
+	return dm.NewDecoder(input).Decode(v)
+}
+
+// WithCBOR registers the CBOR [SerDes] for all the routes of the engine.
+// Requests with "Content-Type: application/cbor" are deserialized from CBOR,
+// and responses are serialized to CBOR when asked with "Accept: application/cbor".
+//
+//	s := fuego.NewServer(fuego.WithEngineOptions(cbor.WithCBOR()))
+func WithCBOR() fuego.EngineOption {
+	return fuego.WithSerDes(ContentType, SerDes{})
+}
@@ -0,0 +1,101 @@
+package cbor
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+type Pet struct {
+	Name string `json:"name" validate:"required"`
+	Age  int    `json:"age,omitempty"`
+}
+
+func encode(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := SerDes{}.Serialize(v)
+	require.NoError(t, err)
+	return b
+}
+
+func TestWithCBOR(t *testing.T) {
+	s := fuego.NewServer(fuego.WithEngineOptions(WithCBOR()))
+	route := fuego.Post(s, "/pets", func(c fuego.ContextWithBody[Pet]) (Pet, error) {
+		pet, err := c.Body()
+		pet.Age++
+		return pet, err
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewReader(encode(t, map[string]any{"name": "Rex", "age": 2})))
+		r.Header.Set("Content-Type", ContentType)
+		r.Header.Set("Accept", ContentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+		var got map[string]any
+		require.NoError(t, cbor.Unmarshal(w.Body.Bytes(), &got))
+		require.Equal(t, "Rex", got["name"])
+		require.EqualValues(t, 3, got["age"])
+	})
+
+	t.Run("JSON is still supported", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewReader(encode(t, Pet{Name: "Rex"})))
+		r.Header.Set("Content-Type", ContentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name":"Rex","age":1}`, w.Body.String())
+	})
+
+	t.Run("validation error in CBOR", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewReader(encode(t, Pet{})))
+		r.Header.Set("Content-Type", ContentType)
+		r.Header.Set("Accept", ContentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+		var got map[string]any
+		require.NoError(t, cbor.Unmarshal(w.Body.Bytes(), &got))
+		require.EqualValues(t, http.StatusBadRequest, got["status"])
+	})
+
+	t.Run("documents the content type", func(t *testing.T) {
+		require.Contains(t, route.Operation.Responses.Value("200").Value.Content, ContentType)
+	})
+}
+
+func TestSerDes(t *testing.T) {
+	t.Run("generic deserialization", func(t *testing.T) {
+		v, err := SerDes{}.Deserialize(t.Context(), bytes.NewReader(encode(t, Pet{Name: "Rex"})))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"name": "Rex"}, v)
+	})
+
+	t.Run("disallow unknown fields", func(t *testing.T) {
+		input := encode(t, map[string]any{"name": "Rex", "color": "brown"})
+
+		var pet Pet
+		require.NoError(t, SerDes{}.DeserializeInto(t.Context(), bytes.NewReader(input), &pet))
+		require.Equal(t, "Rex", pet.Name)
+
+		err := SerDes{DisallowUnknownFields: true}.DeserializeInto(t.Context(), bytes.NewReader(input), &pet)
+		require.Error(t, err)
+	})
+}
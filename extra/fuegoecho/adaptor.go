@@ -152,6 +152,7 @@ func EchoHandler[B, T, P any](engine *fuego.Engine, handler func(c fuego.Context
 				UrlValues:         c.Request().URL.Query(),
 				OpenAPIParams:     route.Params,
 				DefaultStatusCode: route.DefaultStatusCode,
+				URLBuilder:        engine.URLFor,
 			},
 			echoCtx: c,
 		}
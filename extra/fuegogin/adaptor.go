@@ -159,6 +159,7 @@ func GinHandler[B, T, P any](engine *fuego.Engine, handler func(c fuego.Context[
 				UrlValues:         c.Request.URL.Query(),
 				OpenAPIParams:     route.Params,
 				DefaultStatusCode: route.DefaultStatusCode,
+				URLBuilder:        engine.URLFor,
 			},
 			ginCtx: c,
 		}
@@ -169,6 +169,7 @@ func MuxHandler[B, T, P any](engine *fuego.Engine, handler func(c fuego.Context[
 				UrlValues:         r.URL.Query(),
 				OpenAPIParams:     route.Params,
 				DefaultStatusCode: route.DefaultStatusCode,
+				URLBuilder:        engine.URLFor,
 			},
 			req: r,
 			res: w,
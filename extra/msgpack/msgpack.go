@@ -0,0 +1,68 @@
+// Package msgpack provides a MessagePack serializer and deserializer for fuego.
+// Struct fields are encoded with their json tag names and options, like omitempty,
+// so the same types can be used for JSON and MessagePack.
+package msgpack
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/go-fuego/fuego"
+)
+
+// ContentType is the media type handled by [SerDes].
+const ContentType = "application/msgpack"
+
+// SerDes serializes and deserializes MessagePack.
+// It implements [fuego.SerDes] and [fuego.TypedDeserializer].
+type SerDes struct {
+	// DisallowUnknownFields makes the deserialization fail when the input contains fields not present in the body type.
+	DisallowUnknownFields bool
+}
+
+var (
+	_ fuego.SerDes            = SerDes{}
+	_ fuego.TypedDeserializer = SerDes{}
+)
+
+// Serialize encodes v to MessagePack.
+func (s SerDes) Serialize(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Deserialize decodes MessagePack to a generic value (maps, slices and primitive types).
+func (s SerDes) Deserialize(_ context.Context, input io.Reader) (any, error) {
+	var v any
+	err := s.newDecoder(input).Decode(&v)
+	return v, err
+}
+
+// DeserializeInto decodes MessagePack into v.
+func (s SerDes) DeserializeInto(_ context.Context, input io.Reader, v any) error {
+	return s.newDecoder(input).Decode(v)
+}
+
+func (s SerDes) newDecoder(input io.Reader) *msgpack.Decoder {
+	dec := msgpack.NewDecoder(input)
+	dec.SetCustomStructTag("json")
+	dec.DisallowUnknownFields(s.DisallowUnknownFields)
+	return dec
+}
+
+// WithMessagePack registers the MessagePack [SerDes] for all the routes of the engine.
+// Requests with "Content-Type: application/msgpack" are deserialized from MessagePack,
+// and responses are serialized to MessagePack when asked with "Accept: application/msgpack".
+//
+//	s := fuego.NewServer(fuego.WithEngineOptions(msgpack.WithMessagePack()))
+func WithMessagePack() fuego.EngineOption {
+	return fuego.WithSerDes(ContentType, SerDes{})
+}
@@ -0,0 +1,120 @@
+package msgpack
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/go-fuego/fuego"
+)
+
+type Pet struct {
+	Name string `json:"name" validate:"required"`
+	Age  int    `json:"age,omitempty"`
+}
+
+func encode(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := SerDes{}.Serialize(v)
+	require.NoError(t, err)
+	return b
+}
+
+func TestWithMessagePack(t *testing.T) {
+	s := fuego.NewServer(fuego.WithEngineOptions(WithMessagePack()))
+	route := fuego.Post(s, "/pets", func(c fuego.ContextWithBody[Pet]) (Pet, error) {
+		pet, err := c.Body()
+		pet.Age++
+		return pet, err
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewReader(encode(t, map[string]any{"name": "Rex", "age": 2})))
+		r.Header.Set("Content-Type", ContentType)
+		r.Header.Set("Accept", ContentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+		var got map[string]any
+		require.NoError(t, msgpack.Unmarshal(w.Body.Bytes(), &got))
+		require.Equal(t, "Rex", got["name"])
+		require.EqualValues(t, 3, got["age"])
+	})
+
+	t.Run("JSON is still supported", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewReader(encode(t, Pet{Name: "Rex"})))
+		r.Header.Set("Content-Type", ContentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name":"Rex","age":1}`, w.Body.String())
+	})
+
+	t.Run("validation error in MessagePack", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/pets", bytes.NewReader(encode(t, Pet{})))
+		r.Header.Set("Content-Type", ContentType)
+		r.Header.Set("Accept", ContentType)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+		var got map[string]any
+		require.NoError(t, msgpack.Unmarshal(w.Body.Bytes(), &got))
+		require.EqualValues(t, http.StatusBadRequest, got["status"])
+	})
+
+	t.Run("documents the content type", func(t *testing.T) {
+		require.Contains(t, route.Operation.Responses.Value("200").Value.Content, ContentType)
+		require.Contains(t, route.Operation.RequestBody.Value.Content, ContentType)
+		require.Contains(t, route.Operation.RequestBody.Value.Content, "*/*")
+	})
+}
+
+func TestSerDes(t *testing.T) {
+	t.Run("generic deserialization", func(t *testing.T) {
+		v, err := SerDes{}.Deserialize(t.Context(), bytes.NewReader(encode(t, Pet{Name: "Rex"})))
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"name": "Rex"}, v)
+	})
+
+	t.Run("zero values are kept without omitempty", func(t *testing.T) {
+		type counter struct {
+			Name   string `json:"name"`
+			Count  int    `json:"count"`
+			Active bool   `json:"active"`
+			Note   string `json:"note,omitempty"`
+		}
+
+		var got map[string]any
+		require.NoError(t, msgpack.Unmarshal(encode(t, counter{Name: "visits"}), &got))
+		require.Equal(t, map[string]any{"name": "visits", "count": int8(0), "active": false}, got)
+
+		var decoded counter
+		require.NoError(t, SerDes{}.DeserializeInto(t.Context(), bytes.NewReader(encode(t, counter{Count: 0, Active: false})), &decoded))
+		require.Equal(t, counter{}, decoded)
+	})
+
+	t.Run("disallow unknown fields", func(t *testing.T) {
+		input := encode(t, map[string]any{"name": "Rex", "color": "brown"})
+
+		var pet Pet
+		require.NoError(t, SerDes{}.DeserializeInto(t.Context(), bytes.NewReader(input), &pet))
+		require.Equal(t, "Rex", pet.Name)
+
+		err := SerDes{DisallowUnknownFields: true}.DeserializeInto(t.Context(), bytes.NewReader(input), &pet)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,186 @@
+// Package fuegootel traces the requests of a Fuego server with OpenTelemetry.
+//
+//	import fuegootel "github.com/go-fuego/fuego/extra/otel"
+//
+//	s := fuego.NewServer(
+//		fuegootel.WithTracing(fuegootel.Config{}),
+//	)
+package fuegootel
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-fuego/fuego"
+)
+
+const instrumentationName = "github.com/go-fuego/fuego/extra/otel"
+
+// OperationIDKey is the attribute of the server spans holding the OpenAPI operation ID of the route.
+const OperationIDKey = attribute.Key("fuego.operation_id")
+
+// Config configures the tracing of the server, see [WithTracing].
+type Config struct {
+	// Defaults to the global tracer provider, see [otel.SetTracerProvider].
+	TracerProvider trace.TracerProvider
+	// Extracts the trace context from the request headers.
+	// Defaults to the W3C Trace Context and Baggage propagators.
+	Propagator propagation.TextMapPropagator
+	// If true, no child span is created for the phases of the controllers.
+	DisablePhaseSpans bool
+}
+
+// WithTracing creates a server span for each request, named after the route template like "GET /pets/{id}".
+// The trace context of the caller is extracted from the W3C traceparent header.
+//
+// The span has the semantic convention attributes of HTTP servers (http.route, http.response.status_code...),
+// and the operation ID of the route. A child span is created for each phase of the controller:
+// fuegoReqInit (params validation), deserialize, controller, transformOut and serialize.
+//
+// The errors of the controllers, including params validation errors and timeouts, are recorded on the span,
+// whatever the error handler of the route, see [fuego.WithErrorObserver].
+func WithTracing(config Config) fuego.ServerOption {
+	return func(s *fuego.Server) {
+		t := newTracer(config, s.Engine)
+		fuego.WithRouteOptions(fuego.OptionMiddleware(t.middleware))(s)
+	}
+}
+
+type tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	config     Config
+	engine     *fuego.Engine
+
+	// Operation IDs of the routes, by route pattern like "GET /pets/{id}".
+	operationIDs sync.Map
+}
+
+func newTracer(config Config, engine *fuego.Engine) *tracer {
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+	if config.Propagator == nil {
+		config.Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return &tracer{
+		tracer:     config.TracerProvider.Tracer(instrumentationName),
+		propagator: config.Propagator,
+		config:     config,
+		engine:     engine,
+	}
+}
+
+// operationID returns the operation ID of the route matching the pattern.
+func (t *tracer) operationID(pattern string) string {
+	if id, ok := t.operationIDs.Load(pattern); ok {
+		return id.(string)
+	}
+	for _, route := range t.engine.Routes() {
+		if route.Method+" "+route.Path == pattern && route.Operation != nil {
+			t.operationIDs.Store(pattern, route.Operation.OperationID)
+			return route.Operation.OperationID
+		}
+	}
+	return ""
+}
+
+func (t *tracer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		route := r.Pattern
+		if route == "" {
+			route = r.Method
+		}
+		attributes := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+			semconv.URLScheme(scheme(r)),
+			semconv.UserAgentOriginal(r.UserAgent()),
+		}
+		if i := strings.Index(r.Pattern, "/"); i >= 0 {
+			attributes = append(attributes, semconv.HTTPRoute(r.Pattern[i:]))
+		}
+		if id := t.operationID(r.Pattern); id != "" {
+			attributes = append(attributes, OperationIDKey.String(id))
+		}
+
+		ctx, span := t.tracer.Start(ctx, route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attributes...),
+		)
+		defer span.End()
+
+		ctx = fuego.WithErrorObserver(ctx, recordError)
+		if !t.config.DisablePhaseSpans {
+			ctx = fuego.WithPhaseObserver(ctx, t.phaseSpan)
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	})
+}
+
+// phaseSpan creates a child span for a phase of the controller, with its actual start and end times.
+func (t *tracer) phaseSpan(ctx context.Context, phase string, start time.Time, duration time.Duration) {
+	_, span := t.tracer.Start(ctx, phase, trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(start.Add(duration)))
+}
+
+// recordError records an error of the controller on the span of the request.
+func recordError(ctx context.Context, err error) {
+	trace.SpanFromContext(ctx).RecordError(err)
+}
+
+func scheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// statusRecorder captures the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
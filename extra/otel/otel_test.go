@@ -0,0 +1,185 @@
+package fuegootel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-fuego/fuego"
+)
+
+type pet struct {
+	Name string `json:"name"`
+}
+
+func newTracedServer(t *testing.T) (*fuego.Server, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(t.Context()) })
+
+	s := fuego.NewServer(
+		fuego.WithoutLogger(),
+		fuego.WithEngineOptions(fuego.WithOpenAPIConfig(fuego.OpenAPIConfig{DisableLocalSave: true})),
+		WithTracing(Config{TracerProvider: provider}),
+	)
+	fuego.Get(s, "/pets/{id}", func(c fuego.ContextNoBody) (pet, error) {
+		if c.PathParam("id") == "0" {
+			return pet{}, errors.New("database is down")
+		}
+		return pet{Name: "Rex"}, nil
+	}, fuego.OptionOperationID("getPet"))
+	fuego.Post(s, "/pets", func(c fuego.ContextWithBody[pet]) (pet, error) {
+		return c.Body()
+	})
+
+	return s, exporter
+}
+
+func spanNamed(spans tracetest.SpanStubs, name string) (tracetest.SpanStub, bool) {
+	for _, span := range spans {
+		if span.Name == name {
+			return span, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	values := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestWithTracing(t *testing.T) {
+	t.Run("server span named after the route", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+
+		r := httptest.NewRequest(http.MethodGet, "/pets/1", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		server, ok := spanNamed(exporter.GetSpans(), "GET /pets/{id}")
+		require.True(t, ok)
+		assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+
+		attrs := attributes(server)
+		assert.Equal(t, "/pets/{id}", attrs["http.route"].AsString())
+		assert.Equal(t, "/pets/1", attrs["url.path"].AsString())
+		assert.Equal(t, http.MethodGet, attrs["http.request.method"].AsString())
+		assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+		assert.Equal(t, "getPet", attrs[OperationIDKey].AsString())
+	})
+
+	t.Run("child spans for the phases", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+
+		r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"Rex"}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+		spans := exporter.GetSpans()
+		server, ok := spanNamed(spans, "POST /pets")
+		require.True(t, ok)
+		for _, phase := range []string{"fuegoReqInit", "deserialize", "controller", "transformOut", "serialize"} {
+			span, ok := spanNamed(spans, phase)
+			require.True(t, ok, phase)
+			assert.Equal(t, server.SpanContext.SpanID(), span.Parent.SpanID(), phase)
+			assert.False(t, span.EndTime.Before(span.StartTime), phase)
+		}
+	})
+
+	t.Run("propagates the W3C trace context", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+
+		r := httptest.NewRequest(http.MethodGet, "/pets/1", nil)
+		r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+		server, ok := spanNamed(exporter.GetSpans(), "GET /pets/{id}")
+		require.True(t, ok)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+		assert.True(t, server.Parent.IsRemote())
+	})
+
+	t.Run("errors are recorded on the span", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+
+		r := httptest.NewRequest(http.MethodGet, "/pets/0", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+
+		server, ok := spanNamed(exporter.GetSpans(), "GET /pets/{id}")
+		require.True(t, ok)
+		assert.Equal(t, codes.Error, server.Status.Code)
+		require.Len(t, server.Events, 1)
+		assert.Equal(t, "exception", server.Events[0].Name)
+		assert.Contains(t, server.Events[0].Attributes, attribute.String("exception.message", "database is down"))
+	})
+
+	t.Run("errors of routes with their own error handler are recorded", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+		fuego.Get(s, "/toys/{id}", func(c fuego.ContextNoBody) (pet, error) {
+			return pet{}, errors.New("toy not found")
+		}, fuego.OptionErrorHandler(func(_ context.Context, err error) error {
+			return fuego.NotFoundError{Err: err}
+		}))
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/toys/1", nil))
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		server, ok := spanNamed(exporter.GetSpans(), "GET /toys/{id}")
+		require.True(t, ok)
+		require.Len(t, server.Events, 1)
+		assert.Contains(t, server.Events[0].Attributes, attribute.String("exception.message", "toy not found"))
+	})
+
+	t.Run("params validation errors are traced", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+		fuego.Get(s, "/search", func(c fuego.ContextNoBody) (pet, error) {
+			return pet{}, nil
+		}, fuego.OptionQuery("name", "Name", fuego.ParamRequired()))
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search", nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		spans := exporter.GetSpans()
+		server, ok := spanNamed(spans, "GET /search")
+		require.True(t, ok)
+		require.Len(t, server.Events, 1)
+		span, ok := spanNamed(spans, "fuegoReqInit")
+		require.True(t, ok)
+		assert.Equal(t, server.SpanContext.SpanID(), span.Parent.SpanID())
+	})
+
+	t.Run("phase spans can be disabled", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		s := fuego.NewServer(fuego.WithoutLogger(), WithTracing(Config{TracerProvider: provider, DisablePhaseSpans: true}))
+		fuego.Get(s, "/", func(c fuego.ContextNoBody) (string, error) { return "ok", nil })
+
+		s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET /", spans[0].Name)
+	})
+}
@@ -10,11 +10,28 @@ type Registerer[T, B, P any] interface {
 	Register() Route[T, B, P]
 }
 
+// Registers registers the route with the [Registerer], documents it in the OpenAPI spec
+// and adds it to the routes of the engine, see [Engine.Routes].
 func Registers[B, T, P any](engine *Engine, a Registerer[B, T, P]) *Route[B, T, P] {
 	route := a.Register()
-	err := route.RegisterOpenAPIOperation(engine.OpenAPI)
+	openAPI := engine.OpenAPI
+	if route.OpenAPI != nil {
+		openAPI = route.OpenAPI // Version documents, see [Version]
+	}
+	err := route.RegisterOpenAPIOperation(openAPI)
 	if err != nil {
 		slog.Warn("error documenting openapi operation", "error", err)
 	}
+
+	// Hidden routes are not documented but can still be looked up by operation ID
+	if route.Operation != nil && route.Operation.OperationID == "" && route.Method != "" {
+		route.GenerateDefaultOperationID()
+	}
+	if route.Name == "" && route.Operation != nil {
+		route.Name = route.Operation.OperationID
+	}
+	route.Adaptor = adaptorName(a)
+	engine.registerRoute(route.BaseRoute)
+
 	return &route
 }
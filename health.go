@@ -0,0 +1,342 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HealthStatus is the status of a health check, as defined by the
+// [Health Check Response Format for HTTP APIs] draft.
+//
+// [Health Check Response Format for HTTP APIs]: https://datatracker.ietf.org/doc/html/draft-inadarei-api-health-check
+type HealthStatus string
+
+const (
+	HealthPass HealthStatus = "pass"
+	HealthWarn HealthStatus = "warn"
+	HealthFail HealthStatus = "fail"
+)
+
+// HealthContentType is the content type of the health responses.
+const HealthContentType = "application/health+json"
+
+const defaultHealthCheckTimeout = 5 * time.Second
+
+// HealthCheck is a named check of a dependency of the service, see [Engine.AddHealthCheck].
+type HealthCheck struct {
+	// Name of the check, for example "postgres" or "disk". Required.
+	Name string
+	// Check returns an error if the dependency is unhealthy. Required.
+	// See [HealthCheckPing], [HealthCheckHTTP] and [HealthCheckDiskSpace].
+	Check func(ctx context.Context) error
+	// Maximum duration of the check. Defaults to 5s.
+	Timeout time.Duration
+	// Duration during which the result is reused, to avoid hammering the dependency. Defaults to 0 (not cached).
+	CacheDuration time.Duration
+	// If true, the check is also run by the liveness endpoint.
+	// Only use it for checks of the process itself: a failing liveness makes the orchestrator restart the service.
+	Liveness bool
+	// If true, a failure is reported as a warning and does not make the service unhealthy.
+	NonCritical bool
+}
+
+// HealthCheckResult is the result of a [HealthCheck].
+type HealthCheckResult struct {
+	Status        HealthStatus `json:"status"`
+	Time          time.Time    `json:"time"`
+	ObservedValue int64        `json:"observedValue"`
+	ObservedUnit  string       `json:"observedUnit"`
+	Output        string       `json:"output,omitempty"`
+}
+
+// HealthResponse is the body of the health endpoints.
+type HealthResponse struct {
+	Status      HealthStatus                   `json:"status"`
+	Version     string                         `json:"version,omitempty"`
+	Description string                         `json:"description,omitempty"`
+	Output      string                         `json:"output,omitempty"`
+	Checks      map[string][]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// healthCheck is a registered [HealthCheck] with its cached result.
+type healthCheck struct {
+	HealthCheck
+
+	mu     sync.Mutex
+	result HealthCheckResult
+}
+
+// AddHealthCheck registers a check run by the health endpoints, see [WithHealthChecks].
+// It panics if the name is empty or already used, or if the check function is nil.
+//
+//	s.AddHealthCheck(fuego.HealthCheck{
+//		Name:    "postgres",
+//		Check:   fuego.HealthCheckPing(db),
+//		Timeout: time.Second,
+//	})
+func (e *Engine) AddHealthCheck(check HealthCheck) {
+	if check.Name == "" || check.Check == nil {
+		panic("fuego: a health check needs a name and a check function")
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = defaultHealthCheckTimeout
+	}
+
+	e.healthMu.Lock()
+	defer e.healthMu.Unlock()
+	for _, existing := range e.healthChecks {
+		if existing.Name == check.Name {
+			panic(fmt.Sprintf("fuego: health check %q already registered", check.Name))
+		}
+	}
+	e.healthChecks = append(e.healthChecks, &healthCheck{HealthCheck: check})
+}
+
+// CheckHealth runs the health checks concurrently and returns the aggregated status.
+// If liveness is true, only the checks with [HealthCheck.Liveness] are run and the readiness of the engine is ignored.
+func (e *Engine) CheckHealth(ctx context.Context, liveness bool) HealthResponse {
+	e.healthMu.RLock()
+	checks := make([]*healthCheck, 0, len(e.healthChecks))
+	for _, check := range e.healthChecks {
+		if !liveness || check.Liveness {
+			checks = append(checks, check)
+		}
+	}
+	e.healthMu.RUnlock()
+
+	response := HealthResponse{
+		Status:      HealthPass,
+		Version:     e.OpenAPI.Description().Info.Version,
+		Description: e.OpenAPI.Description().Info.Title,
+	}
+	if !liveness && !e.Ready() {
+		response.Status = HealthFail
+		response.Output = "not ready"
+	}
+
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if len(checks) > 0 {
+		response.Checks = make(map[string][]HealthCheckResult, len(checks))
+	}
+	for i, check := range checks {
+		response.Checks[check.Name] = []HealthCheckResult{results[i]}
+		switch {
+		case results[i].Status == HealthFail:
+			response.Status = HealthFail
+		case results[i].Status == HealthWarn && response.Status == HealthPass:
+			response.Status = HealthWarn
+		}
+	}
+
+	return response
+}
+
+// run runs the check with its timeout, or returns the cached result.
+func (c *healthCheck) run(ctx context.Context) HealthCheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.CacheDuration > 0 && !c.result.Time.IsZero() && time.Since(c.result.Time) < c.CacheDuration {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.Timeout)
+	}
+
+	c.result = HealthCheckResult{
+		Status:        HealthPass,
+		Time:          start.UTC(),
+		ObservedValue: time.Since(start).Milliseconds(),
+		ObservedUnit:  "ms",
+	}
+	if err != nil {
+		c.result.Status = HealthFail
+		if c.NonCritical {
+			c.result.Status = HealthWarn
+		}
+		c.result.Output = err.Error()
+	}
+	return c.result
+}
+
+// LivenessHandler answers whether the process is alive. It only runs the checks with [HealthCheck.Liveness].
+// Details of the checks are given with the `verbose` query parameter.
+func (e *Engine) LivenessHandler() http.Handler {
+	return e.healthHandler(true, false)
+}
+
+// ReadinessHandler answers whether the service can receive traffic: the engine is ready (see [Engine.Ready])
+// and all the checks pass. Details of the checks are given with the `verbose` query parameter.
+func (e *Engine) ReadinessHandler() http.Handler {
+	return e.healthHandler(false, false)
+}
+
+// HealthHandler works like [Engine.ReadinessHandler], but always gives the details of the checks.
+func (e *Engine) HealthHandler() http.Handler {
+	return e.healthHandler(false, true)
+}
+
+func (e *Engine) healthHandler(liveness, verbose bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := e.CheckHealth(r.Context(), liveness)
+		if !verbose && !verboseQuery(r) {
+			response.Checks = nil
+		}
+
+		w.Header().Set("Content-Type", HealthContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		if response.Status == HealthFail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
+
+// verboseQuery reports whether the details of the checks are asked with ?verbose or ?verbose=true.
+func verboseQuery(r *http.Request) bool {
+	if !r.URL.Query().Has("verbose") {
+		return false
+	}
+	value := r.URL.Query().Get("verbose")
+	verbose, err := strconv.ParseBool(value)
+	return value == "" || err == nil && verbose
+}
+
+// HealthConfig configures the health endpoints, see [WithHealthChecks].
+type HealthConfig struct {
+	// Checks registered with [Engine.AddHealthCheck].
+	Checks []HealthCheck
+	// Defaults to /livez.
+	LivenessPath string
+	// Defaults to /readyz.
+	ReadinessPath string
+	// Defaults to /healthz.
+	HealthPath string
+	// If true, the endpoints are documented in the OpenAPI description. They are hidden by default.
+	Documented bool
+}
+
+// WithHealthChecks registers the health endpoints, backed by the given checks:
+//   - /livez: the process is alive. Only runs the checks with [HealthCheck.Liveness].
+//   - /readyz: the service can receive traffic. Fails while the server is starting or draining, see [WithShutdown].
+//   - /healthz: like /readyz, with the details of all the checks.
+//
+// Responses follow the [Health Check Response Format for HTTP APIs] draft, with a 503 status code on failure.
+// Add the `verbose` query parameter to /livez and /readyz to get the details of the checks.
+// More checks can be registered later with [Engine.AddHealthCheck].
+//
+//	app := fuego.NewServer(
+//		fuego.WithHealthChecks(fuego.HealthConfig{
+//			Checks: []fuego.HealthCheck{
+//				{Name: "postgres", Check: fuego.HealthCheckPing(db), CacheDuration: 5 * time.Second},
+//				{Name: "payments", Check: fuego.HealthCheckHTTP("http://payments/readyz"), NonCritical: true},
+//			},
+//		}),
+//	)
+//
+// [Health Check Response Format for HTTP APIs]: https://datatracker.ietf.org/doc/html/draft-inadarei-api-health-check
+func WithHealthChecks(config HealthConfig) ServerOption {
+	return func(s *Server) {
+		if config.LivenessPath == "" {
+			config.LivenessPath = "/livez"
+		}
+		if config.ReadinessPath == "" {
+			config.ReadinessPath = "/readyz"
+		}
+		if config.HealthPath == "" {
+			config.HealthPath = "/healthz"
+		}
+		for _, check := range config.Checks {
+			s.AddHealthCheck(check)
+		}
+		s.healthConfig = &config
+	}
+}
+
+// registerHealthRoutes registers the health endpoints configured with [WithHealthChecks].
+func (s *Server) registerHealthRoutes() {
+	options := []RouteOption{optionInternal(), OptionHide()}
+	if s.healthConfig.Documented {
+		response := Response{Type: HealthResponse{}, ContentTypes: []string{HealthContentType}}
+		options = []RouteOption{
+			optionInternal(),
+			OptionTags("Health"),
+			OptionAddResponse(http.StatusOK, "Healthy", response),
+			OptionAddResponse(http.StatusServiceUnavailable, "Unhealthy", response),
+		}
+	}
+	verbose := OptionQueryBool("verbose", "Give the details of the checks")
+
+	GetStd(s, s.healthConfig.LivenessPath, s.Engine.LivenessHandler().ServeHTTP,
+		append(options, OptionSummary("Liveness"), verbose)...)
+	GetStd(s, s.healthConfig.ReadinessPath, s.Engine.ReadinessHandler().ServeHTTP,
+		append(options, OptionSummary("Readiness"), verbose)...)
+	GetStd(s, s.healthConfig.HealthPath, s.Engine.HealthHandler().ServeHTTP,
+		append(options, OptionSummary("Health"))...)
+}
+
+// HealthCheckPing checks a dependency with a PingContext method, like a [database/sql.DB].
+func HealthCheckPing(pinger interface{ PingContext(context.Context) error }) func(context.Context) error {
+	return pinger.PingContext
+}
+
+// HealthCheckHTTP checks that a dependent HTTP service answers the given URL with a 2xx status code.
+func HealthCheckHTTP(url string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return errors.New("unexpected status " + resp.Status)
+		}
+		return nil
+	}
+}
+
+// HealthCheckDiskSpace checks that the filesystem of the given path has at least minFree bytes available.
+// It is only supported on Linux and macOS.
+func HealthCheckDiskSpace(path string, minFree uint64) func(context.Context) error {
+	return func(context.Context) error {
+		free, err := diskFree(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%d bytes available on %s, less than %d", free, path, minFree)
+		}
+		return nil
+	}
+}
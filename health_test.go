@@ -0,0 +1,160 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func healthRequest(t *testing.T, s *Server, path string) (int, HealthResponse) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, HealthContentType, w.Header().Get("Content-Type"))
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestWithHealthChecks(t *testing.T) {
+	var dbDown atomic.Bool
+
+	s := NewServer(
+		WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})),
+		WithHealthChecks(HealthConfig{
+			Checks: []HealthCheck{
+				{Name: "db", Check: func(ctx context.Context) error {
+					if dbDown.Load() {
+						return errors.New("connection refused")
+					}
+					return nil
+				}},
+				{Name: "goroutines", Check: func(ctx context.Context) error { return nil }, Liveness: true},
+			},
+		}),
+	)
+	s.AddHealthCheck(HealthCheck{Name: "cache", Check: func(ctx context.Context) error { return errors.New("cache down") }, NonCritical: true})
+	require.NoError(t, s.Start(context.Background()))
+
+	t.Run("readiness", func(t *testing.T) {
+		code, response := healthRequest(t, s, "/readyz")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, HealthWarn, response.Status, "non-critical failures are warnings")
+		assert.Nil(t, response.Checks)
+	})
+
+	t.Run("verbose", func(t *testing.T) {
+		_, response := healthRequest(t, s, "/readyz?verbose")
+		require.Len(t, response.Checks, 3)
+		assert.Equal(t, HealthPass, response.Checks["db"][0].Status)
+		assert.Equal(t, HealthWarn, response.Checks["cache"][0].Status)
+		assert.Equal(t, "cache down", response.Checks["cache"][0].Output)
+		assert.Equal(t, "ms", response.Checks["db"][0].ObservedUnit)
+
+		_, response = healthRequest(t, s, "/healthz")
+		assert.Len(t, response.Checks, 3)
+	})
+
+	t.Run("failing check", func(t *testing.T) {
+		dbDown.Store(true)
+		defer dbDown.Store(false)
+
+		code, response := healthRequest(t, s, "/healthz")
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, HealthFail, response.Status)
+		assert.Equal(t, "connection refused", response.Checks["db"][0].Output)
+
+		code, response = healthRequest(t, s, "/livez?verbose=true")
+		require.Equal(t, http.StatusOK, code, "liveness only runs liveness checks")
+		assert.Equal(t, []string{"goroutines"}, slices.Collect(maps.Keys(response.Checks)))
+	})
+
+	t.Run("readiness fails while draining", func(t *testing.T) {
+		require.NoError(t, s.Stop(context.Background()))
+		defer s.Start(context.Background())
+
+		code, response := healthRequest(t, s, "/readyz")
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "not ready", response.Output)
+
+		code, _ = healthRequest(t, s, "/livez")
+		require.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("hidden from the OpenAPI description", func(t *testing.T) {
+		assert.Nil(t, s.OpenAPI.Description().Paths.Find("/readyz"))
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		assert.Panics(t, func() {
+			s.AddHealthCheck(HealthCheck{Name: "db", Check: func(ctx context.Context) error { return nil }})
+		})
+	})
+}
+
+func TestHealthCheckTimeoutAndCache(t *testing.T) {
+	e := NewEngine()
+	require.NoError(t, e.Start(context.Background()))
+
+	var calls atomic.Int32
+	e.AddHealthCheck(HealthCheck{
+		Name: "slow",
+		Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Timeout: 10 * time.Millisecond,
+	})
+	e.AddHealthCheck(HealthCheck{
+		Name: "cached",
+		Check: func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+		CacheDuration: time.Minute,
+	})
+
+	response := e.CheckHealth(context.Background(), false)
+	assert.Equal(t, HealthFail, response.Status)
+	assert.Equal(t, "timed out after 10ms", response.Checks["slow"][0].Output)
+
+	e.CheckHealth(context.Background(), false)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHealthChecksDocumented(t *testing.T) {
+	s := NewServer(
+		WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})),
+		WithHealthChecks(HealthConfig{ReadinessPath: "/ready", Documented: true}),
+	)
+
+	operation := s.OpenAPI.Description().Paths.Find("/ready").Get
+	require.NotNil(t, operation)
+	assert.Equal(t, []string{"Health"}, operation.Tags)
+	assert.Contains(t, operation.Responses.Value("503").Value.Content, HealthContentType)
+	assert.NotNil(t, s.OpenAPI.Description().Paths.Find("/livez"))
+}
+
+func TestHealthCheckHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer upstream.Close()
+
+	require.NoError(t, HealthCheckHTTP(upstream.URL+"/ok")(context.Background()))
+	require.ErrorContains(t, HealthCheckHTTP(upstream.URL+"/down")(context.Background()), "503")
+}
@@ -113,7 +113,7 @@ func (s StdRenderer) Render(ctx context.Context, w io.Writer) error {
 
 // loadTemplates
 func (s *Server) loadTemplates(patterns ...string) error {
-	tmpl, err := template.ParseFS(s.fs, patterns...)
+	tmpl, err := template.New("").Funcs(s.Engine.templateFuncs()).ParseFS(s.fs, patterns...)
 	if err != nil {
 		return fmt.Errorf("failed to parse templates: %w", err)
 	}
@@ -34,6 +34,11 @@ type OpenAPIParam struct {
 	// If empty, it is required for 200 status codes.
 	StatusCodes []int
 
+	// If true, it is also required for the default status code of the route,
+	// known once all the options of the route are applied.
+	// Only used for response parameters.
+	DefaultStatusCode bool
+
 	Required bool
 	Nullable bool
 }
@@ -47,10 +52,21 @@ type CommonContext[B any] struct {
 
 	// default status code for the response
 	DefaultStatusCode int
+
+	// Builds the URL of a named route, set by the adaptor to the engine's URLFor.
+	URLBuilder func(name string, args map[string]any, query any) (string, error)
 }
 
 type ParamType string // Query, Header, Cookie
 
+// URLFor builds the URL of the route with the given name.
+func (c CommonContext[B]) URLFor(name string, args map[string]any, query any) (string, error) {
+	if c.URLBuilder == nil {
+		return "", fmt.Errorf("cannot build URL for route %q: no URL builder set on the context", name)
+	}
+	return c.URLBuilder(name, args, query)
+}
+
 // GetOpenAPIParams returns the OpenAPI parameters declared in the OpenAPI spec.
 func (c CommonContext[B]) GetOpenAPIParams() map[string]OpenAPIParam {
 	return c.OpenAPIParams
@@ -0,0 +1,106 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// JSONCodec encodes and decodes JSON.
+// It is used for request bodies, responses, errors and the OpenAPI spec.
+// Set it with [WithJSONCodec] to change the JSON semantics (see [JSONv2Codec])
+// or to use a faster third-party library.
+type JSONCodec interface {
+	// Marshal returns the JSON encoding of v.
+	Marshal(v any) ([]byte, error)
+	// MarshalIndent is like Marshal, but indents the output. Used for the pretty-printed OpenAPI spec.
+	MarshalIndent(v any, prefix, indent string) ([]byte, error)
+	// NewDecoder returns a decoder reading JSON values from r.
+	// The decoder must return [io.EOF] when r is empty.
+	// Unless the codec implements [UnknownFieldsRejecter], Fuego checks the unknown fields itself.
+	NewDecoder(r io.Reader, options JSONDecoderOptions) JSONDecoder
+}
+
+// UnknownFieldsRejecter is implemented by the [JSONCodec] honoring [JSONDecoderOptions].DisallowUnknownFields.
+// For the other codecs, Fuego rejects the request bodies with unknown fields itself,
+// by decoding them a second time with encoding/json.
+type UnknownFieldsRejecter interface {
+	RejectsUnknownFields() bool
+}
+
+// JSONDecoder decodes JSON values from an input stream.
+type JSONDecoder interface {
+	Decode(v any) error
+}
+
+// JSONDecoderOptions are the options passed to [JSONCodec.NewDecoder].
+type JSONDecoderOptions struct {
+	// DisallowUnknownFields makes the decoding fail when an object key does not match any field of the destination struct.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers into an interface{} as a [json.Number] instead of as a float64,
+	// to keep the precision of large integers in patch documents. Codecs may ignore it.
+	UseNumber bool
+}
+
+// StdJSONCodec is the default [JSONCodec], based on encoding/json.
+type StdJSONCodec struct {
+	// UseNumber decodes numbers into an interface{} as a [json.Number] instead of as a float64.
+	UseNumber bool
+}
+
+var (
+	_ JSONCodec             = StdJSONCodec{}
+	_ UnknownFieldsRejecter = StdJSONCodec{}
+)
+
+func (StdJSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (StdJSONCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (StdJSONCodec) RejectsUnknownFields() bool {
+	return true
+}
+
+func (c StdJSONCodec) NewDecoder(r io.Reader, options JSONDecoderOptions) JSONDecoder {
+	dec := json.NewDecoder(r)
+	if options.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if c.UseNumber || options.UseNumber {
+		dec.UseNumber()
+	}
+	return dec
+}
+
+type jsonCodecContextKey struct{}
+
+// withJSONCodec stores the JSON codec in the context, to be used by [SendJSON] and other serializers.
+func withJSONCodec(ctx context.Context, codec JSONCodec) context.Context {
+	return context.WithValue(ctx, jsonCodecContextKey{}, codec)
+}
+
+// jsonCodecFromContext returns the JSON codec set with [WithJSONCodec] for the request, or the default one.
+func jsonCodecFromContext(ctx context.Context) JSONCodec {
+	if codec, ok := ctx.Value(jsonCodecContextKey{}).(JSONCodec); ok {
+		return codec
+	}
+	return StdJSONCodec{}
+}
+
+// rejectsUnknownFields reports whether the codec honors [JSONDecoderOptions].DisallowUnknownFields.
+func rejectsUnknownFields(codec JSONCodec) bool {
+	rejecter, ok := codec.(UnknownFieldsRejecter)
+	return ok && rejecter.RejectsUnknownFields()
+}
+
+// jsonCodecOrDefault returns the codec, or the default one if nil.
+func jsonCodecOrDefault(codec JSONCodec) JSONCodec {
+	if codec == nil {
+		return StdJSONCodec{}
+	}
+	return codec
+}
@@ -0,0 +1,140 @@
+package fuego
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingJSONCodec counts the calls to the underlying codec.
+type recordingJSONCodec struct {
+	StdJSONCodec
+	marshal *int
+	decode  *int
+}
+
+func (c recordingJSONCodec) Marshal(v any) ([]byte, error) {
+	*c.marshal++
+	return c.StdJSONCodec.Marshal(v)
+}
+
+func (c recordingJSONCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	*c.marshal++
+	return c.StdJSONCodec.MarshalIndent(v, prefix, indent)
+}
+
+func (c recordingJSONCodec) NewDecoder(r io.Reader, options JSONDecoderOptions) JSONDecoder {
+	*c.decode++
+	return c.StdJSONCodec.NewDecoder(r, options)
+}
+
+func TestWithJSONCodec(t *testing.T) {
+	t.Run("used for bodies, responses, errors and the spec", func(t *testing.T) {
+		codec := recordingJSONCodec{marshal: new(int), decode: new(int)}
+		s := NewServer(WithEngineOptions(WithJSONCodec(codec)))
+		Post(s, "/", func(c ContextWithBody[ReqBody]) (Resp, error) {
+			body, err := c.Body()
+			if err != nil {
+				return Resp{}, err
+			}
+			return Resp{Message: body.A}, nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"A":"hello"}`))
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"message":"hello"}`, w.Body.String())
+		assert.Equal(t, 1, *codec.decode)
+		assert.Equal(t, 1, *codec.marshal)
+
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"unknown":"field"}`))
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, 2, *codec.decode)
+		assert.Equal(t, 2, *codec.marshal)
+
+		_, err := s.Engine.marshalSpec(s.OpenAPI)
+		require.NoError(t, err)
+		assert.Equal(t, 3, *codec.marshal)
+	})
+
+	t.Run("UseNumber", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithJSONCodec(StdJSONCodec{UseNumber: true})))
+		Post(s, "/", func(c ContextWithBody[map[string]any]) (string, error) {
+			body, err := c.Body()
+			if err != nil {
+				return "", err
+			}
+			number, ok := body["n"].(json.Number)
+			if !ok {
+				return "not a number", nil
+			}
+			return number.String(), nil
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"n":12345678901234567890}`))
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "12345678901234567890", w.Body.String())
+	})
+}
+
+// lenientJSONCodec ignores the decoder options.
+type lenientJSONCodec struct{}
+
+func (lenientJSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (lenientJSONCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (lenientJSONCodec) NewDecoder(r io.Reader, _ JSONDecoderOptions) JSONDecoder {
+	return json.NewDecoder(r)
+}
+
+func TestJSONCodecUnknownFields(t *testing.T) {
+	newServer := func(options ...ServerOption) *Server {
+		s := NewServer(append([]ServerOption{WithEngineOptions(WithJSONCodec(lenientJSONCodec{}))}, options...)...)
+		Post(s, "/", func(c ContextWithBody[ReqBody]) (string, error) {
+			body, err := c.Body()
+			return body.A, err
+		})
+		return s
+	}
+	post := func(s *Server, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Accept", "text/plain")
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("rejected even if the codec ignores the option", func(t *testing.T) {
+		s := newServer()
+		w := post(s, `{"A":"hello"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "hello", w.Body.String())
+
+		w = post(s, `{"A":"hello","unknown":"field"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "unknown")
+
+		require.Equal(t, http.StatusOK, post(s, ``).Code)
+	})
+
+	t.Run("allowed with the option", func(t *testing.T) {
+		s := newServer(WithDisallowUnknownFields(false))
+		require.Equal(t, http.StatusOK, post(s, `{"A":"hello","unknown":"field"}`).Code)
+	})
+}
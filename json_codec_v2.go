@@ -0,0 +1,53 @@
+//go:build go1.27
+
+package fuego
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"io"
+	"slices"
+)
+
+// JSONv2Codec is a [JSONCodec] based on encoding/json/v2.
+// Compared to [StdJSONCodec], field names are matched case-sensitively,
+// nil slices and maps are encoded as empty arrays and objects, and invalid UTF-8 is rejected.
+// Use Options to customize this behavior, for example with [jsonv2.MatchCaseInsensitiveNames].
+// Requires Go 1.27 or later.
+type JSONv2Codec struct {
+	// Options passed to every marshal and unmarshal call.
+	Options []jsonv2.Options
+}
+
+var (
+	_ JSONCodec             = JSONv2Codec{}
+	_ UnknownFieldsRejecter = JSONv2Codec{}
+)
+
+func (c JSONv2Codec) Marshal(v any) ([]byte, error) {
+	return jsonv2.Marshal(v, c.Options...)
+}
+
+func (c JSONv2Codec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return jsonv2.Marshal(v, append(slices.Clone(c.Options), jsontext.WithIndentPrefix(prefix), jsontext.WithIndent(indent))...)
+}
+
+func (JSONv2Codec) RejectsUnknownFields() bool {
+	return true
+}
+
+func (c JSONv2Codec) NewDecoder(r io.Reader, options JSONDecoderOptions) JSONDecoder {
+	return jsonv2Decoder{
+		dec:     jsontext.NewDecoder(r),
+		options: append(slices.Clone(c.Options), jsonv2.RejectUnknownMembers(options.DisallowUnknownFields)),
+	}
+}
+
+type jsonv2Decoder struct {
+	dec     *jsontext.Decoder
+	options []jsonv2.Options
+}
+
+func (d jsonv2Decoder) Decode(v any) error {
+	return jsonv2.UnmarshalDecode(d.dec, v, d.options...)
+}
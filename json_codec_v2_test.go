@@ -0,0 +1,59 @@
+//go:build go1.27
+
+package fuego
+
+import (
+	jsonv2 "encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONv2Codec(t *testing.T) {
+	type Pet struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	newServer := func(options ...ServerOption) *Server {
+		s := NewServer(options...)
+		Post(s, "/", func(c ContextWithBody[Pet]) (Pet, error) {
+			return c.Body()
+		})
+		return s
+	}
+
+	t.Run("case-sensitive field matching with unknown fields disallowed", func(t *testing.T) {
+		s := newServer(WithEngineOptions(WithJSONCodec(JSONv2Codec{})))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"Name":"Rex"}`))
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown fields allowed", func(t *testing.T) {
+		s := newServer(WithEngineOptions(WithJSONCodec(JSONv2Codec{})), WithDisallowUnknownFields(false))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"Name":"Rex"}`))
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name":"","tags":[]}`, w.Body.String())
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		s := newServer(WithEngineOptions(WithJSONCodec(JSONv2Codec{
+			Options: []jsonv2.Options{jsonv2.MatchCaseInsensitiveNames(true)},
+		})))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"Name":"Rex"}`))
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name":"Rex","tags":[]}`, w.Body.String())
+	})
+}
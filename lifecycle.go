@@ -0,0 +1,182 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// A LifecycleHook is a function run when the server starts or shuts down,
+// see [Engine.OnStart] and [Engine.OnShutdown].
+type LifecycleHook = func(ctx context.Context) error
+
+// OnStart registers a hook run before the server accepts requests, for example to warm up a cache.
+// Hooks are run in the order they were registered. If one fails, the following hooks are not run and the server does not start.
+//
+// The fuego [Server] runs them in [Server.Run]. With other routers, call [Engine.Start] before serving.
+func (e *Engine) OnStart(hook LifecycleHook) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.startHooks = append(e.startHooks, hook)
+}
+
+// OnShutdown registers a hook run when the server shuts down, after the in-flight requests are drained,
+// for example to close database pools or flush telemetry.
+// Hooks are run in the order they were registered. All of them are run, and their errors are aggregated.
+//
+// The fuego [Server] runs them when it shuts down. With other routers, call [Engine.Stop] after the server is shut down.
+func (e *Engine) OnShutdown(hook LifecycleHook) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.shutdownHooks = append(e.shutdownHooks, hook)
+}
+
+// Start runs the [Engine.OnStart] hooks in order and marks the engine as ready, see [Engine.Ready].
+// It stops at the first hook returning an error.
+// It is called by [Server.Run]: only call it when using another router, like Gin or Echo.
+//
+//	if err := engine.Start(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+//	ginRouter.Run(":8080")
+func (e *Engine) Start(ctx context.Context) error {
+	e.hooksMu.Lock()
+	hooks := e.startHooks
+	e.hooksMu.Unlock()
+
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("start hook %d: %w", i, err)
+		}
+	}
+	e.ready.Store(true)
+	return nil
+}
+
+// Stop marks the engine as not ready and runs the [Engine.OnShutdown] hooks in order.
+// All hooks are run, even if some of them fail, and the errors are joined.
+// It is called when the [Server] shuts down: only call it when using another router, like Gin or Echo.
+func (e *Engine) Stop(ctx context.Context) error {
+	e.ready.Store(false)
+
+	e.hooksMu.Lock()
+	hooks := e.shutdownHooks
+	e.hooksMu.Unlock()
+
+	var errs []error
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ready reports whether the engine is started and not shutting down.
+// It is false before [Engine.Start], and as soon as the server starts draining its connections,
+// so that load balancers stop sending new requests.
+func (e *Engine) Ready() bool {
+	return e.ready.Load()
+}
+
+// ShutdownConfig configures the graceful shutdown of the [Server], see [WithShutdown].
+type ShutdownConfig struct {
+	// Maximum time to wait for in-flight requests to complete, then for the [Engine.OnShutdown] hooks.
+	// When exceeded, remaining connections are closed. Defaults to 30s.
+	Timeout time.Duration
+	// Time to wait between marking the server as not ready and draining the connections,
+	// so that load balancers have time to notice it. Defaults to 0.
+	ReadinessDelay time.Duration
+	// Signals triggering the shutdown. Defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+	// If true, signals are not handled: the server is only shut down when the context given to [Server.RunContext] is canceled.
+	DisableSignalHandling bool
+}
+
+var defaultShutdownConfig = ShutdownConfig{
+	Timeout: 30 * time.Second,
+	Signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+}
+
+// WithShutdown configures the graceful shutdown of the server.
+// On SIGINT or SIGTERM, or when the context given to [Server.RunContext] is canceled, the server:
+//  1. is marked as not ready, see [Engine.Ready],
+//  2. waits for the ReadinessDelay,
+//  3. stops accepting connections and waits for in-flight requests, at most for the Timeout,
+//  4. runs the [Engine.OnShutdown] hooks.
+//
+// Example:
+//
+//	app := fuego.NewServer(
+//		fuego.WithShutdown(fuego.ShutdownConfig{
+//			Timeout:        10 * time.Second,
+//			ReadinessDelay: 5 * time.Second,
+//		}),
+//	)
+func WithShutdown(config ShutdownConfig) ServerOption {
+	return func(s *Server) {
+		if config.Timeout <= 0 {
+			config.Timeout = defaultShutdownConfig.Timeout
+		}
+		if len(config.Signals) == 0 {
+			config.Signals = defaultShutdownConfig.Signals
+		}
+		s.shutdownConfig = config
+	}
+}
+
+// serveWithContext runs the start hooks and the server, and shuts it down
+// gracefully when the context is canceled or a shutdown signal is received.
+func (s *Server) serveWithContext(ctx context.Context, serve func() error) error {
+	if !s.shutdownConfig.DisableSignalHandling {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, s.shutdownConfig.Signals...)
+		defer stop()
+	}
+
+	if err := s.Engine.Start(ctx); err != nil {
+		s.listener.Close()
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- serve() }()
+
+	select {
+	case err := <-errCh:
+		hooksCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownConfig.Timeout)
+		defer cancel()
+		if hooksErr := s.Engine.Stop(hooksCtx); hooksErr != nil {
+			return errors.Join(err, hooksErr)
+		}
+		return err
+	case <-ctx.Done():
+		return s.shutdown(context.WithoutCancel(ctx))
+	}
+}
+
+// shutdown flips the readiness, drains the in-flight requests and runs the shutdown hooks.
+func (s *Server) shutdown(ctx context.Context) error {
+	s.Engine.ready.Store(false)
+	if !s.disableStartupMessages {
+		slog.Info("Server shutting down", "timeout", s.shutdownConfig.Timeout.String())
+	}
+	time.Sleep(s.shutdownConfig.ReadinessDelay)
+
+	drainCtx, cancel := context.WithTimeout(ctx, s.shutdownConfig.Timeout)
+	defer cancel()
+	err := s.Shutdown(drainCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		slog.Warn("Shutdown timeout exceeded, closing remaining connections", "timeout", s.shutdownConfig.Timeout.String())
+		s.Close()
+	}
+
+	hooksCtx, cancel := context.WithTimeout(ctx, s.shutdownConfig.Timeout)
+	defer cancel()
+	return errors.Join(err, s.Engine.Stop(hooksCtx))
+}
@@ -0,0 +1,198 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineLifecycleHooks(t *testing.T) {
+	t.Run("hooks are run in order", func(t *testing.T) {
+		e := NewEngine()
+		var calls []string
+		e.OnStart(func(ctx context.Context) error { calls = append(calls, "start 1"); return nil })
+		e.OnStart(func(ctx context.Context) error { calls = append(calls, "start 2"); return nil })
+		e.OnShutdown(func(ctx context.Context) error { calls = append(calls, "shutdown 1"); return nil })
+		e.OnShutdown(func(ctx context.Context) error { calls = append(calls, "shutdown 2"); return nil })
+
+		require.False(t, e.Ready())
+		require.NoError(t, e.Start(context.Background()))
+		require.True(t, e.Ready())
+		require.NoError(t, e.Stop(context.Background()))
+		require.False(t, e.Ready())
+
+		assert.Equal(t, []string{"start 1", "start 2", "shutdown 1", "shutdown 2"}, calls)
+	})
+
+	t.Run("start stops at the first error", func(t *testing.T) {
+		e := NewEngine()
+		errDB := errors.New("db unreachable")
+		called := false
+		e.OnStart(func(ctx context.Context) error { return errDB })
+		e.OnStart(func(ctx context.Context) error { called = true; return nil })
+
+		err := e.Start(context.Background())
+		require.ErrorIs(t, err, errDB)
+		assert.False(t, called)
+		assert.False(t, e.Ready())
+	})
+
+	t.Run("shutdown errors are aggregated", func(t *testing.T) {
+		e := NewEngine()
+		errPool := errors.New("pool")
+		errFlush := errors.New("flush")
+		called := false
+		e.OnShutdown(func(ctx context.Context) error { return errPool })
+		e.OnShutdown(func(ctx context.Context) error { called = true; return nil })
+		e.OnShutdown(func(ctx context.Context) error { return errFlush })
+
+		err := e.Stop(context.Background())
+		require.ErrorIs(t, err, errPool)
+		require.ErrorIs(t, err, errFlush)
+		assert.True(t, called)
+	})
+}
+
+func newLifecycleTestServer(t *testing.T, options ...ServerOption) (*Server, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	options = append([]ServerOption{WithListener(listener), WithoutLogger(), WithoutStartupMessages(), WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{Disabled: true}))}, options...)
+	return NewServer(options...), "http://" + listener.Addr().String()
+}
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	t.Run("drains in-flight requests before running the shutdown hooks", func(t *testing.T) {
+		s, url := newLifecycleTestServer(t, WithShutdown(ShutdownConfig{DisableSignalHandling: true}))
+
+		var mu sync.Mutex
+		var events []string
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		Get(s, "/slow", func(c ContextNoBody) (string, error) {
+			close(started)
+			<-release
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, "request")
+			return "done", nil
+		})
+		s.OnShutdown(func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, "shutdown hook")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() { runErr <- s.RunContext(ctx) }()
+		require.Eventually(t, s.Ready, 5*time.Second, 10*time.Millisecond)
+
+		responseCode := make(chan int, 1)
+		go func() {
+			resp, err := http.Get(url + "/slow")
+			if err != nil {
+				responseCode <- 0
+				return
+			}
+			resp.Body.Close()
+			responseCode <- resp.StatusCode
+		}()
+		<-started
+
+		cancel()
+		require.Eventually(t, func() bool { return !s.Ready() }, 5*time.Second, 10*time.Millisecond, "readiness flips before draining")
+
+		close(release)
+		require.Equal(t, http.StatusOK, <-responseCode)
+		require.NoError(t, <-runErr)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{"request", "shutdown hook"}, events)
+	})
+
+	t.Run("drain timeout", func(t *testing.T) {
+		s, url := newLifecycleTestServer(t, WithShutdown(ShutdownConfig{Timeout: 100 * time.Millisecond, DisableSignalHandling: true}))
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		Get(s, "/stuck", func(c ContextNoBody) (string, error) {
+			close(started)
+			<-release
+			return "never", nil
+		})
+		hookCalled := false
+		s.OnShutdown(func(ctx context.Context) error { hookCalled = true; return nil })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() { runErr <- s.RunContext(ctx) }()
+		require.Eventually(t, s.Ready, 5*time.Second, 10*time.Millisecond)
+
+		go func() {
+			resp, err := http.Get(url + "/stuck")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+
+		cancel()
+		select {
+		case err := <-runErr:
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(5 * time.Second):
+			t.Fatal("shutdown did not respect the drain timeout")
+		}
+		assert.True(t, hookCalled)
+	})
+
+	t.Run("start hook error prevents the server from starting", func(t *testing.T) {
+		s, _ := newLifecycleTestServer(t, WithShutdown(ShutdownConfig{DisableSignalHandling: true}))
+		errDB := errors.New("db unreachable")
+		s.OnStart(func(ctx context.Context) error { return errDB })
+
+		err := s.RunContext(context.Background())
+		require.ErrorIs(t, err, errDB)
+		assert.False(t, s.Ready())
+	})
+
+	t.Run("shutdown on SIGTERM", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("signals cannot be sent on Windows")
+		}
+		s, _ := newLifecycleTestServer(t)
+		hookCalled := make(chan struct{})
+		s.OnShutdown(func(ctx context.Context) error { close(hookCalled); return nil })
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- s.Run() }()
+		require.Eventually(t, s.Ready, 5*time.Second, 10*time.Millisecond)
+
+		process, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, process.Signal(syscall.SIGTERM))
+
+		select {
+		case err := <-runErr:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("server was not shut down by SIGTERM")
+		}
+		<-hookCalled
+	})
+}
@@ -0,0 +1,277 @@
+package fuego
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsConfig configures the metrics of the server, see [WithMetrics].
+type MetricsConfig struct {
+	// Path of the metrics endpoint. Defaults to /metrics.
+	Path string
+	// Prefix of the metric names, for example "myapp" for myapp_http_requests_total. Defaults to none.
+	Namespace string
+	// Buckets of the duration histograms, in seconds.
+	// Defaults to .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5 and 10, like the Prometheus client.
+	DurationBuckets []float64
+	// Buckets of the response size histogram, in bytes. Defaults to 100B, 1kB, 10kB, 100kB, 1MB and 10MB.
+	SizeBuckets []float64
+}
+
+var defaultMetricsConfig = MetricsConfig{
+	Path:            "/metrics",
+	DurationBuckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	SizeBuckets:     []float64{100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000},
+}
+
+// WithMetrics records RED metrics (rate, errors, duration) of the routes, and exposes them
+// in the Prometheus text format at a hidden /metrics endpoint:
+//   - http_requests_total: counter of requests, by method, route and status.
+//   - http_request_duration_seconds: histogram of the request durations, by method, route and status.
+//   - http_response_size_bytes: histogram of the response sizes, by method, route and status.
+//   - http_requests_in_flight: gauge of the requests being served, by method and route.
+//   - http_phase_duration_seconds: histogram of the durations of the phases of the controllers, by method, route and phase
+//     (fuegoReqInit, deserialize, controller, transformOut and serialize), as in the Server-Timing header.
+//
+// The route label is the pattern of the route, like /pets/{id}, and not the requested URL, to keep a low cardinality.
+// The endpoints of Fuego itself, like the metrics, OpenAPI and health endpoints, are not measured.
+//
+//	app := fuego.NewServer(
+//		fuego.WithMetrics(fuego.MetricsConfig{Namespace: "myapp"}),
+//	)
+func WithMetrics(config MetricsConfig) ServerOption {
+	return func(s *Server) {
+		if config.Path == "" {
+			config.Path = defaultMetricsConfig.Path
+		}
+		if len(config.DurationBuckets) == 0 {
+			config.DurationBuckets = defaultMetricsConfig.DurationBuckets
+		}
+		if len(config.SizeBuckets) == 0 {
+			config.SizeBuckets = defaultMetricsConfig.SizeBuckets
+		}
+		s.metrics = newMetrics(config)
+	}
+}
+
+// metrics records the metrics of the routes.
+type metrics struct {
+	config MetricsConfig
+
+	mu        sync.Mutex
+	requests  map[metricLabels]uint64
+	durations map[metricLabels]*histogram
+	sizes     map[metricLabels]*histogram
+	inFlight  map[metricLabels]int64
+	phases    map[metricLabels]*histogram
+}
+
+// metricLabels are the labels of a series. Unused labels are empty.
+type metricLabels struct {
+	method, route, status, phase string
+}
+
+func newMetrics(config MetricsConfig) *metrics {
+	return &metrics{
+		config:    config,
+		requests:  make(map[metricLabels]uint64),
+		durations: make(map[metricLabels]*histogram),
+		sizes:     make(map[metricLabels]*histogram),
+		inFlight:  make(map[metricLabels]int64),
+		phases:    make(map[metricLabels]*histogram),
+	}
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func observe(series map[metricLabels]*histogram, labels metricLabels, buckets []float64, value float64) {
+	h, ok := series[labels]
+	if !ok {
+		h = &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+		series[labels] = h
+	}
+	h.observe(value)
+}
+
+// middleware measures the requests of the route with the given method and path pattern.
+func (m *metrics) middleware(method, route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method := method
+			if method == "" {
+				method = r.Method
+			}
+			routeLabels := metricLabels{method: method, route: route}
+
+			m.mu.Lock()
+			m.inFlight[routeLabels]++
+			m.mu.Unlock()
+
+			phases := &phaseTimings{}
+			r = r.WithContext(WithPhaseObserver(r.Context(), phases.observe))
+			wrapped := &sizeResponseWriter{responseWriter: newResponseWriter(w)}
+			start := time.Now()
+
+			defer func() {
+				duration := time.Since(start).Seconds()
+				status := wrapped.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				labels := metricLabels{method: method, route: route, status: strconv.Itoa(status)}
+
+				m.mu.Lock()
+				defer m.mu.Unlock()
+				m.inFlight[routeLabels]--
+				m.requests[labels]++
+				observe(m.durations, labels, m.config.DurationBuckets, duration)
+				observe(m.sizes, labels, m.config.SizeBuckets, float64(wrapped.size))
+				for _, phase := range phases.get() {
+					observe(m.phases, metricLabels{method: method, route: route, phase: phase.Name}, m.config.DurationBuckets, phase.Dur.Seconds())
+				}
+			}()
+
+			next.ServeHTTP(wrapped, r)
+		})
+	}
+}
+
+// sizeResponseWriter counts the bytes of the response body.
+type sizeResponseWriter struct {
+	*responseWriter
+	size int
+}
+
+func (w *sizeResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.responseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+func (w *sizeResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// phaseTimings collects the durations of the phases of [Flow], for the metrics of the request.
+type phaseTimings struct {
+	mu     sync.Mutex
+	phases []Timing
+}
+
+func (p *phaseTimings) observe(_ context.Context, phase string, _ time.Time, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.phases = append(p.phases, Timing{Name: phase, Dur: duration})
+}
+
+func (p *phaseTimings) get() []Timing {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.phases)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := func(metric string) string {
+		if m.config.Namespace == "" {
+			return metric
+		}
+		return m.config.Namespace + "_" + metric
+	}
+
+	requests := name("http_requests_total")
+	fmt.Fprintf(w, "# HELP %s Total number of HTTP requests.\n# TYPE %s counter\n", requests, requests)
+	for _, labels := range sortedLabels(m.requests) {
+		fmt.Fprintf(w, "%s%s %d\n", requests, labels.format(""), m.requests[labels])
+	}
+
+	writeHistograms(w, name("http_request_duration_seconds"), "Duration of the HTTP requests in seconds.", m.durations)
+	writeHistograms(w, name("http_response_size_bytes"), "Size of the HTTP responses in bytes.", m.sizes)
+
+	inFlight := name("http_requests_in_flight")
+	fmt.Fprintf(w, "# HELP %s Number of HTTP requests being served.\n# TYPE %s gauge\n", inFlight, inFlight)
+	for _, labels := range sortedLabels(m.inFlight) {
+		fmt.Fprintf(w, "%s%s %d\n", inFlight, labels.format(""), m.inFlight[labels])
+	}
+
+	writeHistograms(w, name("http_phase_duration_seconds"), "Duration of the phases of the controllers in seconds.", m.phases)
+}
+
+func writeHistograms(w io.Writer, name, help string, series map[metricLabels]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, labels := range sortedLabels(series) {
+		h := series[labels]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels.format(formatFloat(bound)), h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels.format("+Inf"), h.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, labels.format(""), formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, labels.format(""), h.count)
+	}
+}
+
+func sortedLabels[V any](series map[metricLabels]V) []metricLabels {
+	labels := make([]metricLabels, 0, len(series))
+	for l := range series {
+		labels = append(labels, l)
+	}
+	slices.SortFunc(labels, func(a, b metricLabels) int {
+		return strings.Compare(a.route+" "+a.method+" "+a.status+" "+a.phase, b.route+" "+b.method+" "+b.status+" "+b.phase)
+	})
+	return labels
+}
+
+// format formats the labels as {method="GET",route="/pets"}, with the le label of histogram buckets if not empty.
+func (l metricLabels) format(le string) string {
+	pairs := []string{`method="` + escapeLabel(l.method) + `"`, `route="` + escapeLabel(l.route) + `"`}
+	if l.status != "" {
+		pairs = append(pairs, `status="`+l.status+`"`)
+	}
+	if l.phase != "" {
+		pairs = append(pairs, `phase="`+escapeLabel(l.phase)+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,90 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMetrics(t *testing.T) {
+	s := NewServer(
+		WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})),
+		WithMetrics(MetricsConfig{Namespace: "app", DurationBuckets: []float64{0.1, 1}}),
+		WithHealthChecks(HealthConfig{Documented: true}),
+	)
+	Get(s, "/pets/{id}", func(c ContextNoBody) (ans, error) {
+		if c.PathParam("id") == "0" {
+			return ans{}, NotFoundError{}
+		}
+		return ans{Ans: "pet"}, nil
+	})
+	Post(s, "/pets", func(c ContextWithBody[ans]) (ans, error) {
+		return c.Body()
+	})
+	Get(s, "/hidden", func(c ContextNoBody) (string, error) {
+		return "hidden", nil
+	}, OptionHide())
+
+	Handle(s, "/static/", http.NotFoundHandler())
+
+	for _, path := range []string{"/pets/1", "/pets/2", "/pets/0", "/hidden", "/static/app.js", "/healthz"} {
+		s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	r := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"ans":"new"}`))
+	r.Header.Set("Content-Type", "application/json")
+	s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+	r = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+
+	t.Run("requests by route pattern and status", func(t *testing.T) {
+		assert.Contains(t, body, "# TYPE app_http_requests_total counter\n")
+		assert.Contains(t, body, `app_http_requests_total{method="GET",route="/pets/{id}",status="200"} 2`+"\n")
+		assert.Contains(t, body, `app_http_requests_total{method="GET",route="/pets/{id}",status="404"} 1`+"\n")
+		assert.Contains(t, body, `app_http_requests_total{method="POST",route="/pets",status="200"} 1`+"\n")
+		assert.NotContains(t, body, `route="/pets/1"`)
+	})
+
+	t.Run("durations and sizes", func(t *testing.T) {
+		assert.Contains(t, body, "# TYPE app_http_request_duration_seconds histogram\n")
+		assert.Contains(t, body, `app_http_request_duration_seconds_bucket{method="GET",route="/pets/{id}",status="200",le="1"} 2`+"\n")
+		assert.Contains(t, body, `app_http_request_duration_seconds_bucket{method="GET",route="/pets/{id}",status="200",le="+Inf"} 2`+"\n")
+		assert.Contains(t, body, `app_http_request_duration_seconds_count{method="GET",route="/pets/{id}",status="200"} 2`+"\n")
+		assert.Contains(t, body, `app_http_response_size_bytes_count{method="GET",route="/pets/{id}",status="200"} 2`+"\n")
+		assert.Contains(t, body, `app_http_requests_in_flight{method="GET",route="/pets/{id}"} 0`+"\n")
+	})
+
+	t.Run("phases", func(t *testing.T) {
+		for _, phase := range []string{"fuegoReqInit", "controller"} {
+			assert.Contains(t, body, `app_http_phase_duration_seconds_count{method="GET",route="/pets/{id}",phase="`+phase+`"} 3`+"\n", "errors too")
+		}
+		for _, phase := range []string{"transformOut", "serialize"} {
+			assert.Contains(t, body, `app_http_phase_duration_seconds_count{method="GET",route="/pets/{id}",phase="`+phase+`"} 2`+"\n", phase)
+		}
+		assert.Contains(t, body, `app_http_phase_duration_seconds_count{method="POST",route="/pets",phase="deserialize"} 1`+"\n")
+	})
+
+	t.Run("hidden routes are measured", func(t *testing.T) {
+		assert.Contains(t, body, `app_http_requests_total{method="GET",route="/hidden",status="200"} 1`+"\n")
+		assert.Contains(t, body, `app_http_requests_total{method="GET",route="/static/",status="404"} 1`+"\n")
+	})
+
+	t.Run("the endpoints of Fuego are not measured", func(t *testing.T) {
+		assert.NotContains(t, body, `route="/healthz"`)
+		assert.NotContains(t, body, `route="/metrics"`)
+		assert.Nil(t, s.OpenAPI.Description().Paths.Find("/metrics"))
+	})
+}
+
+func TestMetricLabelsEscaping(t *testing.T) {
+	labels := metricLabels{method: "GET", route: `/a"b\c`}
+	assert.Equal(t, `{method="GET",route="/a\"b\\c",le="0.5"}`, labels.format("0.5"))
+}
@@ -2,37 +2,108 @@ package cache
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// ErrNotFound is returned by [Storage.Get] when no value is stored under the key.
+var ErrNotFound = errors.New("cache: not found")
+
+// Storage stores the cached responses. Implementations must be safe for concurrent use.
 type Storage interface {
-	Get(key string) (string, bool)
-	Set(key, value string)
+	// Get returns the value stored under the key, or [ErrNotFound].
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value under the key for the ttl (forever if 0), associated with the tags.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	// Delete removes the values stored under the keys.
+	Delete(ctx context.Context, keys ...string) error
+	// DeleteTags removes the values associated with any of the tags.
+	DeleteTags(ctx context.Context, tags ...string) error
 }
 
 type Config struct {
 	Storage Storage
-	Key     func(r *http.Request) string // Key returns the cache key for the request
+	// Key returns the cache key for the request. Defaults to the method and the URL, query string included.
+	// The values of the request headers listed in the Vary header of the response are added to it.
+	Key func(r *http.Request) string
+	// DefaultTTL is the freshness lifetime of responses without a Cache-Control max-age, s-maxage or Expires header.
+	// Defaults to the TTL of the storage created by [NewInMemoryCache], or to 3 seconds.
+	DefaultTTL time.Duration
+}
+
+// defaultTTLStorage is implemented by the storages with a default TTL, used as the default [Config.DefaultTTL].
+type defaultTTLStorage interface {
+	defaultTTL() time.Duration
 }
 
-// Cache the response of GET requests for a given duration.
-// By default, it will use an in-memory cache with a duration of 3 seconds, with a maximum of 1000 entries.
+// XCacheHeader tells whether the response was served from the cache.
+const XCacheHeader = "X-Cache"
+
+// Values of the [XCacheHeader] header.
+const (
+	XCacheHit   = "HIT"   // served from the cache
+	XCacheStale = "STALE" // served from the cache while being revalidated in the background
+	XCacheMiss  = "MISS"  // served by the handler
+)
+
+// CacheTagHeader is a response header listing comma-separated tags for the response,
+// to purge it with [Cache.PurgeTags]. It is not sent to the client.
+const CacheTagHeader = "Cache-Tag"
+
+// Cache the response of GET and HEAD requests, as a shared cache following RFC 9111.
+// It is a shortcut for NewCache(config...).Middleware, see [NewCache].
+func New(config ...Config) func(http.Handler) http.Handler {
+	return NewCache(config...).Middleware
+}
+
+// Cache is a shared HTTP cache following RFC 9111, used as a middleware with [Cache.Middleware].
+type Cache struct {
+	config       Config
+	revalidating sync.Map // keys being revalidated in the background
+
+	mu       sync.Mutex
+	inflight map[string]chan struct{} // keys of the responses being generated, closed when done
+}
+
+// NewCache creates a cache for the responses of GET and HEAD requests.
+// By default, it will use an in-memory cache with a maximum of 1000 entries.
 // You can provide your own storage implementation by passing a Config struct to the middleware.
 // You can also provide your own key function to generate the cache key for a given request.
+//
+// The Cache-Control header of the response is respected:
+//   - max-age, s-maxage (or Expires) set how long the response is fresh, DefaultTTL otherwise
+//   - no-store, private and no-cache responses are not stored
+//   - stale-while-revalidate serves a stale response while the handler refreshes it in the background
+//
+// Responses are stored with their status code and headers, and are varied according to their Vary header.
+// Cached responses have an Age header, and all responses an X-Cache header (HIT, STALE or MISS).
+// Concurrent requests for a response not in the cache wait for the first one instead of running the handler.
+//
 // Headers can be used to invalidate the cache:
 //   - Cache-Control: no-cache will bypass the cache
 //   - Cache-Control: no-store might use the cache but will not store the response in the cache
-func New(config ...Config) func(http.Handler) http.Handler {
+//
+// Successful POST, PUT, PATCH and DELETE requests purge the responses for their path.
+func NewCache(config ...Config) *Cache {
 	if len(config) > 1 {
 		panic("Only one config is allowed")
 	}
 
 	c := Config{
-		Storage: NewInMemoryCache(3*time.Second, 1000),
+		Storage: NewInMemoryCache(0, 1000),
 		Key: func(r *http.Request) string {
-			return "httpcache_" + r.URL.Path + "_" + r.Header.Get("Content-Type")
+			return "httpcache_" + http.MethodGet + "_" + r.Host + r.URL.RequestURI()
 		},
+		DefaultTTL: 3 * time.Second,
 	}
 
 	if len(config) == 1 {
@@ -43,49 +114,400 @@ func New(config ...Config) func(http.Handler) http.Handler {
 		if config[0].Key != nil {
 			c.Key = config[0].Key
 		}
+
+		if storage, ok := c.Storage.(defaultTTLStorage); ok && storage.defaultTTL() > 0 {
+			c.DefaultTTL = storage.defaultTTL()
+		}
+
+		if config[0].DefaultTTL != 0 {
+			c.DefaultTTL = config[0].DefaultTTL
+		}
 	}
 
-	return func(h http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != http.MethodGet {
-				h.ServeHTTP(w, r)
-				return
-			}
+	return &Cache{
+		config:   c,
+		inflight: map[string]chan struct{}{},
+	}
+}
 
-			if r.Header.Get("Cache-Control") == "no-cache" {
-				h.ServeHTTP(w, r)
-				return
-			}
+// Middleware serves the responses from the cache, and stores the responses of the handler.
+func (m *Cache) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			m.invalidating([]string{r.URL.Path}, h).ServeHTTP(w, r)
+			return
+		}
 
-			key := c.Key(r)
+		requestCacheControl := parseCacheControl(r.Header.Values("Cache-Control"))
+		key := m.config.Key(r)
 
-			if val, ok := c.Storage.Get(key); ok {
-				respContentType, ok := c.Storage.Get(key + "_response-content-type")
-				if ok {
-					w.Header().Set("Content-Type", respContentType)
-				}
+		maxAge, ok := requestCacheControl.duration("max-age")
+		useStored := !requestCacheControl.has("no-cache") && (!ok || maxAge > 0)
+		if useStored && m.serveStored(w, r, h, key) {
+			return
+		}
 
-				w.WriteHeader(http.StatusOK)
-				w.Header().Set("Cache", "hit")
-				_, _ = w.Write([]byte(val))
-				return
+		if useStored && !requestCacheControl.has("no-store") && r.Method == http.MethodGet {
+			// Wait for the concurrent request generating the same response
+			wait, done := m.lock(m.variantKey(r.Context(), key, r))
+			if wait != nil {
+				select {
+				case <-wait:
+				case <-r.Context().Done():
+					return
+				}
+				if m.serveStored(w, r, h, key) {
+					return
+				}
+			} else {
+				defer done()
 			}
+		}
+
+		w.Header().Set(XCacheHeader, XCacheMiss)
+
+		if requestCacheControl.has("no-store") || r.Method != http.MethodGet {
+			h.ServeHTTP(&MultiHTTPWriter{ResponseWriter: w, cacheWriter: io.Discard}, r)
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		multiWriter := &MultiHTTPWriter{
+			ResponseWriter: w,
+			cacheWriter:    buf,
+		}
 
-			if r.Header.Get("Cache-Control") == "no-store" {
-				h.ServeHTTP(w, r)
-				return
+		h.ServeHTTP(multiWriter, r)
+
+		m.store(r.Context(), key, r, multiWriter.status, multiWriter.header, buf.Bytes())
+	})
+}
+
+// Purge removes the stored responses for the paths.
+// A path ending with /* also purges all the paths below it: /recipes/* purges /recipes/1 and /recipes/1/comments.
+//
+//	fuego.Post(s, "/recipes", func(c fuego.ContextWithBody[Recipe]) (Recipe, error) {
+//		...
+//		err := recipesCache.Purge(c.Context(), "/recipes", "/recipes/*")
+//	})
+func (m *Cache) Purge(ctx context.Context, paths ...string) error {
+	tags := make([]string, len(paths))
+	for i, path := range paths {
+		tags[i] = "path:" + path
+	}
+	return m.config.Storage.DeleteTags(ctx, tags...)
+}
+
+// PurgeTags removes the stored responses with any of the tags in their [CacheTagHeader] header.
+func (m *Cache) PurgeTags(ctx context.Context, tags ...string) error {
+	prefixed := make([]string, len(tags))
+	for i, tag := range tags {
+		prefixed[i] = "tag:" + tag
+	}
+	return m.config.Storage.DeleteTags(ctx, prefixed...)
+}
+
+// Invalidate is a middleware purging the paths (see [Cache.Purge]) after successful POST, PUT, PATCH and DELETE requests.
+//
+//	fuego.Post(s, "/recipes", createRecipe, option.Middleware(recipesCache.Invalidate("/recipes", "/recipes/*")))
+func (m *Cache) Invalidate(paths ...string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return m.invalidating(paths, h)
+	}
+}
+
+func (m *Cache) invalidating(paths []string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions || r.Method == http.MethodTrace {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+
+		// RFC 9111 §4.4: unsafe requests with a non-error response invalidate the target URI
+		if sw.status < http.StatusBadRequest {
+			if err := m.Purge(context.WithoutCancel(r.Context()), paths...); err != nil {
+				slog.Warn("cache: cannot purge responses", "paths", paths, "err", err)
 			}
+		}
+	})
+}
+
+// serveStored serves the stored response for the request if it is fresh, or stale but can be revalidated.
+func (m *Cache) serveStored(w http.ResponseWriter, r *http.Request, h http.Handler, key string) bool {
+	e, ok := m.lookup(r.Context(), key, r)
+	if !ok {
+		return false
+	}
+
+	age := e.age(time.Now())
+	switch {
+	case age <= e.TTL:
+		e.serve(w, r, age, XCacheHit)
+		return true
+	case age <= e.TTL+e.StaleWhileRevalidate:
+		e.serve(w, r, age, XCacheStale)
+		m.revalidate(h, r, key)
+		return true
+	}
+	return false
+}
+
+// lock returns a channel to wait for if the response for the key is being generated,
+// or a function to call when done generating it.
+func (m *Cache) lock(key string) (wait <-chan struct{}, done func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ch, ok := m.inflight[key]; ok {
+		return ch, nil
+	}
+
+	ch := make(chan struct{})
+	m.inflight[key] = ch
+	return nil, func() {
+		m.mu.Lock()
+		delete(m.inflight, key)
+		m.mu.Unlock()
+		close(ch)
+	}
+}
+
+// entry is a stored response.
+type entry struct {
+	Status               int           `json:"status"`
+	Header               http.Header   `json:"header"`
+	Body                 []byte        `json:"body"`
+	StoredAt             time.Time     `json:"storedAt"`
+	TTL                  time.Duration `json:"ttl"`
+	StaleWhileRevalidate time.Duration `json:"staleWhileRevalidate"`
+}
+
+// age is the time since the response was generated (RFC 9111 §4.2.3).
+func (e entry) age(now time.Time) time.Duration {
+	age := now.Sub(e.StoredAt)
+	if initialAge, err := strconv.Atoi(e.Header.Get("Age")); err == nil && initialAge > 0 {
+		age += time.Duration(initialAge) * time.Second
+	}
+	return age
+}
+
+func (e entry) serve(w http.ResponseWriter, r *http.Request, age time.Duration, xCache string) {
+	header := w.Header()
+	for name, values := range e.Header {
+		header[name] = slices.Clone(values)
+	}
+	header.Set("Age", strconv.Itoa(int(age.Seconds())))
+	header.Set(XCacheHeader, xCache)
+
+	w.WriteHeader(e.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(e.Body)
+	}
+}
+
+// cacheableStatuses are the status codes that are cacheable by default (RFC 9110 §15.1).
+var cacheableStatuses = []int{
+	http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+	http.StatusMovedPermanently, http.StatusPermanentRedirect, http.StatusNotFound, http.StatusMethodNotAllowed,
+	http.StatusGone, http.StatusRequestURITooLong, http.StatusNotImplemented,
+}
+
+// unstoredHeaders are the hop-by-hop headers, the headers set by the cache itself,
+// and the headers describing the request rather than the response, which must not be replayed to other requests.
+var unstoredHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Connection", "Transfer-Encoding", "Upgrade", "Te", "Trailer", XCacheHeader, CacheTagHeader,
+	"Date", "Server-Timing", "X-Request-ID",
+}
+
+// newEntry returns the entry to store for a response, if it can be stored.
+func (m *Cache) newEntry(r *http.Request, status int, header http.Header, body []byte) (entry, bool) {
+	if !slices.Contains(cacheableStatuses, status) || header.Get("Set-Cookie") != "" {
+		return entry{}, false
+	}
+
+	cacheControl := parseCacheControl(header.Values("Cache-Control"))
+	if cacheControl.has("no-store") || cacheControl.has("private") || cacheControl.has("no-cache") {
+		return entry{}, false
+	}
+	// A shared cache must not reuse an authenticated response, unless explicitly allowed (RFC 9111 §3.5)
+	if r.Header.Get("Authorization") != "" && !cacheControl.has("public") && !cacheControl.has("s-maxage") && !cacheControl.has("must-revalidate") {
+		return entry{}, false
+	}
+
+	now := time.Now()
+	ttl, ok := cacheControl.duration("s-maxage")
+	if !ok {
+		ttl, ok = cacheControl.duration("max-age")
+	}
+	if !ok {
+		if expires, err := http.ParseTime(header.Get("Expires")); err == nil {
+			ttl, ok = expires.Sub(now), true
+		} else if header.Get("Expires") != "" {
+			ttl, ok = 0, true // invalid dates mean already expired
+		}
+	}
+	if !ok {
+		ttl = m.config.DefaultTTL
+	}
 
-			buf := &bytes.Buffer{}
-			multiWriter := &MultiHTTPWriter{
-				ResponseWriter: w,
-				cacheWriter:    buf,
+	staleWhileRevalidate, _ := cacheControl.duration("stale-while-revalidate")
+	if cacheControl.has("must-revalidate") || cacheControl.has("proxy-revalidate") {
+		staleWhileRevalidate = 0
+	}
+	if ttl <= 0 && staleWhileRevalidate <= 0 {
+		return entry{}, false
+	}
+
+	storedHeader := header.Clone()
+	for _, trailer := range header.Values("Trailer") {
+		for name := range strings.SplitSeq(trailer, ",") {
+			storedHeader.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range unstoredHeaders {
+		storedHeader.Del(name)
+	}
+
+	return entry{
+		Status:               status,
+		Header:               storedHeader,
+		Body:                 bytes.Clone(body),
+		StoredAt:             now,
+		TTL:                  ttl,
+		StaleWhileRevalidate: staleWhileRevalidate,
+	}, true
+}
+
+// store stores the response under the key and the values of the request headers listed in its Vary header.
+func (m *Cache) store(ctx context.Context, key string, r *http.Request, status int, header http.Header, body []byte) {
+	if header == nil {
+		return
+	}
+	vary := varyHeaders(header)
+	if slices.Contains(vary, "*") {
+		return
+	}
+	e, ok := m.newEntry(r, status, header, body)
+	if !ok {
+		return
+	}
+
+	value, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	tags := pathTags(r.URL.Path)
+	for _, tagHeader := range header.Values(CacheTagHeader) {
+		for tag := range strings.SplitSeq(tagHeader, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, "tag:"+tag)
 			}
+		}
+	}
+
+	lifetime := e.TTL + e.StaleWhileRevalidate
+	err = errors.Join(
+		m.config.Storage.Set(ctx, key+"_vary", []byte(strings.Join(vary, ",")), lifetime, tags...),
+		m.config.Storage.Set(ctx, variantKey(key, vary, r), value, lifetime, tags...),
+	)
+	if err != nil {
+		slog.Warn("cache: cannot store response", "key", key, "err", err)
+	}
+}
+
+// variantKey returns the key of the stored response for the request, if the Vary header of the response is known.
+func (m *Cache) variantKey(ctx context.Context, key string, r *http.Request) string {
+	vary, err := m.config.Storage.Get(ctx, key+"_vary")
+	if err != nil || len(vary) == 0 {
+		return key
+	}
+	return variantKey(key, strings.Split(string(vary), ","), r)
+}
 
-			h.ServeHTTP(multiWriter, r)
+// lookup returns the stored response matching the request.
+func (m *Cache) lookup(ctx context.Context, key string, r *http.Request) (entry, bool) {
+	vary, err := m.config.Storage.Get(ctx, key+"_vary")
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.Warn("cache: cannot get response", "key", key, "err", err)
+		}
+		return entry{}, false
+	}
+
+	var names []string
+	if len(vary) > 0 {
+		names = strings.Split(string(vary), ",")
+	}
+	value, err := m.config.Storage.Get(ctx, variantKey(key, names, r))
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.Warn("cache: cannot get response", "key", key, "err", err)
+		}
+		return entry{}, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(value, &e); err != nil {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// revalidate refreshes a stale entry in the background, once at a time per key.
+func (m *Cache) revalidate(h http.Handler, r *http.Request, key string) {
+	if _, running := m.revalidating.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	req := r.Clone(context.WithoutCancel(r.Context()))
+	req.Method = http.MethodGet
+	req.Header.Del("Cache-Control")
+
+	go func() {
+		defer m.revalidating.Delete(key)
+
+		rec := newRecorder()
+		h.ServeHTTP(rec, req)
+		m.store(req.Context(), key, req, rec.status, rec.Header(), rec.body.Bytes())
+	}()
+}
+
+// pathTags returns the tags purging the response for the path with [Cache.Purge]:
+// the path itself and the /* patterns of its parents.
+func pathTags(path string) []string {
+	tags := []string{"path:" + path}
+	for i, char := range path {
+		if char == '/' {
+			tags = append(tags, "path:"+path[:i+1]+"*")
+		}
+	}
+	return tags
+}
+
+// varyHeaders returns the canonical names of the headers listed in the Vary header, sorted.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for name := range strings.SplitSeq(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
 
-			c.Storage.Set(key, multiWriter.cacheWriter.(*bytes.Buffer).String())
-			c.Storage.Set(key+"_response-content-type", multiWriter.Header().Get("Content-Type"))
-		})
+func variantKey(key string, vary []string, r *http.Request) string {
+	var sb strings.Builder
+	sb.WriteString(key)
+	for _, name := range vary {
+		sb.WriteString("_" + name + "=" + strings.Join(r.Header.Values(name), ","))
 	}
+	return sb.String()
 }
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	cc := parseCacheControl([]string{`Public, max-age=60`, `stale-while-revalidate="30", s-maxage=-1, ,no-cache`})
+
+	require.True(t, cc.has("public"))
+	require.True(t, cc.has("no-cache"))
+	require.False(t, cc.has("private"))
+
+	maxAge, ok := cc.duration("max-age")
+	require.True(t, ok)
+	require.Equal(t, time.Minute, maxAge)
+
+	swr, ok := cc.duration("stale-while-revalidate")
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, swr)
+
+	_, ok = cc.duration("s-maxage")
+	require.False(t, ok, "negative values are invalid")
+
+	_, ok = cc.duration("no-cache")
+	require.False(t, ok)
+}
@@ -1,8 +1,12 @@
 package cache
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -201,6 +205,365 @@ func TestCache(t *testing.T) {
 	})
 }
 
+func TestHTTPCaching(t *testing.T) {
+	// handler counts its calls and answers with the given headers
+	handler := func(calls *atomic.Int32, header http.Header) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := calls.Add(1)
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("response " + strconv.Itoa(int(n)) + " for " + r.URL.RequestURI() + " " + r.Header.Get("Accept-Language")))
+		})
+	}
+
+	do := func(h http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		for name, values := range header {
+			r.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("stores status, headers and body", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("X-Custom", "value")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not here"))
+		}))
+
+		w := do(h, http.MethodGet, "/", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, XCacheMiss, w.Header().Get(XCacheHeader))
+		require.Empty(t, w.Header().Get("Age"))
+
+		w = do(h, http.MethodGet, "/", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "value", w.Header().Get("X-Custom"))
+		require.Equal(t, "not here", w.Body.String())
+		require.Equal(t, XCacheHit, w.Header().Get(XCacheHeader))
+		require.Equal(t, "0", w.Header().Get("Age"))
+		require.EqualValues(t, 1, calls.Load())
+
+		w = do(h, http.MethodHead, "/", nil)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, XCacheHit, w.Header().Get(XCacheHeader))
+		require.Empty(t, w.Body.String())
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("request-scoped headers are not replayed", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Server-Timing", "db;dur=12")
+			_, _ = w.Write([]byte("response"))
+		}))
+		// Sets the request ID of each request, like the logging middleware of Fuego
+		withRequestID := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-ID", r.Header.Get("X-Request-ID"))
+			h.ServeHTTP(w, r)
+		})
+
+		w := do(withRequestID, http.MethodGet, "/request-id", http.Header{"X-Request-Id": {"first"}})
+		require.Equal(t, XCacheMiss, w.Header().Get(XCacheHeader))
+		require.Equal(t, "first", w.Header().Get("X-Request-ID"))
+
+		w = do(withRequestID, http.MethodGet, "/request-id", http.Header{"X-Request-Id": {"second"}})
+		require.Equal(t, XCacheHit, w.Header().Get(XCacheHeader))
+		require.Equal(t, "second", w.Header().Get("X-Request-ID"))
+		require.Empty(t, w.Header().Get("Server-Timing"))
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("keys on the query string", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(handler(&calls, nil))
+
+		require.Equal(t, "response 1 for /?page=1 ", do(h, http.MethodGet, "/?page=1", nil).Body.String())
+		require.Equal(t, "response 2 for /?page=2 ", do(h, http.MethodGet, "/?page=2", nil).Body.String())
+		require.Equal(t, "response 1 for /?page=1 ", do(h, http.MethodGet, "/?page=1", nil).Body.String())
+	})
+
+	t.Run("keys on the Vary headers", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(handler(&calls, http.Header{"Vary": {"Accept-Language"}}))
+
+		fr := http.Header{"Accept-Language": {"fr"}}
+		en := http.Header{"Accept-Language": {"en"}}
+		require.Equal(t, "response 1 for / fr", do(h, http.MethodGet, "/", fr).Body.String())
+		require.Equal(t, "response 2 for / en", do(h, http.MethodGet, "/", en).Body.String())
+		require.Equal(t, "response 1 for / fr", do(h, http.MethodGet, "/", fr).Body.String())
+		require.Equal(t, "response 2 for / en", do(h, http.MethodGet, "/", en).Body.String())
+	})
+
+	t.Run("does not store Vary: *", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(handler(&calls, http.Header{"Vary": {"*"}}))
+
+		do(h, http.MethodGet, "/", nil)
+		require.Equal(t, XCacheMiss, do(h, http.MethodGet, "/", nil).Header().Get(XCacheHeader))
+	})
+
+	t.Run("respects the response Cache-Control", func(t *testing.T) {
+		for _, cacheControl := range []string{"no-store", "private, max-age=60", "no-cache", "max-age=0"} {
+			t.Run(cacheControl, func(t *testing.T) {
+				var calls atomic.Int32
+				h := New()(handler(&calls, http.Header{"Cache-Control": {cacheControl}}))
+
+				do(h, http.MethodGet, "/", nil)
+				do(h, http.MethodGet, "/", nil)
+				require.EqualValues(t, 2, calls.Load())
+			})
+		}
+	})
+
+	t.Run("does not store responses setting cookies", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(handler(&calls, http.Header{"Set-Cookie": {"session=1"}}))
+
+		do(h, http.MethodGet, "/", nil)
+		do(h, http.MethodGet, "/", nil)
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("authenticated requests are only stored when public", func(t *testing.T) {
+		auth := http.Header{"Authorization": {"Bearer token"}}
+
+		var calls atomic.Int32
+		h := New()(handler(&calls, nil))
+		do(h, http.MethodGet, "/", auth)
+		do(h, http.MethodGet, "/", auth)
+		require.EqualValues(t, 2, calls.Load())
+
+		calls.Store(0)
+		h = New()(handler(&calls, http.Header{"Cache-Control": {"public, max-age=60"}}))
+		do(h, http.MethodGet, "/", auth)
+		do(h, http.MethodGet, "/", auth)
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("max-age overrides the default TTL", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New(Config{DefaultTTL: time.Hour})(handler(&calls, http.Header{"Cache-Control": {"max-age=1"}}))
+
+		do(h, http.MethodGet, "/", nil)
+		require.Equal(t, XCacheHit, do(h, http.MethodGet, "/", nil).Header().Get(XCacheHeader))
+
+		time.Sleep(1100 * time.Millisecond)
+		require.Equal(t, XCacheMiss, do(h, http.MethodGet, "/", nil).Header().Get(XCacheHeader))
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("request max-age=0 bypasses the cache", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(handler(&calls, nil))
+
+		do(h, http.MethodGet, "/", nil)
+		w := do(h, http.MethodGet, "/", http.Header{"Cache-Control": {"max-age=0"}})
+		require.Equal(t, XCacheMiss, w.Header().Get(XCacheHeader))
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("stale-while-revalidate", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(handler(&calls, http.Header{"Cache-Control": {"max-age=1, stale-while-revalidate=10"}}))
+
+		require.Equal(t, "response 1 for / ", do(h, http.MethodGet, "/", nil).Body.String())
+		time.Sleep(1100 * time.Millisecond)
+
+		w := do(h, http.MethodGet, "/", nil)
+		require.Equal(t, XCacheStale, w.Header().Get(XCacheHeader))
+		require.Equal(t, "1", w.Header().Get("Age"))
+		require.Equal(t, "response 1 for / ", w.Body.String())
+
+		require.Eventually(t, func() bool {
+			w := do(h, http.MethodGet, "/", nil)
+			return w.Header().Get(XCacheHeader) == XCacheHit && w.Body.String() == "response 2 for / "
+		}, time.Second, 10*time.Millisecond)
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("does not store hop-by-hop and trailer headers", func(t *testing.T) {
+		var calls atomic.Int32
+		h := New()(handler(&calls, http.Header{"Connection": {"close"}, "Trailer": {"Server-Timing"}, "Server-Timing": {"total;dur=1"}}))
+
+		do(h, http.MethodGet, "/", nil)
+		w := do(h, http.MethodGet, "/", nil)
+		require.Equal(t, XCacheHit, w.Header().Get(XCacheHeader))
+		require.Empty(t, w.Header().Get("Connection"))
+		require.Empty(t, w.Header().Get("Trailer"))
+		require.Empty(t, w.Header().Get("Server-Timing"))
+	})
+}
+
+func TestRequestCoalescing(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	h := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte("response"))
+	}))
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 10)
+	for i := range responses {
+		responses[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			h.ServeHTTP(responses[i], httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	}
+
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond) // lets the other requests wait
+	close(release)
+	wg.Wait()
+
+	require.EqualValues(t, 1, calls.Load())
+	hits := 0
+	for _, w := range responses {
+		require.Equal(t, "response", w.Body.String())
+		if w.Header().Get(XCacheHeader) == XCacheHit {
+			hits++
+		}
+	}
+	require.Equal(t, len(responses)-1, hits)
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	var calls atomic.Int32
+	c := NewCache()
+
+	s := fuego.NewServer()
+	counter := func(c fuego.ContextNoBody) (string, error) {
+		if tag := c.QueryParam("tag"); tag != "" {
+			c.SetHeader(CacheTagHeader, tag)
+		}
+		return c.Request().URL.Path + " " + strconv.Itoa(int(calls.Add(1))), nil
+	}
+	fuego.Get(s, "/recipes", counter, option.Middleware(c.Middleware))
+	fuego.Get(s, "/recipes/{id}", counter, option.Middleware(c.Middleware))
+	fuego.Get(s, "/ingredients", counter, option.Middleware(c.Middleware))
+	fuego.Put(s, "/recipes/{id}", counter, option.Middleware(c.Middleware))
+	fuego.Post(s, "/recipes", counter, option.Middleware(c.Invalidate("/recipes", "/recipes/*")))
+	fuego.Delete(s, "/recipes/{id}", func(c fuego.ContextNoBody) (any, error) {
+		return nil, fuego.ForbiddenError{}
+	}, option.Middleware(c.Middleware))
+
+	do := func(method, target string) string {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Accept", "text/plain")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+	warm := func() {
+		calls.Store(0)
+		require.NoError(t, c.config.Storage.DeleteTags(context.Background(), "path:/*"))
+		require.Equal(t, "/recipes 1", do(http.MethodGet, "/recipes"))
+		require.Equal(t, "/recipes/1 2", do(http.MethodGet, "/recipes/1"))
+		require.Equal(t, "/recipes/2 3", do(http.MethodGet, "/recipes/2?tag=second"))
+		require.Equal(t, "/ingredients 4", do(http.MethodGet, "/ingredients?tag=second"))
+	}
+
+	t.Run("purge paths", func(t *testing.T) {
+		warm()
+		require.NoError(t, c.Purge(context.Background(), "/recipes/1"))
+
+		require.Equal(t, "/recipes 1", do(http.MethodGet, "/recipes"))
+		require.Equal(t, "/recipes/1 5", do(http.MethodGet, "/recipes/1"))
+		require.Equal(t, "/recipes/2 3", do(http.MethodGet, "/recipes/2?tag=second"))
+	})
+
+	t.Run("purge path patterns", func(t *testing.T) {
+		warm()
+		require.NoError(t, c.Purge(context.Background(), "/recipes/*"))
+
+		require.Equal(t, "/recipes 1", do(http.MethodGet, "/recipes"))
+		require.Equal(t, "/recipes/1 5", do(http.MethodGet, "/recipes/1"))
+		require.Equal(t, "/recipes/2 6", do(http.MethodGet, "/recipes/2?tag=second"))
+		require.Equal(t, "/ingredients 4", do(http.MethodGet, "/ingredients?tag=second"))
+	})
+
+	t.Run("purge tags", func(t *testing.T) {
+		warm()
+		require.NoError(t, c.PurgeTags(context.Background(), "second"))
+
+		require.Equal(t, "/recipes/1 2", do(http.MethodGet, "/recipes/1"))
+		require.Equal(t, "/recipes/2 5", do(http.MethodGet, "/recipes/2?tag=second"))
+		require.Equal(t, "/ingredients 6", do(http.MethodGet, "/ingredients?tag=second"))
+	})
+
+	t.Run("Invalidate middleware", func(t *testing.T) {
+		warm()
+		require.Equal(t, "/recipes 5", do(http.MethodPost, "/recipes"))
+
+		require.Equal(t, "/recipes 6", do(http.MethodGet, "/recipes"))
+		require.Equal(t, "/recipes/1 7", do(http.MethodGet, "/recipes/1"))
+		require.Equal(t, "/ingredients 4", do(http.MethodGet, "/ingredients?tag=second"))
+	})
+
+	t.Run("successful unsafe requests purge their path", func(t *testing.T) {
+		warm()
+		require.Equal(t, "/recipes/1 5", do(http.MethodPut, "/recipes/1"))
+
+		require.Equal(t, "/recipes/1 6", do(http.MethodGet, "/recipes/1"))
+		require.Equal(t, "/recipes/2 3", do(http.MethodGet, "/recipes/2?tag=second"))
+	})
+
+	t.Run("failed unsafe requests do not purge", func(t *testing.T) {
+		warm()
+		do(http.MethodDelete, "/recipes/1")
+
+		require.Equal(t, "/recipes/1 2", do(http.MethodGet, "/recipes/1"))
+	})
+
+	t.Run("the Cache-Tag header is not sent", func(t *testing.T) {
+		warm()
+		r := httptest.NewRequest(http.MethodGet, "/recipes/2?tag=second", nil)
+		r.Header.Set("Accept", "text/plain")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, XCacheHit, w.Header().Get(XCacheHeader))
+		require.Empty(t, w.Header().Get(CacheTagHeader))
+	})
+
+	t.Run("the Cache-Tag header is not sent on a miss", func(t *testing.T) {
+		require.NoError(t, c.config.Storage.DeleteTags(context.Background(), "path:/*"))
+		get := func() *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, "/recipes/2?tag=second", nil)
+			r.Header.Set("Accept", "text/plain")
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+			return w
+		}
+
+		w := get()
+		require.Equal(t, XCacheMiss, w.Header().Get(XCacheHeader))
+		require.Empty(t, w.Header().Get(CacheTagHeader))
+
+		// The response is still stored with its tag
+		require.Equal(t, XCacheHit, get().Header().Get(XCacheHeader))
+		require.NoError(t, c.PurgeTags(context.Background(), "second"))
+		require.Equal(t, XCacheMiss, get().Header().Get(XCacheHeader))
+	})
+
+	t.Run("the Cache-Tag header is not sent without storing", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/recipes/2?tag=second", nil)
+		r.Header.Set("Accept", "text/plain")
+		r.Header.Set("Cache-Control", "no-store")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Empty(t, w.Header().Get(CacheTagHeader))
+	})
+}
+
 func BenchmarkCache(b *testing.B) {
 	s := fuego.NewServer()
 
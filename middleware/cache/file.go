@@ -0,0 +1,256 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileCache is a [Storage] keeping the values in files, for single-node deployments.
+//
+// Values are stored in the entries/ subdirectory of the cache directory,
+// and the tag index in the tags/ subdirectory.
+// Expired values are removed when read, and by a sweep of the directory at most once per [fileSweepInterval].
+type FileCache struct {
+	mu        sync.Mutex
+	dir       string
+	lastSweep time.Time
+}
+
+// fileSweepInterval is the minimum time between two removals of the expired values of a [FileCache].
+const fileSweepInterval = time.Minute
+
+// fileHeader is the first line of an entry file, before the value.
+type fileHeader struct {
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	Tags      []string  `json:"tags,omitempty"`
+}
+
+var _ Storage = (*FileCache)(nil)
+
+// NewFileCache creates a storage in the directory, created if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	for _, sub := range []string{"entries", "tags"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			return nil, fmt.Errorf("cannot create cache directory: %w", err)
+		}
+	}
+
+	return &FileCache{dir: dir, lastSweep: time.Now()}, nil
+}
+
+func (f *FileCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	header, value, err := f.read(hash(key))
+	if err != nil {
+		return nil, err
+	}
+	if !header.ExpiresAt.IsZero() && time.Now().After(header.ExpiresAt) {
+		return nil, errors.Join(ErrNotFound, f.remove(hash(key), header))
+	}
+
+	return value, nil
+}
+
+func (f *FileCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	header := fileHeader{Tags: tags}
+	if ttl > 0 {
+		header.ExpiresAt = time.Now().Add(ttl)
+	}
+	line, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sweep(time.Now())
+
+	keyHash := hash(key)
+	if previous, _, err := f.read(keyHash); err == nil {
+		f.untag(keyHash, previous.Tags)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(f.dir, "entries"), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(append(line, '\n'), value...))
+	if err = errors.Join(err, tmp.Close()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.entryPath(keyHash)); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		tagDir := filepath.Join(f.dir, "tags", hash(tag))
+		if err := os.MkdirAll(tagDir, 0o750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(tagDir, keyHash), nil, 0o600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *FileCache) Delete(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for _, key := range keys {
+		header, _, err := f.read(hash(key))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		errs = append(errs, f.remove(hash(key), header))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *FileCache) DeleteTags(ctx context.Context, tags ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for _, tag := range tags {
+		tagDir := filepath.Join(f.dir, "tags", hash(tag))
+		markers, err := os.ReadDir(tagDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		errs = append(errs, err)
+		for _, marker := range markers {
+			header, _, err := f.read(marker.Name())
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			errs = append(errs, f.remove(marker.Name(), header))
+		}
+		errs = append(errs, os.RemoveAll(tagDir))
+	}
+
+	return errors.Join(errs...)
+}
+
+// sweep removes the expired values, at most once per [fileSweepInterval]. Called with the lock held.
+func (f *FileCache) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < fileSweepInterval {
+		return
+	}
+	f.lastSweep = now
+
+	files, err := os.ReadDir(filepath.Join(f.dir, "entries"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), ".tmp-") {
+			continue
+		}
+		header, err := f.readHeader(file.Name())
+		if err == nil && !header.ExpiresAt.IsZero() && now.After(header.ExpiresAt) {
+			_ = f.remove(file.Name(), header)
+		}
+	}
+}
+
+// The files of the entries and of the tag index are named after the hash of the key.
+
+func (f *FileCache) entryPath(keyHash string) string {
+	return filepath.Join(f.dir, "entries", keyHash)
+}
+
+func (f *FileCache) read(keyHash string) (fileHeader, []byte, error) {
+	content, err := os.ReadFile(f.entryPath(keyHash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fileHeader{}, nil, ErrNotFound
+	} else if err != nil {
+		return fileHeader{}, nil, err
+	}
+
+	line, value, _ := bytes.Cut(content, []byte("\n"))
+	var header fileHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return fileHeader{}, nil, fmt.Errorf("corrupted cache entry: %w", err)
+	}
+
+	return header, value, nil
+}
+
+// readHeader reads the header of an entry, without its value.
+func (f *FileCache) readHeader(keyHash string) (fileHeader, error) {
+	file, err := os.Open(f.entryPath(keyHash))
+	if err != nil {
+		return fileHeader{}, err
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fileHeader{}, err
+	}
+	var header fileHeader
+	if err := json.Unmarshal(bytes.TrimSuffix(line, []byte("\n")), &header); err != nil {
+		return fileHeader{}, fmt.Errorf("corrupted cache entry: %w", err)
+	}
+
+	return header, nil
+}
+
+func (f *FileCache) remove(keyHash string, header fileHeader) error {
+	f.untag(keyHash, header.Tags)
+	err := os.Remove(f.entryPath(keyHash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (f *FileCache) untag(keyHash string, tags []string) {
+	for _, tag := range tags {
+		_ = os.Remove(filepath.Join(f.dir, "tags", hash(tag), keyHash))
+	}
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := NewFileCache(dir)
+	require.NoError(t, err)
+
+	testStorage(t, storage)
+
+	t.Run("values survive a new store on the same directory", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, storage.Set(ctx, "persistent", []byte("value"), 0, "tag"))
+
+		reopened, err := NewFileCache(dir)
+		require.NoError(t, err)
+		value, err := reopened.Get(ctx, "persistent")
+		require.NoError(t, err)
+		require.Equal(t, "value", string(value))
+
+		require.NoError(t, reopened.DeleteTags(ctx, "tag"))
+		_, err = storage.Get(ctx, "persistent")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("corrupted entries are errors", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "entries", hash("corrupted")), []byte("not json\nvalue"), 0o600))
+
+		_, err := storage.Get(context.Background(), "corrupted")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("expired values are swept", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, storage.Set(ctx, "expired", []byte("value"), time.Millisecond, "sweep"))
+		require.NoError(t, storage.Set(ctx, "kept", []byte("value"), time.Hour, "sweep"))
+		time.Sleep(2 * time.Millisecond)
+
+		storage.lastSweep = time.Now().Add(-fileSweepInterval)
+		require.NoError(t, storage.Set(ctx, "other", []byte("value"), 0))
+
+		_, err := os.Stat(filepath.Join(dir, "entries", hash("expired")))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		_, err = os.Stat(filepath.Join(dir, "tags", hash("sweep"), hash("expired")))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+		value, err := storage.Get(ctx, "kept")
+		require.NoError(t, err)
+		require.Equal(t, "value", string(value))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := storage.Get(ctx, "key")
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, storage.Set(ctx, "key", nil, 0), context.Canceled)
+	})
+}
@@ -1,27 +1,123 @@
 package cache
 
 import (
+	"context"
+	"sync"
 	"time"
 
-	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
 )
 
+// TTLCache is an in-memory [Storage] evicting the least recently used values.
 type TTLCache struct {
-	cache *expirable.LRU[string, string]
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries *simplelru.LRU[string, memoryEntry]
+	tags    map[string]map[string]struct{} // keys by tag
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // zero if the value never expires
+	tags      []string
 }
 
 var _ Storage = (*TTLCache)(nil)
 
-func NewInMemoryCache(duration time.Duration, maxObjects int) *TTLCache {
-	return &TTLCache{
-		cache: expirable.NewLRU[string, string](maxObjects, nil, duration),
+// NewInMemoryCache creates an in-memory storage of maxObjects values at most.
+// Values stored without TTL are kept for ttl (forever if 0).
+// Used by [NewCache], ttl is also how long the responses without Cache-Control max-age are fresh,
+// unless [Config.DefaultTTL] is set.
+func NewInMemoryCache(ttl time.Duration, maxObjects int) *TTLCache {
+	t := &TTLCache{
+		ttl:  ttl,
+		tags: map[string]map[string]struct{}{},
+	}
+
+	entries, err := simplelru.NewLRU(maxObjects, t.untag)
+	if err != nil {
+		panic(err)
 	}
+	t.entries = entries
+
+	return t
 }
 
-func (t *TTLCache) Get(key string) (string, bool) {
-	return t.cache.Get(key)
+func (t *TTLCache) Get(_ context.Context, key string) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries.Get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		t.entries.Remove(key)
+		return nil, ErrNotFound
+	}
+
+	return e.value, nil
 }
 
-func (t *TTLCache) Set(key, value string) {
-	t.cache.Add(key, value)
+func (t *TTLCache) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	if ttl <= 0 {
+		ttl = t.ttl
+	}
+
+	e := memoryEntry{value: value, tags: tags}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries.Remove(key) // untags the previous value
+	t.entries.Add(key, e)
+	for _, tag := range tags {
+		if t.tags[tag] == nil {
+			t.tags[tag] = map[string]struct{}{}
+		}
+		t.tags[tag][key] = struct{}{}
+	}
+
+	return nil
+}
+
+func (t *TTLCache) Delete(_ context.Context, keys ...string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, key := range keys {
+		t.entries.Remove(key)
+	}
+
+	return nil
+}
+
+func (t *TTLCache) DeleteTags(_ context.Context, tags ...string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range t.tags[tag] {
+			t.entries.Remove(key)
+		}
+	}
+
+	return nil
+}
+
+func (t *TTLCache) defaultTTL() time.Duration {
+	return t.ttl
+}
+
+// untag is called with the lock held when an entry is removed or evicted.
+func (t *TTLCache) untag(key string, e memoryEntry) {
+	for _, tag := range e.tags {
+		delete(t.tags[tag], key)
+		if len(t.tags[tag]) == 0 {
+			delete(t.tags, tag)
+		}
+	}
 }
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testStorage checks the behavior shared by all the Storage implementations.
+func testStorage(t *testing.T, storage Storage) {
+	ctx := context.Background()
+
+	t.Run("get and set", func(t *testing.T) {
+		_, err := storage.Get(ctx, "missing")
+		require.ErrorIs(t, err, ErrNotFound)
+
+		require.NoError(t, storage.Set(ctx, "key", []byte("value"), 0))
+		value, err := storage.Get(ctx, "key")
+		require.NoError(t, err)
+		require.Equal(t, "value", string(value))
+
+		require.NoError(t, storage.Set(ctx, "key", []byte("other value"), 0))
+		value, err = storage.Get(ctx, "key")
+		require.NoError(t, err)
+		require.Equal(t, "other value", string(value))
+	})
+
+	t.Run("expires after the TTL", func(t *testing.T) {
+		require.NoError(t, storage.Set(ctx, "short", []byte("value"), 20*time.Millisecond))
+		_, err := storage.Get(ctx, "short")
+		require.NoError(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+		_, err = storage.Get(ctx, "short")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, storage.Set(ctx, "a", []byte("a"), 0))
+		require.NoError(t, storage.Set(ctx, "b", []byte("b"), 0))
+
+		require.NoError(t, storage.Delete(ctx, "a", "b", "missing"))
+		_, err := storage.Get(ctx, "a")
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = storage.Get(ctx, "b")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("delete tags", func(t *testing.T) {
+		require.NoError(t, storage.Set(ctx, "recipe", []byte("recipe"), 0, "recipes", "food"))
+		require.NoError(t, storage.Set(ctx, "ingredient", []byte("ingredient"), 0, "ingredients", "food"))
+		require.NoError(t, storage.Set(ctx, "user", []byte("user"), 0, "users"))
+
+		require.NoError(t, storage.DeleteTags(ctx, "recipes", "missing"))
+		_, err := storage.Get(ctx, "recipe")
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = storage.Get(ctx, "ingredient")
+		require.NoError(t, err)
+
+		require.NoError(t, storage.DeleteTags(ctx, "food"))
+		_, err = storage.Get(ctx, "ingredient")
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = storage.Get(ctx, "user")
+		require.NoError(t, err)
+	})
+
+	t.Run("overwriting a value replaces its tags", func(t *testing.T) {
+		require.NoError(t, storage.Set(ctx, "retagged", []byte("v1"), 0, "old"))
+		require.NoError(t, storage.Set(ctx, "retagged", []byte("v2"), 0, "new"))
+
+		require.NoError(t, storage.DeleteTags(ctx, "old"))
+		value, err := storage.Get(ctx, "retagged")
+		require.NoError(t, err)
+		require.Equal(t, "v2", string(value))
+	})
+}
+
+func TestInMemoryCache(t *testing.T) {
+	testStorage(t, NewInMemoryCache(0, 100))
+
+	t.Run("evicts the least recently used values", func(t *testing.T) {
+		ctx := context.Background()
+		storage := NewInMemoryCache(0, 2)
+
+		require.NoError(t, storage.Set(ctx, "a", []byte("a"), 0, "tag"))
+		require.NoError(t, storage.Set(ctx, "b", []byte("b"), 0, "tag"))
+		_, err := storage.Get(ctx, "a")
+		require.NoError(t, err)
+		require.NoError(t, storage.Set(ctx, "c", []byte("c"), 0))
+
+		_, err = storage.Get(ctx, "b")
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Len(t, storage.tags["tag"], 1, "evicted values are untagged")
+	})
+
+	t.Run("values stored without TTL expire after the TTL of the cache", func(t *testing.T) {
+		ctx := context.Background()
+		storage := NewInMemoryCache(20*time.Millisecond, 10)
+
+		require.NoError(t, storage.Set(ctx, "default", []byte("value"), 0))
+		require.NoError(t, storage.Set(ctx, "longer", []byte("value"), time.Hour))
+		time.Sleep(30 * time.Millisecond)
+		_, err := storage.Get(ctx, "default")
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = storage.Get(ctx, "longer")
+		require.NoError(t, err)
+	})
+
+	t.Run("sets the default TTL of the cache", func(t *testing.T) {
+		require.Equal(t, time.Hour, NewCache(Config{Storage: NewInMemoryCache(time.Hour, 10)}).config.DefaultTTL)
+		require.Equal(t, time.Minute, NewCache(Config{Storage: NewInMemoryCache(time.Hour, 10), DefaultTTL: time.Minute}).config.DefaultTTL)
+		require.Equal(t, 3*time.Second, NewCache(Config{Storage: NewInMemoryCache(0, 10)}).config.DefaultTTL)
+	})
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"bytes"
 	"io"
 	"net/http"
 )
@@ -8,13 +9,17 @@ import (
 // MultiHTTPWriter is a http.ResponseWriter that writes the response to multiple writers
 type MultiHTTPWriter struct {
 	http.ResponseWriter
-	status      int       // status is the status code that will be written to the response
-	cacheWriter io.Writer // cacheWriter is the writer that will be used to cache the response
+	status      int         // status is the status code that will be written to the response
+	header      http.Header // header is a copy of the headers when the status code was written
+	cacheWriter io.Writer   // cacheWriter is the writer that will be used to cache the response
 }
 
 var _ http.ResponseWriter = &MultiHTTPWriter{}
 
 func (m *MultiHTTPWriter) Write(p []byte) (int, error) {
+	if m.status == 0 {
+		m.WriteHeader(http.StatusOK)
+	}
 	multiWriter := io.MultiWriter(m.ResponseWriter, m.cacheWriter)
 	return multiWriter.Write(p)
 }
@@ -23,7 +28,74 @@ func (m *MultiHTTPWriter) Unwrap() http.ResponseWriter {
 	return m.ResponseWriter
 }
 
+// WriteHeader keeps a copy of the headers for the cache, and removes the [CacheTagHeader] header from the response.
 func (m *MultiHTTPWriter) WriteHeader(statusCode int) {
-	m.status = statusCode
+	if m.status == 0 {
+		m.status = statusCode
+		m.header = m.ResponseWriter.Header().Clone()
+		m.ResponseWriter.Header().Del(CacheTagHeader)
+	}
 	m.ResponseWriter.WriteHeader(statusCode)
 }
+
+func (m *MultiHTTPWriter) Flush() {
+	if m.status == 0 {
+		m.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(m.ResponseWriter).Flush()
+}
+
+// recorder is a http.ResponseWriter keeping the response in memory,
+// used to revalidate stale responses in the background.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+var _ http.ResponseWriter = &recorder{}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.body.Write(p)
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+}
+
+// statusWriter records the status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusWriter) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+func (s *statusWriter) WriteHeader(statusCode int) {
+	if s.status == 0 {
+		s.status = statusCode
+	}
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,329 @@
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Writer compresses the response body.
+type Writer interface {
+	io.WriteCloser
+	// Flush writes any pending compressed data to the underlying writer.
+	Flush() error
+}
+
+// Encoding is a content coding the middleware can compress responses with.
+// Use [Gzip] and [Deflate], or plug another algorithm like br or zstd:
+//
+//	compress.Encoding{
+//		Name:      "br",
+//		NewWriter: func(w io.Writer) compress.Writer { return brotli.NewWriter(w) },
+//	}
+type Encoding struct {
+	// Name is the value of the Content-Encoding header, like "gzip" or "br".
+	Name string
+	// NewWriter returns a Writer compressing to w. Called once per compressed response.
+	NewWriter func(w io.Writer) Writer
+}
+
+const (
+	// DefaultMinSize is the minimum body size compressed by default, in bytes.
+	DefaultMinSize = 1024
+	// NoMinSize compresses the responses whatever their size, when used as [Config.MinSize].
+	NoMinSize = -1
+)
+
+// DefaultSkipContentTypes are the content types that are already compressed.
+var DefaultSkipContentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/",
+	"font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-bzip2", "application/x-7z-compressed", "application/x-rar-compressed",
+	"application/pdf", "application/wasm",
+}
+
+type Config struct {
+	// Encodings supported, by order of preference when the client has none.
+	// Defaults to gzip and deflate with the default compression level.
+	Encodings []Encoding
+	// MinSize is the minimum body size to compress, in bytes. Defaults to [DefaultMinSize].
+	// Smaller bodies are sent as is, unless the response is flushed before reaching it.
+	// Use [NoMinSize] to compress every response.
+	MinSize int
+	// SkipContentTypes are the content types not to compress. Entries ending with "/" match a whole type.
+	// Defaults to [DefaultSkipContentTypes].
+	SkipContentTypes []string
+}
+
+// New returns a middleware compressing responses according to the Accept-Encoding header.
+// Use it on a route or a group with option.Middleware, or on every request with fuego.WithGlobalMiddlewares.
+//
+// Responses are not compressed when they are smaller than MinSize, already encoded,
+// partial (206), have a skipped content type, or a "Cache-Control: no-transform" header.
+// Flushing (used by streams) and hijacking are supported, and trailers like Server-Timing are kept.
+func New(config ...Config) func(http.Handler) http.Handler {
+	if len(config) > 1 {
+		panic("Only one config is allowed")
+	}
+
+	c := Config{
+		Encodings:        []Encoding{Gzip(gzip.DefaultCompression), Deflate(flate.DefaultCompression)},
+		MinSize:          DefaultMinSize,
+		SkipContentTypes: DefaultSkipContentTypes,
+	}
+	if len(config) == 1 {
+		if config[0].Encodings != nil {
+			c.Encodings = config[0].Encodings
+		}
+		if config[0].MinSize != 0 {
+			c.MinSize = max(config[0].MinSize, 0)
+		}
+		if config[0].SkipContentTypes != nil {
+			c.SkipContentTypes = config[0].SkipContentTypes
+		}
+	}
+
+	offers := make([]string, 0, len(c.Encodings)+1)
+	encodings := make(map[string]Encoding, len(c.Encodings))
+	for _, encoding := range c.Encodings {
+		if encoding.Name == "" || encoding.NewWriter == nil {
+			panic("compress: encodings must have a name and a writer")
+		}
+		offers = append(offers, encoding.Name)
+		encodings[encoding.Name] = encoding
+	}
+	offers = append(offers, "identity")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fuego.AddVary(w.Header(), "Accept-Encoding")
+
+			encoding, ok := encodings[fuego.NegotiateEncoding(r.Header.Get("Accept-Encoding"), offers)]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &responseWriter{ResponseWriter: w, config: &c, encoding: encoding}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// Gzip returns the gzip encoding with the given compression level. Writers are pooled.
+func Gzip(level int) Encoding {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		panic(err)
+	}
+	pool := &sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, level)
+		return w
+	}}
+	return Encoding{
+		Name: "gzip",
+		NewWriter: func(w io.Writer) Writer {
+			gz := pool.Get().(*gzip.Writer)
+			gz.Reset(w)
+			return &pooledWriter{Writer: gz, pool: pool}
+		},
+	}
+}
+
+// Deflate returns the deflate encoding with the given compression level. Writers are pooled.
+func Deflate(level int) Encoding {
+	if _, err := flate.NewWriter(io.Discard, level); err != nil {
+		panic(err)
+	}
+	pool := &sync.Pool{New: func() any {
+		w, _ := flate.NewWriter(io.Discard, level)
+		return w
+	}}
+	return Encoding{
+		Name: "deflate",
+		NewWriter: func(w io.Writer) Writer {
+			fw := pool.Get().(*flate.Writer)
+			fw.Reset(w)
+			return &pooledWriter{Writer: fw, pool: pool}
+		},
+	}
+}
+
+// pooledWriter puts the writer back in its pool when closed.
+type pooledWriter struct {
+	Writer
+	pool *sync.Pool
+}
+
+func (p *pooledWriter) Close() error {
+	err := p.Writer.Close()
+	p.pool.Put(p.Writer)
+	return err
+}
+
+// responseWriter buffers the beginning of the body to decide whether to compress it.
+type responseWriter struct {
+	http.ResponseWriter
+	config   *Config
+	encoding Encoding
+
+	status      int
+	wroteHeader bool   // WriteHeader was called by the handler
+	started     bool   // WriteHeader was called on the underlying writer
+	hijacked    bool   // the connection was hijacked
+	compressor  Writer // nil when the response is not compressed
+	buf         []byte
+}
+
+func (cw *responseWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		// Informational responses, like 103 Early Hints
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	cw.wroteHeader = true
+	cw.status = code
+
+	// Decide right away when the buffer is not needed to know
+	if !cw.compressible() {
+		_ = cw.start(false)
+	} else if length, err := strconv.Atoi(cw.Header().Get("Content-Length")); err == nil && length < cw.config.MinSize {
+		_ = cw.start(false)
+	}
+}
+
+func (cw *responseWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.started {
+		if cw.compressor != nil {
+			return cw.compressor.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.config.MinSize {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush compresses the buffered data, even if smaller than MinSize: the response is being streamed.
+func (cw *responseWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.started {
+		_ = cw.start(true)
+	}
+	if cw.compressor != nil {
+		_ = cw.compressor.Flush()
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+func (cw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(cw.ResponseWriter).Hijack()
+	if err == nil {
+		cw.hijacked = true
+	}
+	return conn, rw, err
+}
+
+func (cw *responseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// start writes the status code, then the buffered data, compressed or not.
+// Compression is still skipped if the sniffed content type is already compressed.
+func (cw *responseWriter) start(compress bool) error {
+	cw.started = true
+
+	header := cw.Header()
+	if compress && header.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if compress && cw.compressible() {
+		header.Set("Content-Encoding", cw.encoding.Name)
+		header.Del("Content-Length")
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			// The compressed representation is not byte-for-byte identical
+			header.Set("ETag", "W/"+etag)
+		}
+		cw.compressor = cw.encoding.NewWriter(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.compressor != nil {
+		_, err = cw.compressor.Write(cw.buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf)
+	}
+	cw.buf = nil
+	return err
+}
+
+// close sends the remaining data once the handler has returned.
+func (cw *responseWriter) close() {
+	if cw.hijacked || !cw.wroteHeader {
+		return
+	}
+	if !cw.started {
+		// Smaller than MinSize
+		_ = cw.start(false)
+	}
+	if cw.compressor != nil {
+		_ = cw.compressor.Close()
+	}
+}
+
+// compressible reports whether the response, as known so far, can be compressed.
+func (cw *responseWriter) compressible() bool {
+	switch cw.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent, http.StatusSwitchingProtocols:
+		return false
+	}
+
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	if strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-transform") {
+		return false
+	}
+
+	contentType, _, _ := strings.Cut(header.Get("Content-Type"), ";")
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return true
+	}
+	for _, skipped := range cw.config.SkipContentTypes {
+		if contentType == skipped || (strings.HasSuffix(skipped, "/") && strings.HasPrefix(contentType, skipped)) {
+			return false
+		}
+	}
+	return true
+}
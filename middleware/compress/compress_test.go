@@ -0,0 +1,245 @@
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+	"github.com/go-fuego/fuego/option"
+)
+
+type item struct {
+	Name string `json:"name"`
+}
+
+func bigList(c fuego.ContextNoBody) ([]item, error) {
+	items := make([]item, 200)
+	for i := range items {
+		items[i] = item{Name: "a name long enough to be compressed"}
+	}
+	return items, nil
+}
+
+func gunzip(t *testing.T, r io.Reader) string {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestCompress(t *testing.T) {
+	s := fuego.NewServer()
+	fuego.Get(s, "/big", bigList, option.Middleware(New()))
+	fuego.Get(s, "/small", func(c fuego.ContextNoBody) (item, error) {
+		return item{Name: "small"}, nil
+	}, option.Middleware(New()))
+	fuego.Get(s, "/small/compressed", func(c fuego.ContextNoBody) (item, error) {
+		return item{Name: "small"}, nil
+	}, option.Middleware(New(Config{MinSize: NoMinSize})))
+	fuego.Get(s, "/png", func(c fuego.ContextNoBody) (any, error) {
+		c.Response().Header().Set("Content-Type", "image/png")
+		_, err := c.Response().Write(make([]byte, 2048))
+		return nil, err
+	}, option.Middleware(New()))
+	fuego.Get(s, "/stream", func(c fuego.ContextNoBody) (iter.Seq[item], error) {
+		return func(yield func(item) bool) {
+			for range 100 {
+				if !yield(item{Name: "streamed"}) {
+					return
+				}
+			}
+		}, nil
+	}, option.Middleware(New()))
+
+	t.Run("compresses with gzip", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/big", nil)
+		r.Header.Set("Accept-Encoding", "gzip, deflate")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		require.Empty(t, w.Header().Get("Content-Length"))
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.Contains(t, gunzip(t, w.Body), "a name long enough to be compressed")
+	})
+
+	t.Run("compresses with deflate", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/big", nil)
+		r.Header.Set("Accept-Encoding", "gzip;q=0.5, deflate")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+		body, err := io.ReadAll(flate.NewReader(w.Body))
+		require.NoError(t, err)
+		require.Contains(t, string(body), "a name long enough to be compressed")
+	})
+
+	t.Run("no Accept-Encoding", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/big", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		require.Contains(t, w.Body.String(), "a name long enough to be compressed")
+	})
+
+	t.Run("small bodies are not compressed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/small", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.JSONEq(t, `{"name":"small"}`, w.Body.String())
+	})
+
+	t.Run("small bodies are compressed without min size", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/small/compressed", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		require.JSONEq(t, `{"name":"small"}`, gunzip(t, w.Body))
+	})
+
+	t.Run("compressed content types are skipped", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/png", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Len(t, w.Body.Bytes(), 2048)
+	})
+
+	t.Run("flushed streams are compressed", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		r.Header.Set("Accept", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		require.True(t, w.Flushed)
+		require.Equal(t, strings.Repeat(`{"name":"streamed"}`+"\n", 100), gunzip(t, w.Body))
+	})
+}
+
+func TestCompressWithServer(t *testing.T) {
+	s := fuego.NewServer()
+	fuego.Get(s, "/big", bigList)
+	fuego.Get(s, "/hijack", func(c fuego.ContextNoBody) (any, error) {
+		conn, rw, err := http.NewResponseController(c.Response()).Hijack()
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		return nil, rw.Flush()
+	})
+
+	// As registered with fuego.WithGlobalMiddlewares
+	server := httptest.NewServer(New(Config{MinSize: 10})(s.Mux))
+	defer server.Close()
+
+	t.Run("keeps the Server-Timing trailer", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/big", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		res, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, "gzip", res.Header.Get("Content-Encoding"))
+		require.Contains(t, gunzip(t, res.Body), "a name long enough to be compressed")
+		assert.Contains(t, res.Trailer.Get("Server-Timing"), "serialize")
+	})
+
+	t.Run("hijacking", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/hijack", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		res, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(bufio.NewReader(res.Body))
+		require.NoError(t, err)
+		require.Equal(t, "hijacked", string(body))
+	})
+}
+
+func TestNegotiation(t *testing.T) {
+	brotliLike := Encoding{
+		Name:      "br",
+		NewWriter: func(w io.Writer) Writer { gz, _ := gzip.NewWriterLevel(w, gzip.BestSpeed); return gz },
+	}
+	handler := New(Config{Encodings: []Encoding{brotliLike, Gzip(gzip.BestSpeed)}, MinSize: 1})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("hello"))
+		}),
+	)
+
+	tcs := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{acceptEncoding: "gzip, br", expected: "gzip"},
+		{acceptEncoding: "gzip, br;q=0.5", expected: "gzip"},
+		{acceptEncoding: "zstd", expected: ""},
+		{acceptEncoding: "*", expected: "br"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.acceptEncoding, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expected, w.Header().Get("Content-Encoding"))
+		})
+	}
+}
+
+func TestFlush(t *testing.T) {
+	handler := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+		http.NewResponseController(w).Flush()
+		_, _ = w.Write([]byte(" world"))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	require.True(t, w.Flushed)
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"), "flushed bodies are compressed even if small")
+	require.Equal(t, "hello world", gunzip(t, w.Body))
+}
+
+func TestConfigPanics(t *testing.T) {
+	require.Panics(t, func() { New(Config{}, Config{}) })
+	require.Panics(t, func() { New(Config{Encodings: []Encoding{{Name: "br"}}}) })
+	require.Panics(t, func() { Gzip(42) })
+}
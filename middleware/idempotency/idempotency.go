@@ -0,0 +1,268 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Header is the request header holding the idempotency key.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on the responses replayed from the store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+type Config struct {
+	// Store keeps the records of the keys. Defaults to a [MemoryStore]. Use a shared store when running several instances.
+	Store Store
+	// TTL is how long the responses are kept. Defaults to 24 hours.
+	TTL time.Duration
+	// InFlightTTL is how long a key stays reserved by a request that never completes, for example after a crash.
+	// Defaults to 1 minute.
+	InFlightTTL time.Duration
+	// Required rejects the requests without an Idempotency-Key header with a 400 Bad Request error.
+	Required bool
+	// Scope returns the namespace of the keys of a request. Defaults to the method and the path.
+	// Add the user identity to it so that users cannot replay each other's responses.
+	Scope func(r *http.Request) string
+	// MaxBodySize is the maximum size of the request bodies read to fingerprint the requests, in bytes.
+	// Larger bodies are rejected with a 413 Request Entity Too Large error. Defaults to 1 MiB.
+	MaxBodySize int64
+}
+
+// New returns a middleware making POST, PUT, PATCH and DELETE requests with an Idempotency-Key header idempotent.
+// Use it on a route or a group with [Option] to document the header in the OpenAPI spec.
+//
+// The first response for a key is stored, with its status code, headers and body,
+// and replayed for the retries with the same key and the same request (method, URL and body),
+// with an Idempotent-Replayed: true header. Server errors (5xx) are not stored, so that the request can be retried.
+//
+// Retries while the first request is in flight get a 409 Conflict error,
+// and requests reusing a key with a different payload get a 422 Unprocessable Entity error.
+func New(config ...Config) func(http.Handler) http.Handler {
+	if len(config) > 1 {
+		panic("Only one config is allowed")
+	}
+
+	c := Config{
+		Store:       NewMemoryStore(),
+		TTL:         24 * time.Hour,
+		InFlightTTL: time.Minute,
+		MaxBodySize: 1 << 20,
+		Scope: func(r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		},
+	}
+	if len(config) == 1 {
+		if config[0].Store != nil {
+			c.Store = config[0].Store
+		}
+		if config[0].TTL != 0 {
+			c.TTL = config[0].TTL
+		}
+		if config[0].InFlightTTL != 0 {
+			c.InFlightTTL = config[0].InFlightTTL
+		}
+		if config[0].Scope != nil {
+			c.Scope = config[0].Scope
+		}
+		if config[0].MaxBodySize != 0 {
+			c.MaxBodySize = config[0].MaxBodySize
+		}
+		c.Required = config[0].Required
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			idempotencyKey := r.Header.Get(Header)
+			if idempotencyKey == "" {
+				if c.Required {
+					fuego.SendError(w, r, fuego.BadRequestError{
+						Title:  "Missing Idempotency-Key",
+						Detail: "the " + Header + " header is required",
+						Status: http.StatusBadRequest,
+					})
+					return
+				}
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			fingerprint, err := fingerprint(w, r, c.MaxBodySize)
+			if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+				fuego.SendError(w, r, fuego.HTTPError{
+					Err:    err,
+					Title:  "Request Entity Too Large",
+					Detail: fmt.Sprintf("the request body is larger than %d bytes", maxBytesErr.Limit),
+					Status: http.StatusRequestEntityTooLarge,
+				})
+				return
+			} else if err != nil {
+				fuego.SendError(w, r, fuego.BadRequestError{
+					Err:    err,
+					Title:  "Bad Request",
+					Detail: "cannot read the request body",
+					Status: http.StatusBadRequest,
+				})
+				return
+			}
+
+			key := c.Scope(r) + " " + idempotencyKey
+			existing, reserved, err := c.Store.Reserve(r.Context(), key, Record{Fingerprint: fingerprint}, c.InFlightTTL)
+			if err != nil {
+				fuego.SendError(w, r, fuego.HTTPError{
+					Err:    err,
+					Title:  "Internal Server Error",
+					Detail: "cannot check the idempotency key",
+					Status: http.StatusInternalServerError,
+				})
+				return
+			}
+
+			if !reserved {
+				replay(w, r, existing, fingerprint)
+				return
+			}
+
+			buf := &bytes.Buffer{}
+			rec := &recorder{ResponseWriter: w, body: buf}
+			completed := false
+			defer func() {
+				// Releases the key if the handler panics, or when the response must not be replayed
+				if completed && rec.status < http.StatusInternalServerError {
+					return
+				}
+				if err := c.Store.Delete(context.WithoutCancel(r.Context()), key); err != nil {
+					slog.WarnContext(r.Context(), "idempotency: cannot release key", "err", err)
+				}
+			}()
+
+			h.ServeHTTP(rec, r)
+			completed = true
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+				rec.header = w.Header().Clone()
+			}
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+
+			err = c.Store.Save(context.WithoutCancel(r.Context()), key, Record{
+				Fingerprint: fingerprint,
+				Done:        true,
+				Status:      rec.status,
+				Header:      replayableHeader(rec.header),
+				Body:        buf.Bytes(),
+			}, c.TTL)
+			if err != nil {
+				slog.WarnContext(r.Context(), "idempotency: cannot save response", "err", err)
+				completed = false
+			}
+		})
+	}
+}
+
+// replay sends the stored response, or an error if it is not available for this request.
+func replay(w http.ResponseWriter, r *http.Request, record Record, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		fuego.SendError(w, r, fuego.HTTPError{
+			Title:  "Unprocessable Entity",
+			Detail: "the " + Header + " was already used with a different request",
+			Status: http.StatusUnprocessableEntity,
+		})
+		return
+	}
+
+	if !record.Done {
+		fuego.SendError(w, r, fuego.ConflictError{
+			Title:  "Conflict",
+			Detail: "a request with the same " + Header + " is being processed, retry later",
+			Status: http.StatusConflict,
+		})
+		return
+	}
+
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+// requestScopedHeaders describe the request rather than the response, so they are not replayed.
+var requestScopedHeaders = []string{"Date", "Server-Timing", "X-Request-ID"}
+
+// replayableHeader removes the request-scoped headers and the trailers announced in the headers, as they are not replayed.
+func replayableHeader(header http.Header) http.Header {
+	for _, trailers := range header.Values("Trailer") {
+		for name := range strings.SplitSeq(trailers, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+	header.Del("Trailer")
+	for _, name := range requestScopedHeaders {
+		header.Del(name)
+	}
+	return header
+}
+
+// fingerprint hashes the method, the URL and the body of the request, of maxBodySize bytes at most.
+// The body can still be read afterwards.
+func fingerprint(w http.ResponseWriter, r *http.Request, maxBodySize int64) (string, error) {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+
+	if r.Body != nil {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			return "", err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash.Write(body)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Option makes a route, or all the routes of a group, idempotent,
+// and documents the Idempotency-Key header and the 409, 413 and 422 responses in the OpenAPI spec.
+//
+//	fuego.Post(s, "/payments", createPayment, idempotency.Option(idempotency.Config{Required: true}))
+func Option(config ...Config) fuego.RouteOption {
+	middleware := New(config...)
+
+	paramOptions := []fuego.ParamOption{}
+	if len(config) == 1 && config[0].Required {
+		paramOptions = append(paramOptions, fuego.ParamRequired())
+	}
+
+	return func(r *fuego.BaseRoute) {
+		fuego.OptionMiddleware(middleware)(r)
+		fuego.OptionHeader(Header, "Unique key of the request, to retry it safely: the response of the first request is replayed", paramOptions...)(r)
+
+		errorResponse := fuego.Response{
+			Type:         fuego.HTTPError{},
+			ContentTypes: []string{"application/problem+json", "application/json"},
+		}
+		fuego.OptionAddResponse(http.StatusConflict, "Conflict: a request with the same Idempotency-Key is in flight", errorResponse)(r)
+		fuego.OptionAddResponse(http.StatusRequestEntityTooLarge, "Request Entity Too Large: the request body is too large to check the Idempotency-Key", errorResponse)(r)
+		fuego.OptionAddResponse(http.StatusUnprocessableEntity, "Unprocessable Entity: the Idempotency-Key was already used with a different request", errorResponse)(r)
+	}
+}
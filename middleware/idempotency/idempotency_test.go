@@ -0,0 +1,244 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+	"github.com/go-fuego/fuego/middleware/idempotency"
+)
+
+func TestNew(t *testing.T) {
+	require.Panics(t, func() { idempotency.New(idempotency.Config{}, idempotency.Config{}) })
+
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := idempotency.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "slow" {
+			close(started)
+			<-release
+		}
+		if string(body) == "fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		n := calls.Add(1)
+		w.Header().Set("Location", "/orders/"+strconv.Itoa(int(n)))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("order " + strconv.Itoa(int(n)) + ": " + string(body)))
+	}))
+
+	do := func(method, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/orders", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(idempotency.Header, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("replays the first response", func(t *testing.T) {
+		w := do(http.MethodPost, "key-1", "pizza")
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Empty(t, w.Header().Get(idempotency.ReplayedHeader))
+		first := w.Body.String()
+
+		w = do(http.MethodPost, "key-1", "pizza")
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, first, w.Body.String())
+		require.Equal(t, "true", w.Header().Get(idempotency.ReplayedHeader))
+		require.Equal(t, "/orders/1", w.Header().Get("Location"))
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("new keys run the handler", func(t *testing.T) {
+		w := do(http.MethodPost, "key-2", "pizza")
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Empty(t, w.Header().Get(idempotency.ReplayedHeader))
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("keys are scoped to the method and path", func(t *testing.T) {
+		w := do(http.MethodPut, "key-1", "pizza")
+		require.Empty(t, w.Header().Get(idempotency.ReplayedHeader))
+	})
+
+	t.Run("422 on payload mismatch", func(t *testing.T) {
+		w := do(http.MethodPost, "key-1", "pasta")
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		require.Contains(t, w.Body.String(), "different request")
+	})
+
+	t.Run("409 while in flight", func(t *testing.T) {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- do(http.MethodPost, "key-slow", "slow") }()
+
+		<-started
+		require.Equal(t, http.StatusConflict, do(http.MethodPost, "key-slow", "slow").Code)
+
+		close(release)
+		require.Equal(t, http.StatusCreated, (<-done).Code)
+		require.Equal(t, "true", do(http.MethodPost, "key-slow", "slow").Header().Get(idempotency.ReplayedHeader))
+	})
+
+	t.Run("server errors are not stored", func(t *testing.T) {
+		require.Equal(t, http.StatusServiceUnavailable, do(http.MethodPost, "key-fail", "fail").Code)
+		w := do(http.MethodPost, "key-fail", "fail")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Empty(t, w.Header().Get(idempotency.ReplayedHeader))
+	})
+
+	t.Run("requests without key are not idempotent", func(t *testing.T) {
+		before := calls.Load()
+		do(http.MethodPost, "", "pizza")
+		do(http.MethodPost, "", "pizza")
+		require.Equal(t, before+2, calls.Load())
+	})
+
+	t.Run("safe methods are ignored", func(t *testing.T) {
+		w := do(http.MethodGet, "key-1", "")
+		require.Empty(t, w.Header().Get(idempotency.ReplayedHeader))
+	})
+}
+
+func TestReplayRequestScopedHeaders(t *testing.T) {
+	h := idempotency.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server-Timing", "db;dur=12")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	// Sets the request ID of each request, like the logging middleware of Fuego
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", r.Header.Get("X-Request-ID"))
+		h.ServeHTTP(w, r)
+	})
+
+	do := func(requestID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("pizza"))
+		r.Header.Set(idempotency.Header, "key")
+		r.Header.Set("X-Request-ID", requestID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	require.Equal(t, "first", do("first").Header().Get("X-Request-ID"))
+
+	w := do("second")
+	require.Equal(t, "true", w.Header().Get(idempotency.ReplayedHeader))
+	require.Equal(t, "second", w.Header().Get("X-Request-ID"))
+	require.Empty(t, w.Header().Get("Server-Timing"))
+}
+
+func TestRequired(t *testing.T) {
+	handler := idempotency.New(idempotency.Config{Required: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "Idempotency-Key")
+}
+
+func TestMaxBodySize(t *testing.T) {
+	var calls atomic.Int32
+	handler := idempotency.New(idempotency.Config{MaxBodySize: 8})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	do := func(body string) int {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		r.Header.Set(idempotency.Header, body)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusCreated, do("12345678"))
+	require.Equal(t, http.StatusRequestEntityTooLarge, do("123456789"))
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestHandlerPanicReleasesKey(t *testing.T) {
+	var calls atomic.Int32
+	handler := idempotency.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	do := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		r.Header.Set(idempotency.Header, "key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	require.Panics(t, func() { do() })
+	require.Equal(t, http.StatusCreated, do().Code)
+}
+
+type failingStore struct{ idempotency.Store }
+
+func (failingStore) Reserve(context.Context, string, idempotency.Record, time.Duration) (idempotency.Record, bool, error) {
+	return idempotency.Record{}, false, errors.New("store unavailable")
+}
+
+func TestStoreFailure(t *testing.T) {
+	handler := idempotency.New(idempotency.Config{Store: failingStore{}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	r.Header.Set(idempotency.Header, "key")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestOption(t *testing.T) {
+	s := fuego.NewServer()
+
+	var calls atomic.Int32
+	route := fuego.Post(s, "/payments", func(c fuego.ContextNoBody) (string, error) {
+		return "payment " + strconv.Itoa(int(calls.Add(1))), nil
+	}, idempotency.Option(idempotency.Config{Required: true}))
+
+	t.Run("makes the route idempotent", func(t *testing.T) {
+		do := func() string {
+			r := httptest.NewRequest(http.MethodPost, "/payments", nil)
+			r.Header.Set(idempotency.Header, "key")
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+			return w.Body.String()
+		}
+
+		require.Equal(t, do(), do())
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("documents the header and errors", func(t *testing.T) {
+		param := route.Operation.Parameters.GetByInAndName("header", idempotency.Header)
+		require.NotNil(t, param)
+		assert.True(t, param.Required)
+		assert.NotNil(t, route.Operation.Responses.Value("409"))
+		assert.NotNil(t, route.Operation.Responses.Value("413"))
+		assert.NotNil(t, route.Operation.Responses.Value("422"))
+	})
+}
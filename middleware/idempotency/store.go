@@ -0,0 +1,103 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the state of an idempotency key.
+type Record struct {
+	// Fingerprint identifies the request that first used the key.
+	Fingerprint string `json:"fingerprint"`
+	// Done is false while the first request is in flight.
+	Done bool `json:"done"`
+	// Status, Header and Body are the stored response, once done.
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Store keeps the records of the idempotency keys. Implementations must be safe for concurrent use.
+// Shared backends can implement Reserve atomically, like SET NX on Redis.
+type Store interface {
+	// Reserve stores the record if the key is new and reports true,
+	// or returns the existing record of the key and reports false.
+	Reserve(ctx context.Context, key string, record Record, ttl time.Duration) (Record, bool, error)
+	// Save replaces the record of a reserved key.
+	Save(ctx context.Context, key string, record Record, ttl time.Duration) error
+	// Delete releases a key, so that the request can be retried.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStore is an in-memory [Store], for single-node deployments.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryRecord
+	lastSweep time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// sweepInterval is the minimum time between two removals of the expired records.
+const sweepInterval = time.Minute
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records:   map[string]memoryRecord{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *MemoryStore) Reserve(_ context.Context, key string, record Record, ttl time.Duration) (Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	if existing, ok := m.records[key]; ok && now.Before(existing.expiresAt) {
+		return existing.Record, false, nil
+	}
+	m.records[key] = memoryRecord{Record: record, expiresAt: now.Add(ttl)}
+
+	return record, true, nil
+}
+
+func (m *MemoryStore) Save(_ context.Context, key string, record Record, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[key] = memoryRecord{Record: record, expiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (m *MemoryStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+
+	return nil
+}
+
+// sweep removes the expired records, at most once per [sweepInterval]. Called with the lock held.
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, record := range m.records {
+		if now.After(record.expiresAt) {
+			delete(m.records, key)
+		}
+	}
+}
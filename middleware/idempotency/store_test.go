@@ -0,0 +1,55 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	record, reserved, err := store.Reserve(ctx, "key", Record{Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Equal(t, "a", record.Fingerprint)
+
+	record, reserved, err = store.Reserve(ctx, "key", Record{Fingerprint: "b"}, time.Minute)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.Equal(t, "a", record.Fingerprint)
+	require.False(t, record.Done)
+
+	require.NoError(t, store.Save(ctx, "key", Record{Fingerprint: "a", Done: true, Status: 201}, time.Minute))
+	record, reserved, err = store.Reserve(ctx, "key", Record{Fingerprint: "a"}, time.Minute)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.True(t, record.Done)
+	require.Equal(t, 201, record.Status)
+
+	require.NoError(t, store.Delete(ctx, "key"))
+	_, reserved, err = store.Reserve(ctx, "key", Record{Fingerprint: "b"}, time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	t.Run("records expire", func(t *testing.T) {
+		time.Sleep(2 * time.Millisecond)
+		_, reserved, err := store.Reserve(ctx, "key", Record{Fingerprint: "c"}, time.Minute)
+		require.NoError(t, err)
+		require.True(t, reserved)
+	})
+
+	t.Run("sweeps expired records", func(t *testing.T) {
+		_, _, err := store.Reserve(ctx, "expired", Record{}, time.Millisecond)
+		require.NoError(t, err)
+		store.lastSweep = time.Now().Add(-sweepInterval)
+		time.Sleep(2 * time.Millisecond)
+
+		_, _, err = store.Reserve(ctx, "other", Record{}, time.Minute)
+		require.NoError(t, err)
+		require.NotContains(t, store.records, "expired")
+	})
+}
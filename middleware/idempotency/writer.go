@@ -0,0 +1,36 @@
+package idempotency
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// recorder is a http.ResponseWriter writing the response to the client and keeping a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	status int         // status is the status code written to the response
+	header http.Header // header is a copy of the headers when the status code was written
+	body   *bytes.Buffer
+}
+
+var _ http.ResponseWriter = &recorder{}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+		r.header = r.ResponseWriter.Header().Clone()
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,28 @@
+package idempotency
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := &recorder{ResponseWriter: w, body: &bytes.Buffer{}}
+
+	rec.Header().Set("X-Before", "kept")
+	_, err := rec.Write([]byte("hello"))
+	require.NoError(t, err)
+	rec.Header().Set("X-After", "not kept")
+	rec.WriteHeader(http.StatusTeapot)
+
+	require.Equal(t, http.StatusOK, rec.status, "the first status code is kept")
+	require.Equal(t, "kept", rec.header.Get("X-Before"))
+	require.Empty(t, rec.header.Get("X-After"))
+	require.Equal(t, "hello", rec.body.String())
+	require.Equal(t, "hello", w.Body.String())
+	require.Equal(t, w, rec.Unwrap())
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-fuego/fuego"
+)
+
+// KeyFunc returns the key of the requests sharing a limit. Requests with an empty key are not limited.
+type KeyFunc func(r *http.Request) string
+
+// ByIP limits the requests by client IP address, from the connection.
+// Behind a reverse proxy, set the remote address from the proxy headers with a middleware,
+// or use [ByHeader] with the header set by the proxy, like X-Real-IP.
+func ByIP() KeyFunc {
+	return func(r *http.Request) string {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if host == "" {
+			return ""
+		}
+		return "ip:" + host
+	}
+}
+
+// BySubject limits the requests by the subject of the JWT validated by the [fuego.Security] middlewares,
+// found with [fuego.TokenFromContext]. Anonymous requests are not limited, see [Or] to limit them by IP.
+func BySubject() KeyFunc {
+	return func(r *http.Request) string {
+		claims, err := fuego.TokenFromContext(r.Context())
+		if err != nil {
+			return ""
+		}
+		subject, err := claims.GetSubject()
+		if err != nil || subject == "" {
+			return ""
+		}
+		return "sub:" + subject
+	}
+}
+
+// ByHeader limits the requests by the value of a request header, like an API key.
+// Requests without the header are not limited, see [Or] to limit them by IP.
+//
+//	ratelimit.ByHeader("X-API-Key")
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		value := r.Header.Get(name)
+		if value == "" {
+			return ""
+		}
+		return "header:" + http.CanonicalHeaderKey(name) + ":" + value
+	}
+}
+
+// Or uses the first non-empty key.
+//
+//	ratelimit.Or(ratelimit.BySubject(), ratelimit.ByIP())
+func Or(keys ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		for _, key := range keys {
+			if k := key(r); k != "" {
+				return k
+			}
+		}
+		return ""
+	}
+}
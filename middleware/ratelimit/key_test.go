@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func TestKeys(t *testing.T) {
+	t.Run("by IP", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		require.Equal(t, "ip:192.0.2.1", ByIP()(r))
+
+		r.RemoteAddr = "192.0.2.1"
+		require.Equal(t, "ip:192.0.2.1", ByIP()(r))
+
+		r.RemoteAddr = ""
+		require.Empty(t, ByIP()(r))
+	})
+
+	t.Run("by subject", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		require.Empty(t, BySubject()(r))
+
+		r = r.WithContext(fuego.WithValue(r.Context(), jwt.MapClaims{"sub": "alice"}))
+		require.Equal(t, "sub:alice", BySubject()(r))
+	})
+
+	t.Run("by header", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		require.Empty(t, ByHeader("X-API-Key")(r))
+
+		r.Header.Set("X-API-Key", "secret")
+		require.Equal(t, "header:X-Api-Key:secret", ByHeader("x-api-key")(r))
+	})
+
+	t.Run("or", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		key := Or(ByHeader("X-API-Key"), ByIP())
+		require.Equal(t, "ip:192.0.2.1", key(r))
+
+		r.Header.Set("X-API-Key", "secret")
+		require.Equal(t, "header:X-Api-Key:secret", key(r))
+
+		require.Empty(t, Or()(r))
+	})
+}
@@ -0,0 +1,179 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Limiter decides whether a request is allowed, according to the counters of its key.
+type Limiter interface {
+	// Allow counts a request for the key at the given time, if it is allowed.
+	Allow(ctx context.Context, store Store, key string, now time.Time) (Result, error)
+	// Policy describes the limit, as the value of the RateLimit-Policy header, like "100;w=60".
+	Policy() string
+}
+
+// Result is the state of the limit of a key after a request.
+type Result struct {
+	Allowed bool
+	// Limit is the number of requests allowed in a window.
+	Limit int
+	// Remaining is the number of requests still allowed in the current window.
+	Remaining int
+	// Reset is the time until the full quota is available again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, if not allowed.
+	RetryAfter time.Duration
+}
+
+// errConflict is returned when the counters of a key are modified concurrently too many times.
+var errConflict = errors.New("ratelimit: too many concurrent updates")
+
+// maxAttempts is the maximum number of compare-and-swap attempts to update a counter.
+const maxAttempts = 10
+
+// TokenBucket allows bursts of Burst requests, with tokens refilled at Rate requests per Period.
+// It is implemented with the Generic Cell Rate Algorithm, storing a single timestamp per key.
+type TokenBucket struct {
+	Rate   int
+	Period time.Duration
+	// Burst is the capacity of the bucket. Defaults to Rate.
+	Burst int
+}
+
+var _ Limiter = TokenBucket{}
+
+func (tb TokenBucket) validate() error {
+	if tb.Rate <= 0 || tb.Period <= 0 || tb.Burst < 0 {
+		return fmt.Errorf("ratelimit: invalid token bucket %d per %s with a burst of %d", tb.Rate, tb.Period, tb.Burst)
+	}
+	return nil
+}
+
+func (tb TokenBucket) burst() int {
+	if tb.Burst == 0 {
+		return tb.Rate
+	}
+	return tb.Burst
+}
+
+func (tb TokenBucket) Allow(ctx context.Context, store Store, key string, now time.Time) (Result, error) {
+	interval := tb.Period / time.Duration(tb.Rate)
+	capacity := interval * time.Duration(tb.burst())
+
+	for range maxAttempts {
+		// The counter is the theoretical arrival time: when the bucket would be full again
+		stored, err := store.Increment(ctx, key, 0, capacity)
+		if err != nil {
+			return Result{}, err
+		}
+
+		tat := time.Unix(0, stored)
+		if tat.Before(now) {
+			tat = now
+		}
+		newTat := tat.Add(interval)
+		allowAt := newTat.Add(-capacity)
+		if now.Before(allowAt) {
+			return Result{
+				Limit:      tb.burst(),
+				Reset:      tat.Sub(now),
+				RetryAfter: allowAt.Sub(now),
+			}, nil
+		}
+
+		swapped, err := store.CompareAndSwap(ctx, key, stored, newTat.UnixNano(), newTat.Sub(now))
+		if err != nil {
+			return Result{}, err
+		}
+		if swapped {
+			return Result{
+				Allowed:   true,
+				Limit:     tb.burst(),
+				Remaining: int(now.Sub(allowAt) / interval),
+				Reset:     newTat.Sub(now),
+			}, nil
+		}
+	}
+
+	return Result{}, errConflict
+}
+
+func (tb TokenBucket) Policy() string {
+	return strconv.Itoa(tb.Rate) + ";w=" + seconds(tb.Period) + ";burst=" + strconv.Itoa(tb.burst())
+}
+
+// SlidingWindow allows Limit requests per Window.
+// The count of the sliding window is estimated from the counts of the current and previous fixed windows.
+type SlidingWindow struct {
+	Limit  int
+	Window time.Duration
+}
+
+var _ Limiter = SlidingWindow{}
+
+func (sw SlidingWindow) validate() error {
+	if sw.Limit <= 0 || sw.Window <= 0 {
+		return fmt.Errorf("ratelimit: invalid sliding window of %d per %s", sw.Limit, sw.Window)
+	}
+	return nil
+}
+
+func (sw SlidingWindow) Allow(ctx context.Context, store Store, key string, now time.Time) (Result, error) {
+	index := now.UnixNano() / int64(sw.Window)
+	elapsed := now.Sub(time.Unix(0, index*int64(sw.Window)))
+	currentKey := key + ":" + strconv.FormatInt(index, 10)
+	previousKey := key + ":" + strconv.FormatInt(index-1, 10)
+
+	previous, err := store.Increment(ctx, previousKey, 0, 2*sw.Window)
+	if err != nil {
+		return Result{}, err
+	}
+	current, err := store.Increment(ctx, currentKey, 1, 2*sw.Window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	weight := 1 - float64(elapsed)/float64(sw.Window)
+	count := float64(previous)*weight + float64(current)
+	if count <= float64(sw.Limit) {
+		return Result{
+			Allowed:   true,
+			Limit:     sw.Limit,
+			Remaining: sw.Limit - int(math.Ceil(count)),
+			Reset:     sw.Window - elapsed,
+		}, nil
+	}
+
+	// Denied requests are not counted
+	if _, err := store.Increment(ctx, currentKey, -1, 2*sw.Window); err != nil {
+		return Result{}, err
+	}
+	current--
+
+	retryAfter := sw.Window - elapsed
+	if current < int64(sw.Limit) && previous > 0 {
+		// When the weight of the previous window leaves room for one request
+		freeAt := time.Duration(float64(sw.Window) * (1 - float64(int64(sw.Limit)-current-1)/float64(previous)))
+		retryAfter = freeAt - elapsed
+	}
+
+	return Result{
+		Limit:      sw.Limit,
+		Reset:      sw.Window - elapsed,
+		RetryAfter: retryAfter,
+	}, nil
+}
+
+func (sw SlidingWindow) Policy() string {
+	return strconv.Itoa(sw.Limit) + ";w=" + seconds(sw.Window)
+}
+
+// seconds formats the duration in whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
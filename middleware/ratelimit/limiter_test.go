@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	limiter := TokenBucket{Rate: 1, Period: time.Second, Burst: 3}
+	now := time.Now()
+
+	for i := range 3 {
+		result, err := limiter.Allow(ctx, store, "key", now)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Limit)
+		require.Equal(t, 2-i, result.Remaining)
+		require.Equal(t, time.Duration(i+1)*time.Second, result.Reset)
+	}
+
+	result, err := limiter.Allow(ctx, store, "key", now)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, time.Second, result.RetryAfter)
+	require.Equal(t, 3*time.Second, result.Reset)
+
+	result, err = limiter.Allow(ctx, store, "other key", now)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// A token is refilled every second
+	result, err = limiter.Allow(ctx, store, "key", now.Add(time.Second))
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	require.Equal(t, "1;w=1;burst=3", limiter.Policy())
+	require.Equal(t, "10;w=60;burst=10", TokenBucket{Rate: 10, Period: time.Minute}.Policy())
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	limiter := SlidingWindow{Limit: 4, Window: time.Minute}
+	start := time.Unix(0, 0).Add(1000 * time.Minute) // start of a window
+
+	for i := range 4 {
+		result, err := limiter.Allow(ctx, store, "key", start)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 4, result.Limit)
+		require.Equal(t, 3-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, store, "key", start.Add(30*time.Second))
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 30*time.Second, result.RetryAfter)
+	require.Equal(t, 30*time.Second, result.Reset)
+
+	// Half of the previous window counts: 2 requests, 2 remaining
+	result, err = limiter.Allow(ctx, store, "key", start.Add(90*time.Second))
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 1, result.Remaining)
+
+	result, err = limiter.Allow(ctx, store, "key", start.Add(90*time.Second))
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+
+	// Denied requests are not counted, the previous window leaves room for a request after 15s
+	result, err = limiter.Allow(ctx, store, "key", start.Add(90*time.Second))
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 15*time.Second, result.RetryAfter)
+
+	result, err = limiter.Allow(ctx, store, "key", start.Add(105*time.Second))
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	require.Equal(t, "4;w=60", limiter.Policy())
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, TokenBucket{Rate: 1, Period: time.Second}.validate())
+	require.Error(t, TokenBucket{Period: time.Second}.validate())
+	require.Error(t, TokenBucket{Rate: 1}.validate())
+	require.Error(t, TokenBucket{Rate: 1, Period: time.Second, Burst: -1}.validate())
+
+	require.NoError(t, SlidingWindow{Limit: 1, Window: time.Second}.validate())
+	require.Error(t, SlidingWindow{Window: time.Second}.validate())
+	require.Error(t, SlidingWindow{Limit: 1}.validate())
+}
+
+// conflictingStore never swaps, as if another instance always updated the counter first.
+type conflictingStore struct{ *MemoryStore }
+
+func (conflictingStore) CompareAndSwap(context.Context, string, int64, int64, time.Duration) (bool, error) {
+	return false, nil
+}
+
+func TestTokenBucketConflicts(t *testing.T) {
+	limiter := TokenBucket{Rate: 1, Period: time.Second}
+
+	_, err := limiter.Allow(context.Background(), conflictingStore{NewMemoryStore()}, "key", time.Now())
+	require.True(t, errors.Is(err, errConflict))
+}
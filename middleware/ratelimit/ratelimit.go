@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-fuego/fuego"
+)
+
+// Headers of the IETF draft "RateLimit header fields for HTTP".
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderPolicy    = "RateLimit-Policy"
+)
+
+type Config struct {
+	// Limiter is required, for example [TokenBucket] or [SlidingWindow].
+	Limiter Limiter
+	// Store keeps the counters. Defaults to a [MemoryStore]. Use a shared store when running several instances.
+	Store Store
+	// Key returns the key of the requests sharing a limit. Defaults to [ByIP].
+	Key KeyFunc
+	// Name separates the counters of several limiters using the same Store.
+	Name string
+}
+
+// New returns a middleware limiting the rate of requests.
+// Use it on a route or a group with [Option] to document the limit in the OpenAPI spec.
+//
+// Responses have the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers.
+// Requests over the limit get a 429 Too Many Requests error with a Retry-After header.
+// If the store fails, requests are allowed.
+func New(config Config) func(http.Handler) http.Handler {
+	if config.Limiter == nil {
+		panic("ratelimit: limiter is required")
+	}
+	if v, ok := config.Limiter.(interface{ validate() error }); ok {
+		if err := v.validate(); err != nil {
+			panic(err)
+		}
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.Key == nil {
+		config.Key = ByIP()
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := config.Key(r)
+			if key == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := config.Limiter.Allow(r.Context(), config.Store, "ratelimit:"+config.Name+":"+key, time.Now())
+			if err != nil {
+				slog.WarnContext(r.Context(), "ratelimit: cannot check the limit, request allowed", "err", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(HeaderLimit, strconv.Itoa(result.Limit))
+			w.Header().Set(HeaderRemaining, strconv.Itoa(result.Remaining))
+			w.Header().Set(HeaderReset, seconds(result.Reset))
+			w.Header().Set(HeaderPolicy, config.Limiter.Policy())
+
+			if !result.Allowed {
+				retryAfter := seconds(max(result.RetryAfter, time.Second))
+				w.Header().Set("Retry-After", retryAfter)
+				fuego.SendError(w, r, fuego.HTTPError{
+					Title:  "Too Many Requests",
+					Detail: "rate limit exceeded, retry in " + retryAfter + " seconds",
+					Status: http.StatusTooManyRequests,
+				})
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Option limits the rate of requests of a route, or of all the routes of a group,
+// and documents the 429 response and the RateLimit headers in the OpenAPI spec.
+//
+//	fuego.Post(s, "/login", login, ratelimit.Option(ratelimit.Config{
+//		Limiter: ratelimit.SlidingWindow{Limit: 5, Window: time.Minute},
+//	}))
+func Option(config Config) fuego.RouteOption {
+	middleware := New(config)
+
+	return func(r *fuego.BaseRoute) {
+		fuego.OptionMiddleware(middleware)(r)
+
+		fuego.OptionAddResponse(http.StatusTooManyRequests, "Too Many Requests", fuego.Response{
+			Type:         fuego.HTTPError{},
+			ContentTypes: []string{"application/problem+json", "application/json"},
+		})(r)
+
+		// The default status code of the route is resolved when it is registered, after all its options
+		statusCodes := []fuego.ParamOption{fuego.ParamStatusCodes(http.StatusTooManyRequests), fuego.ParamDefaultStatusCode()}
+		fuego.OptionResponseHeader(HeaderLimit, "Number of requests allowed in the window", append(statusCodes, fuego.ParamInteger())...)(r)
+		fuego.OptionResponseHeader(HeaderRemaining, "Number of requests remaining in the window", append(statusCodes, fuego.ParamInteger())...)(r)
+		fuego.OptionResponseHeader(HeaderReset, "Seconds until the quota is reset", append(statusCodes, fuego.ParamInteger())...)(r)
+		fuego.OptionResponseHeader(HeaderPolicy, "Quota policy", append(statusCodes, fuego.ParamExample("policy", config.Limiter.Policy()))...)(r)
+		fuego.OptionResponseHeader("Retry-After", "Seconds to wait before retrying", fuego.ParamInteger(), fuego.ParamStatusCodes(http.StatusTooManyRequests))(r)
+	}
+}
@@ -0,0 +1,140 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+	"github.com/go-fuego/fuego/middleware/ratelimit"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("limiter is required", func(t *testing.T) {
+		require.Panics(t, func() { ratelimit.New(ratelimit.Config{}) })
+	})
+
+	t.Run("limiter must be valid", func(t *testing.T) {
+		require.Panics(t, func() { ratelimit.New(ratelimit.Config{Limiter: ratelimit.TokenBucket{}}) })
+	})
+
+	handler := ratelimit.New(ratelimit.Config{
+		Limiter: ratelimit.SlidingWindow{Limit: 2, Window: time.Hour},
+		Key:     ratelimit.ByHeader("X-API-Key"),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}))
+
+	do := func(apiKey string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if apiKey != "" {
+			r.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("sets the RateLimit headers", func(t *testing.T) {
+		w := do("first")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "2", w.Header().Get(ratelimit.HeaderLimit))
+		require.Equal(t, "1", w.Header().Get(ratelimit.HeaderRemaining))
+		require.NotEmpty(t, w.Header().Get(ratelimit.HeaderReset))
+		require.Equal(t, "2;w=3600", w.Header().Get(ratelimit.HeaderPolicy))
+		require.Empty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("returns 429 over the limit", func(t *testing.T) {
+		do("second")
+		do("second")
+
+		w := do("second")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "0", w.Header().Get(ratelimit.HeaderRemaining))
+		require.NotEmpty(t, w.Header().Get("Retry-After"))
+		require.Contains(t, w.Header().Get("Content-Type"), "json")
+		require.Contains(t, w.Body.String(), "Too Many Requests")
+
+		require.Equal(t, http.StatusOK, do("third").Code, "other keys are not limited")
+	})
+
+	t.Run("requests without key are not limited", func(t *testing.T) {
+		for range 3 {
+			w := do("")
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Empty(t, w.Header().Get(ratelimit.HeaderLimit))
+		}
+	})
+}
+
+type failingStore struct{}
+
+func (failingStore) Increment(context.Context, string, int64, time.Duration) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func (failingStore) CompareAndSwap(context.Context, string, int64, int64, time.Duration) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func TestStoreFailureAllowsRequests(t *testing.T) {
+	handler := ratelimit.New(ratelimit.Config{
+		Limiter: ratelimit.TokenBucket{Rate: 1, Period: time.Hour},
+		Store:   failingStore{},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	}))
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func TestOption(t *testing.T) {
+	s := fuego.NewServer()
+
+	route := fuego.Post(s, "/login", func(c fuego.ContextNoBody) (string, error) {
+		return "logged in", nil
+	}, ratelimit.Option(ratelimit.Config{
+		Limiter: ratelimit.TokenBucket{Rate: 1, Period: time.Hour},
+	}), fuego.OptionDefaultStatusCode(201))
+
+	t.Run("limits the route", func(t *testing.T) {
+		do := func() int {
+			r := httptest.NewRequest(http.MethodPost, "/login", nil)
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+			return w.Code
+		}
+
+		require.Equal(t, http.StatusCreated, do())
+		require.Equal(t, http.StatusTooManyRequests, do())
+	})
+
+	t.Run("documents the limit", func(t *testing.T) {
+		tooManyRequests := route.Operation.Responses.Value("429")
+		require.NotNil(t, tooManyRequests)
+		assert.Equal(t, "Too Many Requests", *tooManyRequests.Value.Description)
+		assert.Contains(t, tooManyRequests.Value.Content, "application/problem+json")
+		assert.Contains(t, tooManyRequests.Value.Headers, "Retry-After")
+		assert.Contains(t, tooManyRequests.Value.Headers, ratelimit.HeaderRemaining)
+
+		created := route.Operation.Responses.Value("201")
+		require.NotNil(t, created)
+		for _, header := range []string{ratelimit.HeaderLimit, ratelimit.HeaderRemaining, ratelimit.HeaderReset, ratelimit.HeaderPolicy} {
+			assert.Contains(t, created.Value.Headers, header)
+		}
+		assert.NotContains(t, created.Value.Headers, "Retry-After")
+		assert.NotNil(t, created.Value.Content, "the default response content is still generated")
+		assert.Nil(t, route.Operation.Responses.Value("200"), "the default status code is set after the option")
+	})
+}
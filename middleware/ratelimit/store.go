@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps the counters of the limiters. Implementations must be safe for concurrent use.
+// Shared backends can implement it with atomic operations,
+// like INCRBY and PEXPIRE NX on Redis, and a Lua script for CompareAndSwap.
+type Store interface {
+	// Increment adds n to the counter of the key and returns its new value.
+	// A missing counter is created at 0, and expires after the ttl.
+	Increment(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+	// CompareAndSwap sets the counter of the key to new if its value is old (0 if missing),
+	// with a new ttl, and reports whether it was set.
+	CompareAndSwap(ctx context.Context, key string, old, new int64, ttl time.Duration) (bool, error)
+}
+
+// MemoryStore is an in-memory [Store], for single-node deployments.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]memoryCounter
+	lastSweep time.Time
+}
+
+type memoryCounter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// sweepInterval is the minimum time between two removals of the expired counters.
+const sweepInterval = time.Minute
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters:  map[string]memoryCounter{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *MemoryStore) Increment(_ context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	counter, ok := m.counters[key]
+	if !ok || now.After(counter.expiresAt) {
+		counter = memoryCounter{expiresAt: now.Add(ttl)}
+	}
+	counter.value += n
+	m.counters[key] = counter
+
+	return counter.value, nil
+}
+
+func (m *MemoryStore) CompareAndSwap(_ context.Context, key string, old, new int64, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	counter, ok := m.counters[key]
+	if !ok || now.After(counter.expiresAt) {
+		counter = memoryCounter{}
+	}
+	if counter.value != old {
+		return false, nil
+	}
+	m.counters[key] = memoryCounter{value: new, expiresAt: now.Add(ttl)}
+
+	return true, nil
+}
+
+// sweep removes the expired counters, at most once per [sweepInterval]. Called with the lock held.
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, counter := range m.counters {
+		if now.After(counter.expiresAt) {
+			delete(m.counters, key)
+		}
+	}
+}
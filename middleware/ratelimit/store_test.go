@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("increment", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		value, err := store.Increment(ctx, "key", 0, time.Minute)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, value)
+
+		value, err = store.Increment(ctx, "key", 2, time.Minute)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, value)
+
+		value, err = store.Increment(ctx, "key", -1, time.Minute)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, value)
+	})
+
+	t.Run("counters expire", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		_, err := store.Increment(ctx, "key", 5, 10*time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+
+		value, err := store.Increment(ctx, "key", 1, time.Minute)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, value)
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		swapped, err := store.CompareAndSwap(ctx, "key", 1, 2, time.Minute)
+		require.NoError(t, err)
+		require.False(t, swapped)
+
+		swapped, err = store.CompareAndSwap(ctx, "key", 0, 2, time.Minute)
+		require.NoError(t, err)
+		require.True(t, swapped)
+
+		value, err := store.Increment(ctx, "key", 0, time.Minute)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, value)
+	})
+
+	t.Run("sweeps expired counters", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		_, err := store.Increment(ctx, "expired", 1, time.Millisecond)
+		require.NoError(t, err)
+		store.lastSweep = time.Now().Add(-sweepInterval)
+		time.Sleep(2 * time.Millisecond)
+
+		_, err = store.Increment(ctx, "other", 1, time.Minute)
+		require.NoError(t, err)
+		require.NotContains(t, store.counters, "expired")
+	})
+}
@@ -0,0 +1,208 @@
+package fuego
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// acceptRange is one element of an Accept or Accept-Encoding header.
+type acceptRange struct {
+	value string // lowercased, without parameters
+	q     float64
+	index int // position in the header, to break ties
+}
+
+// parseAcceptRanges parses an Accept-* header with its quality values (RFC 9110 §12.4.2).
+// Elements with an invalid quality value are ignored.
+func parseAcceptRanges(header string) []acceptRange {
+	var ranges []acceptRange
+	for i, element := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(element, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		q, valid := 1.0, true
+		for _, param := range strings.Split(params, ";") {
+			name, raw, ok := strings.Cut(param, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				valid = false
+				break
+			}
+			q = parsed
+		}
+		if valid {
+			ranges = append(ranges, acceptRange{value: value, q: q, index: i})
+		}
+	}
+	return ranges
+}
+
+// matchMediaRange returns the specificity of a media range for a media type:
+// 4 for an exact match, 3 for the structured syntax suffix of the range (RFC 6839),
+// like application/vnd.acme.v2+json for application/json, 2 for type/*, 1 for */*.
+func matchMediaRange(mediaRange, mediaType string) (int, bool) {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case mediaRange == mediaType:
+		return 4, true
+	case suffixMediaType(mediaRange) == mediaType:
+		return 3, true
+	case mediaRange == "*/*" || mediaRange == "*":
+		return 1, true
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1]):
+		return 2, true
+	}
+	return 0, false
+}
+
+// suffixMediaType returns the media type of the structured syntax suffix of a media range,
+// like application/json for application/vnd.acme.v2+json, or "" if it has none.
+func suffixMediaType(mediaRange string) string {
+	mediaType, subtype, ok := strings.Cut(mediaRange, "/")
+	i := strings.LastIndex(subtype, "+")
+	if !ok || i < 0 || i == len(subtype)-1 {
+		return ""
+	}
+	return mediaType + "/" + subtype[i+1:]
+}
+
+// matchToken returns the specificity of a content coding range for a token:
+// 2 for an exact match, 1 for *.
+func matchToken(tokenRange, token string) (int, bool) {
+	switch {
+	case strings.EqualFold(tokenRange, token):
+		return 2, true
+	case tokenRange == "*":
+		return 1, true
+	}
+	return 0, false
+}
+
+// rankOffers returns the acceptable offers, best first.
+// Each offer gets the quality of its most specific matching range, and offers with q=0 are excluded.
+// Ties are broken by specificity, then by the order of the header, then by the order of the offers.
+// Without any range, all offers are acceptable in their original order.
+func rankOffers(ranges []acceptRange, offers []string, match func(string, string) (int, bool)) []string {
+	if len(ranges) == 0 {
+		return slices.Clone(offers)
+	}
+
+	type candidate struct {
+		offer       string
+		q           float64
+		specificity int
+		index       int
+	}
+
+	candidates := make([]candidate, 0, len(offers))
+	for _, offer := range offers {
+		best := candidate{offer: offer, specificity: -1}
+		for _, r := range ranges {
+			specificity, ok := match(r.value, offer)
+			if ok && specificity > best.specificity {
+				best.q, best.specificity, best.index = r.q, specificity, r.index
+			}
+		}
+		if best.specificity >= 0 && best.q > 0 {
+			candidates = append(candidates, best)
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(b.q, a.q),
+			cmp.Compare(b.specificity, a.specificity),
+			cmp.Compare(a.index, b.index),
+		)
+	})
+
+	ranked := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if !slices.Contains(ranked, c.offer) {
+			ranked = append(ranked, c.offer)
+		}
+	}
+	return ranked
+}
+
+// NegotiateContentType returns the offered media type that best matches the Accept header
+// (RFC 9110 §12.5.1), or an empty string if none is acceptable.
+// Media ranges are ranked by quality value, then by specificity (type/subtype, type/*, */*).
+// On equal terms, the first offer wins: list the offers by order of preference.
+func NegotiateContentType(accept string, offers []string) string {
+	return bestOffer(acceptableContentTypes(accept, offers))
+}
+
+// NegotiateEncoding returns the offered content coding that best matches the Accept-Encoding header
+// (RFC 9110 §12.5.3), or an empty string if none is acceptable.
+// "identity" is acceptable unless explicitly excluded with "identity;q=0" or "*;q=0",
+// but any other acceptable coding is preferred.
+func NegotiateEncoding(acceptEncoding string, offers []string) string {
+	ranges := parseAcceptRanges(acceptEncoding)
+	if !slices.ContainsFunc(ranges, func(r acceptRange) bool { return r.value == "identity" || r.value == "*" }) {
+		ranges = append(ranges, acceptRange{value: "identity", q: 0.001, index: len(ranges)})
+	}
+	return bestOffer(rankOffers(ranges, offers, matchToken))
+}
+
+// acceptableContentTypes returns the offered media types acceptable for the Accept header, best first.
+func acceptableContentTypes(accept string, offers []string) []string {
+	return rankOffers(parseAcceptRanges(accept), offers, matchMediaRange)
+}
+
+// acceptsExplicitly reports whether the Accept header names the media type, or a type with its suffix, without a wildcard.
+func acceptsExplicitly(accept, mediaType string) bool {
+	return slices.ContainsFunc(parseAcceptRanges(accept), func(r acceptRange) bool {
+		specificity, _ := matchMediaRange(r.value, mediaType)
+		return specificity >= 3 && r.q > 0
+	})
+}
+
+func bestOffer(ranked []string) string {
+	if len(ranked) == 0 {
+		return ""
+	}
+	return ranked[0]
+}
+
+// AddVary adds a header name to the Vary response header, unless already present or if Vary is "*".
+// Useful for middlewares whose response depends on a request header, like compression.
+func AddVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "*" || strings.EqualFold(field, name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
+
+// notAcceptable returns the 406 error listing the acceptable content types.
+func notAcceptable(accept string, offers []string) NotAcceptableError {
+	acceptable := make([]string, 0, len(offers))
+	for _, offer := range offers {
+		if !slices.Contains(acceptable, offer) {
+			acceptable = append(acceptable, offer)
+		}
+	}
+	return NotAcceptableError{
+		Detail: "no supported Accept header was provided from: " + accept + ". Acceptable content types: " + strings.Join(acceptable, ", "),
+		Errors: []ErrorItem{{
+			Name:   "Accept",
+			Reason: "not acceptable",
+			More:   map[string]any{"acceptable": acceptable},
+		}},
+	}
+}
@@ -0,0 +1,163 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "text/html"}
+
+	tcs := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{name: "empty header", accept: "", expected: "application/json"},
+		{name: "any", accept: "*/*", expected: "application/json"},
+		{name: "exact", accept: "text/html", expected: "text/html"},
+		{name: "case insensitive", accept: "Text/HTML", expected: "text/html"},
+		{name: "quality values", accept: "application/json;q=0.1, application/xml", expected: "application/xml"},
+		{name: "header order on equal quality", accept: "application/xml, application/json", expected: "application/xml"},
+		{name: "specificity over header order", accept: "*/*, text/html", expected: "text/html"},
+		{name: "type wildcard", accept: "text/*", expected: "text/html"},
+		{name: "most specific range wins", accept: "application/*;q=0.9, application/json;q=0.2", expected: "application/xml"},
+		{name: "q=0 excludes", accept: "*/*, application/json;q=0", expected: "application/xml"},
+		{name: "media type parameters", accept: "application/xml; charset=utf-8;q=0.8, text/plain", expected: "application/xml"},
+		{name: "invalid quality value is ignored", accept: "text/html;q=2, application/xml", expected: "application/xml"},
+		{name: "structured syntax suffix", accept: "application/vnd.acme.v2+json", expected: "application/json"},
+		{name: "exact match over suffix", accept: "application/vnd.acme+xml, application/json;q=0.5, application/xml;q=0.1", expected: "application/json"},
+		{name: "nothing acceptable", accept: "image/png", expected: ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, NegotiateContentType(tc.accept, offers))
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	offers := []string{"br", "gzip", "identity"}
+
+	tcs := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{name: "empty header", accept: "", expected: "identity"},
+		{name: "gzip", accept: "gzip, deflate", expected: "gzip"},
+		{name: "quality values", accept: "gzip;q=0.5, br", expected: "br"},
+		{name: "any", accept: "*", expected: "br"},
+		{name: "identity is implicitly acceptable", accept: "zstd", expected: "identity"},
+		{name: "identity excluded", accept: "zstd, identity;q=0", expected: ""},
+		{name: "everything excluded", accept: "*;q=0", expected: ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, NegotiateEncoding(tc.accept, offers))
+		})
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	header := http.Header{}
+	AddVary(header, "Accept")
+	AddVary(header, "accept")
+	AddVary(header, "Accept-Encoding")
+	require.Equal(t, []string{"Accept", "Accept-Encoding"}, header.Values("Vary"))
+
+	header = http.Header{"Vary": []string{"*"}}
+	AddVary(header, "Accept")
+	require.Equal(t, []string{"*"}, header.Values("Vary"))
+}
+
+func TestSendNegotiation(t *testing.T) {
+	t.Run("uses quality values", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "application/json;q=0.1, application/xml")
+
+		err := Send(w, r, ans{Ans: "Hello World"})
+		require.NoError(t, err)
+		require.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+		require.Equal(t, "Accept", w.Header().Get("Vary"))
+	})
+
+	t.Run("not acceptable lists the acceptable types", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "image/png")
+
+		err := Send(w, r, ans{Ans: "Hello World"})
+		var notAcceptableError NotAcceptableError
+		require.ErrorAs(t, err, &notAcceptableError)
+		assert.Contains(t, notAcceptableError.Detail, "application/json, application/xml")
+		require.Len(t, notAcceptableError.Errors, 1)
+		assert.Equal(t, "Accept", notAcceptableError.Errors[0].Name)
+	})
+
+	t.Run("errors use quality values", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "application/json;q=0.5, application/xml")
+
+		SendError(w, r, NotFoundError{Title: "Not Found"})
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+		require.Equal(t, "Accept", w.Header().Get("Vary"))
+	})
+}
+
+func TestRouteResponseContentTypes(t *testing.T) {
+	s := NewServer(WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})))
+	Get(s, "/json", func(c ContextNoBody) (ans, error) {
+		return ans{Ans: "Hello World"}, nil
+	}, OptionResponseContentType("application/json"))
+	Get(s, "/json/fail", func(c ContextNoBody) (ans, error) {
+		return ans{}, NotFoundError{Title: "Not Found"}
+	}, OptionResponseContentType("application/json"))
+	Get(s, "/any", func(c ContextNoBody) (ans, error) {
+		return ans{Ans: "Hello World"}, nil
+	})
+
+	request := func(path, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("restricted route refuses other content types", func(t *testing.T) {
+		for _, accept := range []string{"application/xml", "application/x-yaml", "text/html"} {
+			w := request("/json", accept)
+			require.Equal(t, http.StatusNotAcceptable, w.Code, accept)
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			require.Contains(t, w.Body.String(), "Acceptable content types: application/json")
+		}
+	})
+
+	t.Run("restricted route sends its content types", func(t *testing.T) {
+		w := request("/json", "application/xml;q=0.9, */*;q=0.1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("errors of a restricted route", func(t *testing.T) {
+		w := request("/json/fail", "application/xml, application/json;q=0.5")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("other routes negotiate all the content types", func(t *testing.T) {
+		w := request("/any", "application/x-yaml")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/x-yaml", w.Header().Get("Content-Type"))
+	})
+}
@@ -88,7 +88,22 @@ func Register[T, B, P any](s *Server, route Route[T, B, P], controller http.Hand
 	slog.Debug("registering controller " + fullPath)
 
 	route.Middlewares = append(s.middlewares, route.Middlewares...)
-	s.Mux.Handle(fullPath, withMiddlewares(controller, route.Middlewares...))
+	handler := withMiddlewares(controller, route.Middlewares...)
+	if route.CORS != nil {
+		handler = corsMiddleware(route.CORS, route.Operation)(handler)
+	}
+	if s.metrics != nil && !route.internal {
+		handler = s.metrics.middleware(route.Method, route.Path)(handler)
+	}
+
+	if s.corsRoutes != nil && s.corsRoutes.register(s.Mux, route.Method, route.Path, route.CORS, handler) {
+		return &route
+	}
+	if s.version != nil && s.versionRoutes != nil {
+		s.versionRoutes.register(s.Mux, fullPath, s.version.Name, handler)
+		return &route
+	}
+	s.Mux.Handle(fullPath, handler)
 
 	return &route
 }
@@ -101,15 +116,22 @@ func Use(s *Server, middlewares ...func(http.Handler) http.Handler) {
 	s.middlewares = append(s.middlewares, middlewares...)
 }
 
-// Handle registers a standard HTTP handler into the default mux.
+// Handle registers a standard HTTP handler into the default mux, for all methods.
 // Use this function if you want to use a standard HTTP handler instead of a Fuego controller.
+// The handler is not documented in the OpenAPI spec, but is listed by [Engine.Routes].
 func Handle(s *Server, path string, controller http.Handler, options ...RouteOption) *Route[any, any, any] {
-	return Register(s, Route[any, any, any]{
+	route := Register(s, Route[any, any, any]{
 		BaseRoute: BaseRoute{
 			Path:     path,
 			FullName: FuncName(controller),
 		},
 	}, controller)
+
+	route.Hidden = true
+	route.Adaptor = adaptorName(s)
+	s.Engine.registerRoute(route.BaseRoute)
+
+	return route
 }
 
 func AllStd(s *Server, path string, controller func(http.ResponseWriter, *http.Request), options ...RouteOption) *Route[any, any, any] {
@@ -154,10 +176,25 @@ func registerFuegoController[T, B, P any](s *Server, method, path string, contro
 func registerStdController(s *Server, method, path string, controller func(http.ResponseWriter, *http.Request), options ...RouteOption) *Route[any, any, any] {
 	route := NewRoute[any, any, any](method, path, controller, s.Engine, append(s.routeOptions, options...)...)
 
+	var handler http.Handler = http.HandlerFunc(controller)
+	if route.Timeout > 0 {
+		_, errorSerializer := s.serializers(route.BaseRoute)
+		errorHandler := s.errorHandler(route.BaseRoute)
+		handler = withTimeout(handler, route.Timeout, route.timeoutStatusCode(), func(w http.ResponseWriter, r *http.Request, err error) {
+			observeError(r.Context(), err)
+			err = errorHandler(r.Context(), err)
+			if errorSerializer != nil {
+				errorSerializer(w, r, err)
+				return
+			}
+			SendError(w, r, err)
+		})
+	}
+
 	return Registers(s.Engine, netHttpRouteRegisterer[any, any, any]{
 		s:          s,
 		route:      route,
-		controller: http.HandlerFunc(controller),
+		controller: handler,
 	})
 }
 
@@ -1,7 +1,9 @@
 package fuego
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -789,6 +791,136 @@ func Test_GroupRouteMiddleware(t *testing.T) {
 	})
 }
 
+func Test_GroupSettings(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	s := NewServer()
+	admin := Group(s, "/admin",
+		OptionErrorHandler(func(ctx context.Context, err error) error {
+			return ForbiddenError{Title: "Admin error", Err: err}
+		}),
+		OptionErrorSerializer(func(w http.ResponseWriter, r *http.Request, err error) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("<p>" + err.Error() + "</p>"))
+		}),
+		OptionSerializer(func(w http.ResponseWriter, r *http.Request, ans any) error {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, err := fmt.Fprintf(w, "<p>%v</p>", ans)
+			return err
+		}),
+		OptionResponseContentType("text/html"),
+		OptionMaxBodySize(32),
+		OptionDisallowUnknownFields(false),
+	)
+
+	controller := func(c ContextWithBody[payload]) (string, error) {
+		body, err := c.Body()
+		if err != nil {
+			return "", err
+		}
+		return body.Name, nil
+	}
+	failing := func(c ContextNoBody) (string, error) {
+		return "", errors.New("boom")
+	}
+	Post(admin, "/echo", controller)
+	Get(admin, "/fail", failing)
+	Post(s, "/echo", controller)
+	Get(s, "/fail", failing)
+
+	pages := Group(s, "/pages", OptionResponseContentType("text/html"))
+	Get(pages, "/home", func(c ContextNoBody) (string, error) {
+		return "<h1>Home</h1>", nil
+	})
+	Get(pages, "/fail", failing)
+
+	t.Run("group serializer", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/admin/echo", strings.NewReader(`{"name":"a","other":1}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code, "unknown fields are allowed in the group")
+		require.Equal(t, "<p>a</p>", w.Body.String())
+	})
+
+	t.Run("group max body size", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/admin/echo", strings.NewReader(`{"name":"a name longer than the limit"}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "<p>")
+	})
+
+	t.Run("group error handler and error serializer", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/admin/fail", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), "Admin error")
+	})
+
+	t.Run("server settings outside of the group", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"name":"a","other":1}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		r = httptest.NewRequest(http.MethodGet, "/fail", nil)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("group response content types are negotiated", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/pages/home", nil)
+		r.Header.Set("Accept", "*/*")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "<h1>Home</h1>", w.Body.String())
+
+		r = httptest.NewRequest(http.MethodGet, "/pages/home", nil)
+		r.Header.Set("Accept", "application/json")
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotAcceptable, w.Code)
+
+		r = httptest.NewRequest(http.MethodGet, "/pages/fail", nil)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+		r = httptest.NewRequest(http.MethodPost, "/admin/echo", strings.NewReader(`{"name":"a"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/json")
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code, "not acceptable, through the group error handler")
+		require.Contains(t, w.Body.String(), "<p>")
+	})
+
+	t.Run("group response content types are documented", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/admin/fail").Get
+		require.NotNil(t, operation.Responses.Value("200").Value.Content.Get("text/html"))
+		require.Nil(t, operation.Responses.Value("200").Value.Content.Get("application/json"))
+
+		operation = s.OpenAPI.Description().Paths.Find("/fail").Get
+		require.NotNil(t, operation.Responses.Value("200").Value.Content.Get("application/json"))
+	})
+}
+
 func ExampleContextNoBody_SetCookie() {
 	s := NewServer()
 	Get(s, "/test", func(c ContextNoBody) (string, error) {
@@ -3,6 +3,7 @@ package fuego
 import (
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
 	"reflect"
 	"regexp"
@@ -196,11 +197,16 @@ func RegisterOpenAPIOperation[T, B, P any](openapi *OpenAPI, route Route[T, B, P
 	}
 
 	// Request Body
+	if patch, ok := any(new(B)).(patchBody); ok && route.Operation.RequestBody == nil {
+		route.Operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: patch.openAPIRequestBody(openapi),
+		}
+	}
 	if route.Operation.RequestBody == nil {
 		bodyTag := SchemaTagFromType(openapi, *new(B))
 
 		if bodyTag.Name != "unknown-interface" {
-			requestBody := newRequestBody[B](bodyTag, route.RequestContentTypes)
+			requestBody := newRequestBody[B](bodyTag, withCSVContentType(withSerDesContentTypes(route.RequestContentTypes, route.contentTypeSerDes), reflect.TypeFor[B]()))
 
 			// add request body to operation
 			route.Operation.RequestBody = &openapi3.RequestBodyRef{
@@ -231,14 +237,41 @@ func RegisterOpenAPIOperation[T, B, P any](openapi *OpenAPI, route Route[T, B, P
 		route.Operation.AddResponse(route.DefaultStatusCode, response)
 		responseDefault = route.Operation.Responses.Value(defaultStatusCode)
 	}
+	if len(route.defaultStatusCodeHeaders) > 0 && responseDefault.Value.Headers == nil {
+		responseDefault.Value.Headers = openapi3.Headers{}
+	}
+	maps.Copy(responseDefault.Value.Headers, route.defaultStatusCodeHeaders)
 
 	// Automatically add non-declared Content for 200 (or other) Response
 	if responseDefault.Value.Content == nil {
 		responseSchema := SchemaTagFromType(openapi, *new(T))
-		content := openapi3.NewContentWithSchemaRef(&responseSchema.SchemaRef, route.ResponseContentTypes)
+		responseContentTypes := withCSVContentType(withSerDesContentTypes(route.ResponseContentTypes, route.contentTypeSerDes), reflect.TypeFor[T]())
+		content := openapi3.NewContentWithSchemaRef(&responseSchema.SchemaRef, responseContentTypes)
+		if _, ok := streamElemType(reflect.TypeFor[T]()); ok {
+			content = newStreamContent(responseSchema)
+			if _, ok := csvElemType(reflect.TypeFor[T]()); ok {
+				content[ContentTypeCSV] = openapi3.NewMediaType().WithSchemaRef(&responseSchema.SchemaRef)
+			}
+		}
+		if isBinaryType(reflect.TypeFor[T]()) {
+			content = openapi3.NewContentWithSchema(newBinarySchema(), []string{"application/octet-stream"})
+		}
 		responseDefault.Value.WithContent(content)
 	}
 
+	if route.ETag {
+		documentETag(openapi, &route.BaseRoute)
+	}
+
+	if route.Deprecation != nil {
+		documentDeprecation(&route.BaseRoute)
+	}
+
+	if route.Timeout > 0 {
+		status := route.timeoutStatusCode()
+		addResponseIfNotSet(openapi, route.Operation, status, http.StatusText(status)+": the request timed out", Response{Type: HTTPError{}})
+	}
+
 	// Automatically add non-declared Path parameters
 	for _, pathParam := range parsePathParams(route.Path) {
 		if exists := route.Operation.Parameters.GetByInAndName("path", pathParam); exists != nil {
@@ -413,6 +446,24 @@ func parseDefaultValueArray(defaultStr string, elemKind reflect.Kind) (any, erro
 	return result, nil
 }
 
+// withSerDesContentTypes adds the content types handled by custom SerDes to the given content types.
+// Empty content types mean "*/*", kept next to the ones of the SerDes.
+func withSerDesContentTypes(contentTypes []string, serdes map[string]SerDes) []string {
+	if len(serdes) == 0 {
+		return contentTypes
+	}
+	result := slices.Clone(contentTypes)
+	if len(result) == 0 {
+		result = []string{"*/*"}
+	}
+	for _, contentType := range slices.Sorted(maps.Keys(serdes)) {
+		if !slices.Contains(result, contentType) {
+			result = append(result, contentType)
+		}
+	}
+	return result
+}
+
 func newRequestBody[RequestBody any](tag SchemaTag, consumes []string) *openapi3.RequestBody {
 	content := openapi3.NewContentWithSchemaRef(&tag.SchemaRef, consumes)
 	return openapi3.NewRequestBody().
@@ -460,16 +511,24 @@ func dive(openapi *OpenAPI, t reflect.Type, tag SchemaTag, maxDepth int) SchemaT
 		}
 	}
 
+	// Readers, files and []byte are documented as binary strings
+	if isBinaryType(t) {
+		tag.Name = "binary"
+		tag.Value = newBinarySchema()
+		return tag
+	}
+
+	// Streams (iter.Seq, iter.Seq2[T, error] and channels) are documented as arrays of their elements
+	if elemType, ok := streamElemType(t); ok {
+		return diveArray(openapi, elemType, tag, maxDepth)
+	}
+
 	switch t.Kind() {
 	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
 		return dive(openapi, t.Elem(), tag, maxDepth-1)
 
 	case reflect.Slice, reflect.Array:
-		item := dive(openapi, t.Elem(), tag, maxDepth-1)
-		tag.Name = item.Name
-		tag.Value = openapi3.NewArraySchema()
-		tag.Value.Items = &item.SchemaRef
-		return tag
+		return diveArray(openapi, t.Elem(), tag, maxDepth)
 
 	default:
 		tag.Name = transformTypeName(t.Name())
@@ -483,6 +542,15 @@ func dive(openapi *OpenAPI, t reflect.Type, tag SchemaTag, maxDepth int) SchemaT
 	}
 }
 
+// diveArray builds an array schema whose items are the schema of the given element type.
+func diveArray(openapi *OpenAPI, elemType reflect.Type, tag SchemaTag, maxDepth int) SchemaTag {
+	item := dive(openapi, elemType, tag, maxDepth-1)
+	tag.Name = item.Name
+	tag.Value = openapi3.NewArraySchema()
+	tag.Value.Items = &item.SchemaRef
+	return tag
+}
+
 // getOrCreateSchema is used to get a schema from the OpenAPI spec.
 // If the schema does not exist, it will create a new schema and add it to the OpenAPI spec.
 func (openAPI *OpenAPI) getOrCreateSchema(key string, v any) *openapi3.Schema {
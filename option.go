@@ -1,6 +1,7 @@
 package fuego
 
 import (
+	"context"
 	"fmt"
 	"maps"
 	"net/http"
@@ -224,11 +225,21 @@ func OptionResponseHeader(name, description string, options ...ParamOption) Rout
 	openapiParam.Name = ""
 	openapiParam.In = ""
 
-	if len(apiParam.StatusCodes) == 0 {
+	if len(apiParam.StatusCodes) == 0 && !apiParam.DefaultStatusCode {
 		apiParam.StatusCodes = []int{200}
 	}
 
 	return func(r *BaseRoute) {
+		if apiParam.DefaultStatusCode {
+			if r.defaultStatusCodeHeaders == nil {
+				r.defaultStatusCodeHeaders = openapi3.Headers{}
+			}
+			r.defaultStatusCodeHeaders[name] = &openapi3.HeaderRef{
+				Value: &openapi3.Header{
+					Parameter: *openapiParam,
+				},
+			}
+		}
 		for _, code := range apiParam.StatusCodes {
 			codeString := strconv.Itoa(code)
 			responseForCurrentCode := r.Operation.Responses.Value(codeString)
@@ -383,7 +394,16 @@ func OptionOperationID(operationID string) RouteOption {
 	}
 }
 
-// OptionDeprecated marks the route as deprecated.
+// OptionName names the route, to build its URL with [Engine.URLFor], [Context.URLFor]
+// or the urlFor template function. By default, routes are named after their operation ID.
+func OptionName(name string) RouteOption {
+	return func(r *BaseRoute) {
+		r.Name = name
+	}
+}
+
+// OptionDeprecated marks the route as deprecated in the OpenAPI spec.
+// To also signal the deprecation to the clients at runtime, use [OptionDeprecation].
 func OptionDeprecated() RouteOption {
 	return func(r *BaseRoute) {
 		r.Operation.Deprecated = true
@@ -499,6 +519,62 @@ func OptionRequestContentType(consumes ...string) RouteOption {
 	}
 }
 
+// OptionResponseContentType sets the content types of the responses of the route, or of all the routes of a group.
+// They are negotiated with the Accept header of the request, along with the content types of the SerDes,
+// and documented in the OpenAPI spec. Requests accepting none of them get a 406 Not Acceptable error.
+// This will override any options set at the server level.
+func OptionResponseContentType(produces ...string) RouteOption {
+	return func(r *BaseRoute) {
+		r.ResponseContentTypes = produces
+		r.restrictResponseContentTypes = true
+	}
+}
+
+// OptionErrorHandler sets the error handler of the route, or of all the routes of a group,
+// instead of the one of the engine set with [WithErrorHandler].
+// This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
+func OptionErrorHandler(errorHandler func(ctx context.Context, err error) error) RouteOption {
+	return func(r *BaseRoute) {
+		r.ErrorHandler = errorHandler
+	}
+}
+
+// OptionSerializer sets the serializer of the responses of the route, or of all the routes of a group,
+// instead of the one of the server set with [WithSerializer].
+// This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
+func OptionSerializer(serializer Sender) RouteOption {
+	return func(r *BaseRoute) {
+		r.Serializer = serializer
+	}
+}
+
+// OptionErrorSerializer sets the serializer of the errors of the route, or of all the routes of a group,
+// instead of the one of the server set with [WithErrorSerializer].
+// This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
+func OptionErrorSerializer(serializer ErrorSender) RouteOption {
+	return func(r *BaseRoute) {
+		r.ErrorSerializer = serializer
+	}
+}
+
+// OptionMaxBodySize limits the size of the request body of the route, or of all the routes of a group,
+// instead of the limit of the server set with [WithMaxBodySize]. 0 means no limit.
+// This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
+func OptionMaxBodySize(maxBodySize int64) RouteOption {
+	return func(r *BaseRoute) {
+		r.MaxBodySize = &maxBodySize
+	}
+}
+
+// OptionDisallowUnknownFields rejects request bodies with unknown fields on the route, or on all the routes of a group,
+// instead of the setting of the server set with [WithDisallowUnknownFields].
+// This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
+func OptionDisallowUnknownFields(disallow bool) RouteOption {
+	return func(r *BaseRoute) {
+		r.DisallowUnknownFields = &disallow
+	}
+}
+
 // OptionHide hides the route from the OpenAPI spec.
 func OptionHide() RouteOption {
 	return func(r *BaseRoute) {
@@ -506,6 +582,13 @@ func OptionHide() RouteOption {
 	}
 }
 
+// optionInternal marks the endpoints of Fuego itself, like the metrics, health and OpenAPI endpoints.
+func optionInternal() RouteOption {
+	return func(r *BaseRoute) {
+		r.internal = true
+	}
+}
+
 // OptionShow shows the route from the OpenAPI spec.
 func OptionShow() RouteOption {
 	return func(r *BaseRoute) {
@@ -160,9 +160,17 @@ var Security = fuego.OptionSecurity
 // OperationID adds an operation ID to the route.
 var OperationID = fuego.OptionOperationID
 
+// Name names the route, to build its URL with [fuego.Engine.URLFor].
+// See [fuego.OptionName].
+var Name = fuego.OptionName
+
 // Deprecated marks the route as deprecated.
 var Deprecated = fuego.OptionDeprecated
 
+// Deprecation marks the route as deprecated, and signals it to the clients with the Deprecation, Sunset and Link headers.
+// See [fuego.OptionDeprecation].
+var Deprecation = fuego.OptionDeprecation
+
 // AddError adds an error to the route.
 //
 // Deprecated: Use [AddResponse] instead.
@@ -192,6 +200,30 @@ var DefaultResponse = fuego.OptionDefaultResponse
 // This will override any options set at the server level.
 var RequestContentType = fuego.OptionRequestContentType
 
+// ResponseContentType sets the content types of the responses of the route, or of all the routes of a group.
+// See [fuego.OptionResponseContentType].
+var ResponseContentType = fuego.OptionResponseContentType
+
+// ErrorHandler sets the error handler of the route, or of all the routes of a group.
+// See [fuego.OptionErrorHandler].
+var ErrorHandler = fuego.OptionErrorHandler
+
+// Serializer sets the serializer of the responses of the route, or of all the routes of a group.
+// See [fuego.OptionSerializer].
+var Serializer = fuego.OptionSerializer
+
+// ErrorSerializer sets the serializer of the errors of the route, or of all the routes of a group.
+// See [fuego.OptionErrorSerializer].
+var ErrorSerializer = fuego.OptionErrorSerializer
+
+// MaxBodySize limits the size of the request body of the route, or of all the routes of a group.
+// See [fuego.OptionMaxBodySize].
+var MaxBodySize = fuego.OptionMaxBodySize
+
+// DisallowUnknownFields rejects request bodies with unknown fields on the route, or on all the routes of a group.
+// See [fuego.OptionDisallowUnknownFields].
+var DisallowUnknownFields = fuego.OptionDisallowUnknownFields
+
 // Hide hides the route from the OpenAPI spec.
 var Hide = fuego.OptionHide
 
@@ -205,6 +237,22 @@ var DefaultStatusCode = fuego.OptionDefaultStatusCode
 // By default, the trailing slash is kept, so becauseful when registering route like "/" within a group.
 var StripTrailingSlash = fuego.OptionStripTrailingSlash
 
+// ETag enables ETags and conditional requests (If-None-Match, If-Match) on the route.
+// See [fuego.OptionETag].
+var ETag = fuego.OptionETag
+
+// IfMatch enables ETags on the route, and checks the If-Match header against the current ETag of the resource
+// before the controller. See [fuego.OptionIfMatch].
+var IfMatch = fuego.OptionIfMatch
+
+// Timeout sets a deadline to the requests of the route, or of all the routes of a group.
+// See [fuego.OptionTimeout].
+var Timeout = fuego.OptionTimeout
+
+// CORS sets the Cross-Origin Resource Sharing policy of the route, or of all the routes of a group.
+// See [fuego.OptionCORS].
+var CORS = fuego.OptionCORS
+
 // WithContentTypeSerDes sets a custom serializer and deserializer for a content type.
 // This option is currently only applicable to the [fuego.Server]. Other adaptors are not affected by this option.
 var WithContentTypeSerDes = fuego.OptionWithContentTypeSerDes
@@ -656,6 +656,19 @@ func TestOptionResponseHeader(t *testing.T) {
 		require.NotNil(t, route.Operation.Responses.Value("206").Value.Headers["X-Test"])
 		require.Nil(t, route.Operation.Responses.Value("400").Value.Headers["X-Test"])
 	})
+
+	t.Run("Declare a response header for the default status code set after the option", func(t *testing.T) {
+		s := fuego.NewServer()
+
+		route := fuego.Post(s, "/test", helloWorld,
+			fuego.OptionResponseHeader("X-Test", "test header", param.DefaultStatusCode(), param.StatusCodes(429)),
+			fuego.OptionDefaultStatusCode(201),
+		)
+
+		require.NotNil(t, route.Operation.Responses.Value("201").Value.Headers["X-Test"])
+		require.NotNil(t, route.Operation.Responses.Value("429").Value.Headers["X-Test"])
+		require.Nil(t, route.Operation.Responses.Value("200"))
+	})
 }
 
 func TestSecurity(t *testing.T) {
@@ -64,3 +64,12 @@ func ParamStatusCodes(codes ...int) ParamOption {
 		param.StatusCodes = codes
 	}
 }
+
+// ParamDefaultStatusCode sets the parameter as required for the default status code of the route,
+// as set with [OptionDefaultStatusCode], even by an option applied after this one.
+// Can be combined with [ParamStatusCodes]. Only used for response parameters.
+func ParamDefaultStatusCode() ParamOption {
+	return func(param *OpenAPIParam) {
+		param.DefaultStatusCode = true
+	}
+}
@@ -35,3 +35,8 @@ var Example = fuego.ParamExample
 // Only used for response parameters.
 // If empty, it is required for 200 status codes.
 var StatusCodes = fuego.ParamStatusCodes
+
+// DefaultStatusCode sets the parameter as required for the default status code of the route,
+// even if it is set by an option applied after this one.
+// Only used for response parameters.
+var DefaultStatusCode = fuego.ParamDefaultStatusCode
@@ -0,0 +1,623 @@
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// ContentTypeMergePatch is the content type of a JSON Merge Patch document (RFC 7396).
+	ContentTypeMergePatch = "application/merge-patch+json"
+	// ContentTypeJSONPatch is the content type of a JSON Patch document (RFC 6902).
+	ContentTypeJSONPatch = "application/json-patch+json"
+)
+
+// patchBody is implemented by the patch body types, [MergePatch] and [JSONPatch].
+type patchBody interface {
+	// contentType returns the content type of the patch document.
+	contentType() string
+	// check returns an error if the patch document is invalid.
+	check() error
+	// setReadOptions keeps the request context and read options, used by Apply.
+	setReadOptions(ctx context.Context, options readOptions)
+	// openAPIRequestBody documents the patch document.
+	openAPIRequestBody(openapi *OpenAPI) *openapi3.RequestBody
+}
+
+// patchContext is the context in which a patch is applied.
+type patchContext struct {
+	ctx     context.Context
+	options readOptions
+}
+
+func (p *patchContext) setReadOptions(ctx context.Context, options readOptions) {
+	p.ctx = ctx
+	p.options = options
+}
+
+func (p patchContext) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
+// codec returns the JSON codec of the request, see [WithJSONCodec].
+func (p patchContext) codec() JSONCodec {
+	return jsonCodecOrDefault(p.options.JSONCodec)
+}
+
+// MergePatch is a JSON Merge Patch (RFC 7396) request body for a T.
+// Unlike a T body, it distinguishes absent fields (left unchanged) from fields set to null (removed).
+// It is read from "application/merge-patch+json" request bodies.
+//
+//	fuego.Patch(s, "/pets/{id}", func(c fuego.ContextWithBody[fuego.MergePatch[Pet]]) (Pet, error) {
+//		patch, err := c.Body()
+//		if err != nil {
+//			return Pet{}, err
+//		}
+//		pet, err := store.GetPet(c.PathParam("id"))
+//		if err != nil {
+//			return Pet{}, err
+//		}
+//		pet, err = patch.Apply(pet)
+//		if err != nil {
+//			return Pet{}, err
+//		}
+//		return store.UpdatePet(pet)
+//	})
+type MergePatch[T any] struct {
+	patchContext
+	document json.RawMessage
+}
+
+var _ patchBody = &MergePatch[struct{}]{}
+
+// UnmarshalJSON reads the merge patch document.
+func (p *MergePatch[T]) UnmarshalJSON(data []byte) error {
+	p.document = slices.Clone(data)
+	return nil
+}
+
+func (p *MergePatch[T]) check() error {
+	kind := reflect.TypeFor[T]().Kind()
+	if (kind == reflect.Struct || kind == reflect.Map) && !bytes.HasPrefix(bytes.TrimSpace(p.document), []byte("{")) {
+		return BadRequestError{
+			Title:  "Invalid Merge Patch",
+			Detail: "a merge patch for " + reflect.TypeFor[T]().String() + " must be a JSON object",
+		}
+	}
+	return nil
+}
+
+// MarshalJSON returns the merge patch document.
+func (p MergePatch[T]) MarshalJSON() ([]byte, error) {
+	if p.document == nil {
+		return []byte("{}"), nil
+	}
+	return p.document, nil
+}
+
+// Apply applies the patch to the original value, then transforms and validates the result
+// like a request body (see [TransformAndValidate]).
+// The original value is not modified.
+func (p MergePatch[T]) Apply(original T) (T, error) {
+	var patch any
+	if err := decodeJSONDocument(p.codec(), p.document, &patch); err != nil {
+		return original, BadRequestError{
+			Title:  "Invalid Merge Patch",
+			Err:    err,
+			Detail: "cannot decode merge patch: " + err.Error(),
+		}
+	}
+
+	document, err := toJSONDocument(p.codec(), original)
+	if err != nil {
+		return original, err
+	}
+
+	return fromJSONDocument(p.patchContext, mergePatch(document, patch), original)
+}
+
+// mergePatch applies a merge patch to a target, following RFC 7396.
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any, len(patchObject))
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+func (*MergePatch[T]) contentType() string { return ContentTypeMergePatch }
+
+func (*MergePatch[T]) openAPIRequestBody(openapi *OpenAPI) *openapi3.RequestBody {
+	tag := SchemaTagFromType(openapi, *new(T))
+	schemaRef := &tag.SchemaRef
+	if tag.Value != nil && tag.Ref != "" {
+		// All fields are optional in a merge patch
+		name := tag.Name + "MergePatch"
+		if _, ok := openapi.Description().Components.Schemas[name]; !ok {
+			schema := *tag.Value
+			schema.Required = nil
+			schema.Description = "JSON Merge Patch (RFC 7396) for " + tag.Name + ". Absent fields are left unchanged, fields set to null are removed."
+			openapi.Description().Components.Schemas[name] = openapi3.NewSchemaRef("", &schema)
+		}
+		schemaRef = openapi3.NewSchemaRef("#/components/schemas/"+name, openapi.Description().Components.Schemas[name].Value)
+	}
+
+	return openapi3.NewRequestBody().
+		WithRequired(true).
+		WithDescription("JSON Merge Patch (RFC 7396) for " + reflect.TypeFor[T]().String()).
+		WithContent(openapi3.NewContentWithSchemaRef(schemaRef, []string{ContentTypeMergePatch}))
+}
+
+// JSONPatchOperation is an operation of a [JSONPatch] document.
+type JSONPatchOperation struct {
+	// One of "add", "remove", "replace", "move", "copy" or "test".
+	Op string `json:"op"`
+	// JSON Pointer (RFC 6901) to the target location, ex: "/tags/0".
+	Path string `json:"path"`
+	// JSON Pointer to the source location, for "move" and "copy".
+	From string `json:"from,omitempty"`
+	// Value for "add", "replace" and "test". It can be null.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is a JSON Patch (RFC 6902) request body for a T: a list of operations to apply to a T.
+// It is read from "application/json-patch+json" request bodies.
+// See [MergePatch] for an example.
+type JSONPatch[T any] struct {
+	patchContext
+	Operations []JSONPatchOperation
+}
+
+var _ patchBody = &JSONPatch[struct{}]{}
+
+// UnmarshalJSON reads the JSON Patch operations.
+func (p *JSONPatch[T]) UnmarshalJSON(data []byte) error {
+	dec := p.codec().NewDecoder(bytes.NewReader(data), JSONDecoderOptions{DisallowUnknownFields: p.options.DisallowUnknownFields})
+	return dec.Decode(&p.Operations)
+}
+
+func (p *JSONPatch[T]) check() error {
+	var errorItems []ErrorItem
+	for i, operation := range p.Operations {
+		if reason := checkJSONPatchOperation(operation); reason != "" {
+			errorItems = append(errorItems, ErrorItem{
+				Name:   fmt.Sprintf("[%d]", i),
+				Reason: reason,
+				More:   map[string]any{"op": operation.Op, "path": operation.Path},
+			})
+		}
+	}
+	if len(errorItems) > 0 {
+		return BadRequestError{
+			Title:  "Invalid JSON Patch",
+			Detail: "invalid JSON Patch operations",
+			Errors: errorItems,
+		}
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON Patch operations.
+func (p JSONPatch[T]) MarshalJSON() ([]byte, error) {
+	if p.Operations == nil {
+		return []byte("[]"), nil
+	}
+	return p.codec().Marshal(p.Operations)
+}
+
+func checkJSONPatchOperation(operation JSONPatchOperation) string {
+	if _, err := parseJSONPointer(operation.Path); err != nil {
+		return err.Error()
+	}
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return `"value" is required for "` + operation.Op + `"`
+		}
+	case "move", "copy":
+		if _, err := parseJSONPointer(operation.From); err != nil {
+			return `invalid "from": ` + err.Error()
+		}
+	case "remove":
+	default:
+		return "unknown operation " + strconv.Quote(operation.Op)
+	}
+	return ""
+}
+
+// Apply applies the operations to the original value, then transforms and validates the result
+// like a request body (see [TransformAndValidate]).
+// The original value is not modified.
+// If a "test" operation fails, a [ConflictError] is returned.
+func (p JSONPatch[T]) Apply(original T) (T, error) {
+	document, err := toJSONDocument(p.codec(), original)
+	if err != nil {
+		return original, err
+	}
+
+	for i, operation := range p.Operations {
+		document, err = applyJSONPatchOperation(p.codec(), document, operation)
+		if err != nil {
+			errorItem := ErrorItem{
+				Name:   fmt.Sprintf("[%d]", i),
+				Reason: err.Error(),
+				More:   map[string]any{"op": operation.Op, "path": operation.Path},
+			}
+			if errors.Is(err, errJSONPatchTestFailed) {
+				return original, ConflictError{
+					Title:  "JSON Patch Test Failed",
+					Err:    err,
+					Detail: fmt.Sprintf("operation %d: %s", i, err),
+					Errors: []ErrorItem{errorItem},
+				}
+			}
+			return original, HTTPError{
+				Title:  "Invalid JSON Patch",
+				Err:    err,
+				Status: http.StatusUnprocessableEntity,
+				Detail: fmt.Sprintf("cannot apply operation %d: %s", i, err),
+				Errors: []ErrorItem{errorItem},
+			}
+		}
+	}
+
+	return fromJSONDocument(p.patchContext, document, original)
+}
+
+var errJSONPatchTestFailed = errors.New("test failed")
+
+func applyJSONPatchOperation(codec JSONCodec, document any, operation JSONPatchOperation) (any, error) {
+	path, err := parseJSONPointer(operation.Path)
+	if err != nil {
+		return document, err
+	}
+
+	var value any
+	if operation.Value != nil {
+		if err := decodeJSONDocument(codec, operation.Value, &value); err != nil {
+			return document, fmt.Errorf("invalid value: %w", err)
+		}
+	}
+
+	switch operation.Op {
+	case "add":
+		return jsonPointerAdd(document, path, value)
+	case "remove":
+		document, _, err = jsonPointerRemove(document, path)
+		return document, err
+	case "replace":
+		document, _, err = jsonPointerRemove(document, path)
+		if err != nil {
+			return document, err
+		}
+		return jsonPointerAdd(document, path, value)
+	case "move", "copy":
+		from, err := parseJSONPointer(operation.From)
+		if err != nil {
+			return document, err
+		}
+		if operation.Op == "move" {
+			if len(path) > len(from) && slices.Equal(path[:len(from)], from) {
+				return document, errors.New(`cannot move a value into one of its children`)
+			}
+			document, value, err = jsonPointerRemove(document, from)
+		} else {
+			value, err = jsonPointerGet(document, from)
+			if err == nil {
+				value, err = deepCopyJSONDocument(codec, value)
+			}
+		}
+		if err != nil {
+			return document, err
+		}
+		return jsonPointerAdd(document, path, value)
+	case "test":
+		current, err := jsonPointerGet(document, path)
+		if err != nil {
+			return document, err
+		}
+		if !jsonDocumentsEqual(current, value) {
+			return document, fmt.Errorf("%w: value at %s is not %s", errJSONPatchTestFailed, operation.Path, operation.Value)
+		}
+		return document, nil
+	}
+
+	return document, fmt.Errorf("unknown operation %q", operation.Op)
+}
+
+func (*JSONPatch[T]) contentType() string { return ContentTypeJSONPatch }
+
+func (*JSONPatch[T]) openAPIRequestBody(openapi *OpenAPI) *openapi3.RequestBody {
+	const name = "JSONPatchOperation"
+	schemas := openapi.Description().Components.Schemas
+	if _, ok := schemas[name]; !ok {
+		schema := openapi3.NewObjectSchema().
+			WithProperty("op", openapi3.NewStringSchema().WithEnum("add", "remove", "replace", "move", "copy", "test")).
+			WithPropertyRef("path", openapi3.NewSchemaRef("", openapi3.NewStringSchema().WithPattern(`^(/[^/]*)*$`))).
+			WithPropertyRef("from", openapi3.NewSchemaRef("", openapi3.NewStringSchema().WithPattern(`^(/[^/]*)*$`))).
+			WithPropertyRef("value", openapi3.NewSchemaRef("", openapi3.NewSchema()))
+		schema.Required = []string{"op", "path"}
+		schema.Description = "JSON Patch (RFC 6902) operation. path and from are JSON Pointers (RFC 6901), ex: /tags/0."
+		schemas[name] = openapi3.NewSchemaRef("", schema)
+	}
+
+	arraySchema := openapi3.NewArraySchema()
+	arraySchema.Items = openapi3.NewSchemaRef("#/components/schemas/"+name, schemas[name].Value)
+
+	return openapi3.NewRequestBody().
+		WithRequired(true).
+		WithDescription("JSON Patch (RFC 6902) for " + reflect.TypeFor[T]().String()).
+		WithContent(openapi3.NewContentWithSchema(arraySchema, []string{ContentTypeJSONPatch}))
+}
+
+// decodeJSONDocument decodes JSON into a generic document, keeping numbers as [json.Number]
+// if the codec supports [JSONDecoderOptions.UseNumber].
+func decodeJSONDocument(codec JSONCodec, data []byte, v *any) error {
+	return codec.NewDecoder(bytes.NewReader(data), JSONDecoderOptions{UseNumber: true}).Decode(v)
+}
+
+// toJSONDocument converts a value to a generic JSON document.
+func toJSONDocument(codec JSONCodec, v any) (any, error) {
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, InternalServerError{
+			Err:    err,
+			Detail: "cannot serialize the value to patch",
+		}
+	}
+	var document any
+	err = decodeJSONDocument(codec, data, &document)
+	return document, err
+}
+
+// fromJSONDocument converts a generic JSON document to a T, then transforms and validates it.
+// The document is decoded into a copy of the original value, so that the fields
+// not serialized to JSON, such as unexported or `json:"-"` fields, are kept.
+func fromJSONDocument[T any](p patchContext, document any, original T) (T, error) {
+	data, err := p.codec().Marshal(document)
+	if err != nil {
+		return original, BadRequestError{
+			Err:    err,
+			Detail: "cannot serialize the patched value: " + err.Error(),
+		}
+	}
+	return readJSONInto(p.context(), bytes.NewReader(data), p.options, withoutJSONFields(original))
+}
+
+// withoutJSONFields returns a copy of a struct, or of a pointer to a struct, with the fields serialized to JSON reset.
+// They are read from the patched document: decoding into them would merge maps and write into the slices and pointers of the original.
+// The other values are reset entirely.
+func withoutJSONFields[T any](original T) T {
+	value := reflect.ValueOf(&original).Elem()
+	switch {
+	case value.Kind() == reflect.Struct:
+		clearJSONFields(value)
+	case value.Kind() == reflect.Pointer && value.Type().Elem().Kind() == reflect.Struct && !value.IsNil():
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(value.Elem())
+		clearJSONFields(copied.Elem())
+		value.Set(copied)
+	default:
+		return *new(T)
+	}
+	return original
+}
+
+// clearJSONFields resets the fields of a struct serialized to JSON, including the fields of embedded structs.
+func clearJSONFields(value reflect.Value) {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			clearJSONFields(value.Field(i))
+			continue
+		}
+		if field.IsExported() {
+			value.Field(i).SetZero()
+		}
+	}
+}
+
+func deepCopyJSONDocument(codec JSONCodec, v any) (any, error) {
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var copied any
+	err = decodeJSONDocument(codec, data, &copied)
+	return copied, err
+}
+
+// jsonDocumentsEqual compares two generic JSON documents. Numbers are compared by value.
+func jsonDocumentsEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number, float64:
+		fa, okA := jsonNumberValue(a)
+		fb, okB := jsonNumberValue(b)
+		return okA && okB && fa == fb
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !jsonDocumentsEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonDocumentsEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// jsonNumberValue returns the value of a number of a generic JSON document,
+// decoded as a [json.Number] or a float64 depending on the codec.
+func jsonNumberValue(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// parseJSONPointer parses a JSON Pointer (RFC 6901) into its reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q: must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// jsonArrayIndex parses an array index. If allowEnd is true, "-" and len(array) refer to the end of the array.
+func jsonArrayIndex(token string, array []any, allowEnd bool) (int, error) {
+	maxIndex := len(array) - 1
+	if allowEnd {
+		maxIndex = len(array)
+		if token == "-" {
+			return len(array), nil
+		}
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > maxIndex || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return index, nil
+}
+
+func jsonPointerGet(document any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := document.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %q does not exist", token)
+			}
+			document = value
+		case []any:
+			index, err := jsonArrayIndex(token, node, false)
+			if err != nil {
+				return nil, err
+			}
+			document = node[index]
+		default:
+			return nil, fmt.Errorf("path not found: cannot get %q from a scalar value", token)
+		}
+	}
+	return document, nil
+}
+
+func jsonPointerAdd(document any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, last := path[0], len(path) == 1
+
+	switch node := document.(type) {
+	case map[string]any:
+		if last {
+			node[token] = value
+			return node, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return document, fmt.Errorf("path not found: %q does not exist", token)
+		}
+		child, err := jsonPointerAdd(child, path[1:], value)
+		node[token] = child
+		return node, err
+	case []any:
+		index, err := jsonArrayIndex(token, node, last)
+		if err != nil {
+			return document, err
+		}
+		if last {
+			return slices.Insert(node, index, value), nil
+		}
+		node[index], err = jsonPointerAdd(node[index], path[1:], value)
+		return node, err
+	}
+	return document, fmt.Errorf("path not found: cannot add %q to a scalar value", token)
+}
+
+func jsonPointerRemove(document any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, document, nil
+	}
+	token, last := path[0], len(path) == 1
+
+	switch node := document.(type) {
+	case map[string]any:
+		child, ok := node[token]
+		if !ok {
+			return document, nil, fmt.Errorf("path not found: %q does not exist", token)
+		}
+		if last {
+			delete(node, token)
+			return node, child, nil
+		}
+		child, removed, err := jsonPointerRemove(child, path[1:])
+		node[token] = child
+		return node, removed, err
+	case []any:
+		index, err := jsonArrayIndex(token, node, false)
+		if err != nil {
+			return document, nil, err
+		}
+		if last {
+			removed := node[index]
+			return slices.Delete(node, index, index+1), removed, nil
+		}
+		child, removed, err := jsonPointerRemove(node[index], path[1:])
+		node[index] = child
+		return node, removed, err
+	}
+	return document, nil, fmt.Errorf("path not found: cannot remove %q from a scalar value", token)
+}
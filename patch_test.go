@@ -0,0 +1,298 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type patchAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type patchPet struct {
+	Name    string        `json:"name" validate:"required"`
+	Age     int           `json:"age"`
+	Tags    []string      `json:"tags"`
+	Address *patchAddress `json:"address,omitempty"`
+}
+
+func TestMergePatch(t *testing.T) {
+	original := patchPet{Name: "Rex", Age: 3, Tags: []string{"dog"}, Address: &patchAddress{City: "Paris", Zip: "75001"}}
+
+	apply := func(t *testing.T, document string) (patchPet, error) {
+		t.Helper()
+		patch, err := ReadJSON[MergePatch[patchPet]](t.Context(), strings.NewReader(document))
+		require.NoError(t, err)
+		return patch.Apply(original)
+	}
+
+	t.Run("absent fields are unchanged, null fields are removed", func(t *testing.T) {
+		pet, err := apply(t, `{"age":4,"address":{"zip":null}}`)
+		require.NoError(t, err)
+		require.Equal(t, patchPet{Name: "Rex", Age: 4, Tags: []string{"dog"}, Address: &patchAddress{City: "Paris"}}, pet)
+		require.Equal(t, "75001", original.Address.Zip, "the original must not be modified")
+	})
+
+	t.Run("arrays are replaced", func(t *testing.T) {
+		pet, err := apply(t, `{"tags":["good boy"],"address":null}`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"good boy"}, pet.Tags)
+		require.Nil(t, pet.Address)
+	})
+
+	t.Run("result is validated", func(t *testing.T) {
+		_, err := apply(t, `{"name":null}`)
+		var httpError HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusBadRequest, httpError.StatusCode())
+		require.Equal(t, "Validation Error", httpError.Title)
+	})
+}
+
+func TestJSONPatch(t *testing.T) {
+	original := patchPet{Name: "Rex", Age: 3, Tags: []string{"dog", "brown"}}
+
+	apply := func(t *testing.T, document string) (patchPet, error) {
+		t.Helper()
+		patch, err := ReadJSON[JSONPatch[patchPet]](t.Context(), strings.NewReader(document))
+		require.NoError(t, err)
+		return patch.Apply(original)
+	}
+
+	t.Run("operations", func(t *testing.T) {
+		pet, err := apply(t, `[
+			{"op":"test","path":"/age","value":3.0},
+			{"op":"replace","path":"/age","value":4},
+			{"op":"add","path":"/tags/-","value":"good boy"},
+			{"op":"remove","path":"/tags/0"},
+			{"op":"add","path":"/address","value":{"city":"Paris"}},
+			{"op":"copy","from":"/address/city","path":"/address/zip"},
+			{"op":"move","from":"/tags/0","path":"/tags/1"}
+		]`)
+		require.NoError(t, err)
+		require.Equal(t, patchPet{Name: "Rex", Age: 4, Tags: []string{"good boy", "brown"}, Address: &patchAddress{City: "Paris", Zip: "Paris"}}, pet)
+		require.Equal(t, []string{"dog", "brown"}, original.Tags, "the original must not be modified")
+	})
+
+	t.Run("failed test", func(t *testing.T) {
+		_, err := apply(t, `[{"op":"test","path":"/name","value":"Felix"},{"op":"replace","path":"/name","value":"Tom"}]`)
+		require.ErrorAs(t, err, &ConflictError{})
+	})
+
+	t.Run("path not found", func(t *testing.T) {
+		_, err := apply(t, `[{"op":"replace","path":"/address/city","value":"Paris"}]`)
+		var httpError HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusUnprocessableEntity, httpError.StatusCode())
+		require.Equal(t, "[0]", httpError.Errors[0].Name)
+	})
+
+	t.Run("result is validated", func(t *testing.T) {
+		_, err := apply(t, `[{"op":"remove","path":"/name"}]`)
+		require.ErrorContains(t, err, "Validation Error")
+	})
+}
+
+type patchAccount struct {
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels"`
+	Password string            `json:"-"`
+	version  int
+}
+
+func TestPatchKeepsFieldsNotSerialized(t *testing.T) {
+	original := patchAccount{Name: "Rex", Labels: map[string]string{"a": "1", "b": "2"}, Password: "secret", version: 2}
+
+	t.Run("merge patch", func(t *testing.T) {
+		patch, err := ReadJSON[MergePatch[patchAccount]](t.Context(), strings.NewReader(`{"labels":{"a":null}}`))
+		require.NoError(t, err)
+		account, err := patch.Apply(original)
+		require.NoError(t, err)
+		require.Equal(t, patchAccount{Name: "Rex", Labels: map[string]string{"b": "2"}, Password: "secret", version: 2}, account)
+		require.Equal(t, map[string]string{"a": "1", "b": "2"}, original.Labels, "the original must not be modified")
+	})
+
+	t.Run("JSON Patch", func(t *testing.T) {
+		patch, err := ReadJSON[JSONPatch[patchAccount]](t.Context(), strings.NewReader(`[{"op":"replace","path":"/name","value":"Felix"}]`))
+		require.NoError(t, err)
+		account, err := patch.Apply(original)
+		require.NoError(t, err)
+		require.Equal(t, patchAccount{Name: "Felix", Labels: map[string]string{"a": "1", "b": "2"}, Password: "secret", version: 2}, account)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		patch, err := ReadJSON[MergePatch[*patchAccount]](t.Context(), strings.NewReader(`{"name":"Felix"}`))
+		require.NoError(t, err)
+		account, err := patch.Apply(&original)
+		require.NoError(t, err)
+		require.Equal(t, &patchAccount{Name: "Felix", Labels: map[string]string{"a": "1", "b": "2"}, Password: "secret", version: 2}, account)
+		require.Equal(t, "Rex", original.Name, "the original must not be modified")
+	})
+}
+
+func TestParseJSONPointer(t *testing.T) {
+	tokens, err := parseJSONPointer("/a~1b/m~0n/0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/b", "m~n", "0"}, tokens)
+
+	tokens, err = parseJSONPointer("")
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	_, err = parseJSONPointer("a")
+	require.Error(t, err)
+}
+
+func TestPatchController(t *testing.T) {
+	s := NewServer()
+	original := patchPet{Name: "Rex", Age: 3}
+
+	mergeRoute := Patch(s, "/merge", func(c ContextWithBody[MergePatch[patchPet]]) (patchPet, error) {
+		patch, err := c.Body()
+		if err != nil {
+			return patchPet{}, err
+		}
+		return patch.Apply(original)
+	})
+	jsonPatchRoute := Patch(s, "/json-patch", func(c ContextWithBody[JSONPatch[patchPet]]) (patchPet, error) {
+		patch, err := c.Body()
+		if err != nil {
+			return patchPet{}, err
+		}
+		return patch.Apply(original)
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/merge", strings.NewReader(`{"age":5}`))
+		r.Header.Set("Content-Type", ContentTypeMergePatch)
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"name":"Rex","age":5,"tags":null}`, w.Body.String())
+	})
+
+	t.Run("merge patch with unknown field", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/merge", strings.NewReader(`{"color":"brown"}`))
+		r.Header.Set("Content-Type", ContentTypeMergePatch)
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("merge patch must be an object", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/merge", strings.NewReader(`[]`))
+		r.Header.Set("Content-Type", ContentTypeMergePatch)
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid JSON Patch operations", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/json-patch", strings.NewReader(`[{"op":"add","path":"/age"},{"op":"jump","path":"/"}]`))
+		r.Header.Set("Content-Type", ContentTypeJSONPatch)
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `"name":"[0]"`)
+		require.Contains(t, w.Body.String(), `"name":"[1]"`)
+	})
+
+	t.Run("wrong patch media type", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/merge", strings.NewReader(`[]`))
+		r.Header.Set("Content-Type", ContentTypeJSONPatch)
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("patch sent as JSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/merge", strings.NewReader(`{"color":"brown"}`))
+		r.Header.Set("Content-Type", "application/json")
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("patch media type with parameters", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/merge", strings.NewReader(`{"age":5}`))
+		r.Header.Set("Content-Type", ContentTypeMergePatch+"; charset=utf-8")
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("documents the merge patch", func(t *testing.T) {
+		content := mergeRoute.Operation.RequestBody.Value.Content
+		require.Len(t, content, 1)
+		require.Contains(t, content, ContentTypeMergePatch)
+		assert.Equal(t, "#/components/schemas/patchPetMergePatch", content[ContentTypeMergePatch].Schema.Ref)
+
+		schema := s.OpenAPI.Description().Components.Schemas["patchPetMergePatch"].Value
+		assert.Empty(t, schema.Required)
+		assert.Contains(t, schema.Properties, "name")
+		assert.NotEmpty(t, s.OpenAPI.Description().Components.Schemas["patchPet"].Value.Required)
+	})
+
+	t.Run("documents the JSON Patch", func(t *testing.T) {
+		content := jsonPatchRoute.Operation.RequestBody.Value.Content
+		require.Len(t, content, 1)
+		schema := content[ContentTypeJSONPatch].Schema.Value
+		require.True(t, schema.Type.Is("array"))
+		assert.Equal(t, "#/components/schemas/JSONPatchOperation", schema.Items.Ref)
+	})
+}
+
+func TestPatchJSONCodec(t *testing.T) {
+	type account struct {
+		ID      int64 `json:"id"`
+		Balance int64 `json:"balance"`
+	}
+	original := account{ID: 1<<62 + 1, Balance: 10}
+
+	codec := recordingJSONCodec{marshal: new(int), decode: new(int)}
+	s := NewServer(WithEngineOptions(WithJSONCodec(codec)))
+	Patch(s, "/json-patch", func(c ContextWithBody[JSONPatch[account]]) (account, error) {
+		patch, err := c.Body()
+		if err != nil {
+			return account{}, err
+		}
+		return patch.Apply(original)
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPatch, "/json-patch", strings.NewReader(body))
+		r.Header.Set("Content-Type", ContentTypeJSONPatch)
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("patch documents use the codec", func(t *testing.T) {
+		w := send(`[{"op":"test","path":"/id","value":4611686018427387905},{"op":"replace","path":"/balance","value":20}]`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"id":4611686018427387905,"balance":20}`, w.Body.String(), "large integers keep their precision")
+
+		// request body, operations, 2 operation values, original value, patched value
+		assert.Equal(t, 6, *codec.decode)
+		// original value, patched value, response
+		assert.Equal(t, 3, *codec.marshal)
+	})
+
+	t.Run("unknown fields in the operations", func(t *testing.T) {
+		w := send(`[{"op":"replace","path":"/balance","value":20,"unknown":true}]`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
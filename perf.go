@@ -1,12 +1,16 @@
 package fuego
 
 import (
+	"context"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Timing is a struct to represent a server timing.
-// Used in the Server-Timing header.
+// Used in the Server-Timing header, see [StartTiming] and [AddTiming].
 type Timing struct {
 	Name string
 	Desc string
@@ -21,3 +25,140 @@ func (t Timing) String() string {
 	}
 	return s
 }
+
+type serverTimingsKey struct{}
+
+// serverTimings collects the timings of a request, written in the Server-Timing header
+// before the body, and in the Server-Timing trailer for the ones recorded after.
+type serverTimings struct {
+	mu      sync.Mutex
+	timings []Timing
+	// Number of timings already written in the header.
+	written int
+}
+
+// withServerTimings returns a copy of ctx collecting the timings of the request, if it does not already.
+func withServerTimings(ctx context.Context) context.Context {
+	if serverTimingsFrom(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, serverTimingsKey{}, &serverTimings{})
+}
+
+func serverTimingsFrom(ctx context.Context) *serverTimings {
+	timings, _ := ctx.Value(serverTimingsKey{}).(*serverTimings)
+	return timings
+}
+
+func (t *serverTimings) add(timing Timing) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timings = append(t.timings, timing)
+}
+
+func (t *serverTimings) all() []Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.timings)
+}
+
+// unwritten returns the Server-Timing value of the timings not written yet, and marks them as written.
+func (t *serverTimings) unwritten() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	value := formatTimings(t.timings[t.written:])
+	t.written = len(t.timings)
+	return value
+}
+
+func formatTimings(timings []Timing) string {
+	entries := make([]string, len(timings))
+	for i, timing := range timings {
+		entries[i] = timing.String()
+	}
+	return strings.Join(entries, ", ")
+}
+
+// AddTiming adds a timing to the Server-Timing header of the response, and to the logs of the request.
+// The ctx is the [Context] of the controller, or the context of the request.
+// Timings added after the response body is written are sent in the Server-Timing trailer.
+//
+//	fuego.AddTiming(c, fuego.Timing{Name: "cache", Desc: "hit", Dur: elapsed})
+func AddTiming(ctx context.Context, timing Timing) {
+	if timings := serverTimingsFrom(ctx); timings != nil {
+		timings.add(timing)
+	}
+}
+
+// StartTiming starts a timing, added to the Server-Timing header of the response when the returned function is called.
+// See [AddTiming].
+//
+//	stop := fuego.StartTiming(c, "db", "list recipes")
+//	recipes, err := store.ListRecipes(c)
+//	stop()
+func StartTiming(ctx context.Context, name, desc string) (stop func()) {
+	start := time.Now()
+	return func() {
+		AddTiming(ctx, Timing{Name: name, Desc: desc, Dur: time.Since(start)})
+	}
+}
+
+// Timings returns the timings of the request: the ones of the controller phases, and the ones added with [AddTiming].
+// The ctx is the [Context] of the controller, or the context of the request.
+func Timings(ctx context.Context) []Timing {
+	if timings := serverTimingsFrom(ctx); timings != nil {
+		return timings.all()
+	}
+	return nil
+}
+
+// PhaseObserver is notified at the end of each phase of a Fuego controller, see [WithPhaseObserver].
+type PhaseObserver func(ctx context.Context, phase string, start time.Time, duration time.Duration)
+
+type phaseObserversKey struct{}
+
+// WithPhaseObserver returns a copy of ctx in which the observer is notified of the phases of the controllers,
+// with the names of the Server-Timing header: fuegoReqInit (params validation), deserialize, controller, transformOut and serialize.
+// It is meant for middlewares recording metrics or traces:
+//
+//	r = r.WithContext(fuego.WithPhaseObserver(r.Context(), func(ctx context.Context, phase string, start time.Time, duration time.Duration) {
+//		slog.DebugContext(ctx, "phase", "name", phase, "duration", duration)
+//	}))
+func WithPhaseObserver(ctx context.Context, observer PhaseObserver) context.Context {
+	observers, _ := ctx.Value(phaseObserversKey{}).([]PhaseObserver)
+	return context.WithValue(ctx, phaseObserversKey{}, append(slices.Clip(observers), observer))
+}
+
+// observePhase notifies the observers of the context that a phase ended.
+func observePhase(ctx context.Context, phase string, start time.Time, duration time.Duration) {
+	observers, _ := ctx.Value(phaseObserversKey{}).([]PhaseObserver)
+	for _, observer := range observers {
+		observer(ctx, phase, start, duration)
+	}
+}
+
+// ErrorObserver is notified of the errors of a Fuego controller, see [WithErrorObserver].
+type ErrorObserver func(ctx context.Context, err error)
+
+type errorObserversKey struct{}
+
+// WithErrorObserver returns a copy of ctx in which the observer is notified of the errors of the controllers,
+// before they go through the error handler of the route: params validation, controller, transformOut
+// and serialization errors, and timeouts.
+// Unlike wrapping the error handler, it does not depend on the error handler set on the route or its group.
+//
+//	r = r.WithContext(fuego.WithErrorObserver(r.Context(), func(ctx context.Context, err error) {
+//		slog.ErrorContext(ctx, "request failed", "error", err)
+//	}))
+func WithErrorObserver(ctx context.Context, observer ErrorObserver) context.Context {
+	observers, _ := ctx.Value(errorObserversKey{}).([]ErrorObserver)
+	return context.WithValue(ctx, errorObserversKey{}, append(slices.Clip(observers), observer))
+}
+
+// observeError notifies the observers of the context of an error.
+func observeError(ctx context.Context, err error) {
+	observers, _ := ctx.Value(errorObserversKey{}).([]ErrorObserver)
+	for _, observer := range observers {
+		observer(ctx, err)
+	}
+}
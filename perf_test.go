@@ -1,10 +1,19 @@
 package fuego
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/thejerf/slogassert"
 )
 
 func TestTiming_String(t *testing.T) {
@@ -27,3 +36,230 @@ func TestTiming_String(t *testing.T) {
 		require.Equal(t, "test;dur=300;desc=\"test desc\"", timing.String())
 	})
 }
+
+func TestWithPhaseObserver(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Post(s, "/", func(c ContextWithBody[ans]) (ans, error) {
+		return c.Body()
+	})
+
+	var first, second []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithPhaseObserver(r.Context(), func(_ context.Context, phase string, start time.Time, duration time.Duration) {
+			require.False(t, start.IsZero())
+			first = append(first, phase)
+		})
+		ctx = WithPhaseObserver(ctx, func(_ context.Context, phase string, _ time.Time, _ time.Duration) {
+			second = append(second, phase)
+		})
+		s.Mux.ServeHTTP(w, r.WithContext(ctx))
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"ans":"ok"}`))
+	r.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	expected := []string{"fuegoReqInit", "deserialize", "controller", "transformOut", "serialize"}
+	require.Equal(t, expected, first)
+	require.Equal(t, expected, second)
+}
+
+func TestDeserializePhase(t *testing.T) {
+	s := NewServer(WithoutLogger(), WithEngineOptions(WithSerDes("application/vnd.typed", typedSerDes{})))
+	Post(s, "/typed", func(c ContextWithBody[ans]) (ans, error) {
+		return c.Body()
+	})
+	Post(s, "/text", func(c ContextWithBody[string]) (string, error) {
+		return c.Body()
+	}, OptionWithContentTypeSerDes("application/vnd.text", textSerDes{}))
+	Patch(s, "/patch", func(c ContextWithBody[MergePatch[ans]]) (ans, error) {
+		patch, err := c.Body()
+		if err != nil {
+			return ans{}, err
+		}
+		return patch.Apply(ans{})
+	})
+	Post(s, "/binary", func(c ContextWithBody[[]byte]) (string, error) {
+		body, err := c.Body()
+		return string(body), err
+	})
+
+	for _, tc := range []struct {
+		method, path, contentType string
+	}{
+		{http.MethodPost, "/typed", "application/vnd.typed"},
+		{http.MethodPost, "/text", "application/vnd.text"},
+		{http.MethodPatch, "/patch", ContentTypeMergePatch},
+		{http.MethodPost, "/binary", "application/octet-stream"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			var phases []string
+			r := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{"ans":"ok"}`))
+			r.Header.Set("Content-Type", tc.contentType)
+			r = r.WithContext(WithPhaseObserver(r.Context(), func(_ context.Context, phase string, _ time.Time, _ time.Duration) {
+				phases = append(phases, phase)
+			}))
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.Contains(t, phases, "deserialize")
+			require.Contains(t, w.Result().Header.Get("Server-Timing"), "deserialize;")
+		})
+	}
+}
+
+func TestWithErrorObserver(t *testing.T) {
+	s := NewServer(WithoutLogger())
+	Get(s, "/query", func(c ContextNoBody) (ans, error) {
+		return ans{Ans: "ok"}, nil
+	}, OptionQuery("name", "Name", ParamRequired()))
+	Get(s, "/route-error-handler", func(c ContextNoBody) (ans, error) {
+		return ans{}, errors.New("controller failed")
+	}, OptionErrorHandler(func(_ context.Context, err error) error {
+		return NotFoundError{Err: err}
+	}))
+	Get(s, "/timeout", func(c ContextNoBody) (ans, error) {
+		<-c.Context().Done()
+		return ans{Ans: "too late"}, nil
+	}, OptionTimeout(10*time.Millisecond))
+
+	// serve returns the status code, and the errors and phases observed so far
+	serve := func(path string) (int, func() ([]error, []string)) {
+		var mu sync.Mutex
+		var errs []error
+		var phases []string
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		ctx := WithErrorObserver(r.Context(), func(_ context.Context, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		})
+		ctx = WithPhaseObserver(ctx, func(_ context.Context, phase string, _ time.Time, _ time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			phases = append(phases, phase)
+		})
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r.WithContext(ctx))
+		return w.Code, func() ([]error, []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(errs), slices.Clone(phases)
+		}
+	}
+
+	t.Run("params validation", func(t *testing.T) {
+		code, observed := serve("/query")
+		require.Equal(t, http.StatusBadRequest, code)
+		errs, phases := observed()
+		require.Len(t, errs, 1)
+		require.Equal(t, []string{"fuegoReqInit"}, phases)
+	})
+
+	t.Run("route error handler", func(t *testing.T) {
+		code, observed := serve("/route-error-handler")
+		require.Equal(t, http.StatusNotFound, code)
+		errs, _ := observed()
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs[0], "controller failed")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		code, observed := serve("/timeout")
+		require.Equal(t, http.StatusServiceUnavailable, code)
+
+		// Waits for the controller, still running after the timeout
+		require.Eventually(t, func() bool {
+			_, phases := observed()
+			return slices.Contains(phases, "serialize")
+		}, time.Second, time.Millisecond)
+
+		errs, _ := observed()
+		require.True(t, slices.ContainsFunc(errs, func(err error) bool {
+			return errors.Is(err, context.DeadlineExceeded)
+		}), errs)
+	})
+
+	t.Run("no error", func(t *testing.T) {
+		code, observed := serve("/query?name=ok")
+		require.Equal(t, http.StatusOK, code)
+		errs, _ := observed()
+		require.Empty(t, errs)
+	})
+}
+
+func TestServerTiming(t *testing.T) {
+	controller := func(c ContextNoBody) (ans, error) {
+		stop := StartTiming(c, "db", "list recipes")
+		stop()
+		AddTiming(c, Timing{Name: "cache", Desc: "miss", Dur: 2 * time.Millisecond})
+		return ans{Ans: "ok"}, nil
+	}
+
+	t.Run("controller timings are aggregated in the header", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		Get(s, "/", controller)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		res := w.Result()
+
+		require.Len(t, res.Header.Values("Server-Timing"), 1)
+		header := res.Header.Get("Server-Timing")
+		for _, name := range []string{"fuegoReqInit;dur=", `desc="list recipes"`, "cache;dur=2;desc=\"miss\"", "controller;dur=", "transformOut;dur="} {
+			require.Contains(t, header, name)
+		}
+		require.NotContains(t, header, "serialize")
+
+		require.Equal(t, "Server-Timing", res.Header.Get("Trailer"))
+		require.True(t, strings.HasPrefix(res.Trailer.Get("Server-Timing"), "serialize;dur="))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		s := NewServer(WithoutLogger())
+		Get(s, "/", func(c ContextNoBody) (ans, error) {
+			AddTiming(c, Timing{Name: "db", Dur: time.Millisecond})
+			return ans{}, BadRequestError{}
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Header().Get("Server-Timing"), "db;dur=1")
+	})
+
+	t.Run("disabled exposure, still logged", func(t *testing.T) {
+		handler := slogassert.New(t, slog.LevelInfo, nil)
+		s := NewServer(
+			WithLogHandler(handler),
+			WithEngineOptions(WithoutServerTiming()),
+		)
+		Get(s, "/", controller)
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Server-Timing"))
+		require.Empty(t, w.Header().Get("Trailer"))
+
+		logged := false
+		for _, record := range handler.Unasserted() {
+			if record.Message != "outgoing response" {
+				continue
+			}
+			timings := record.Attrs["server_timing"].String()
+			logged = strings.Contains(timings, `desc="list recipes"`) && strings.Contains(timings, "serialize;dur=")
+		}
+		require.True(t, logged)
+		handler.Reset()
+	})
+
+	t.Run("no-op outside of a request", func(t *testing.T) {
+		AddTiming(context.Background(), Timing{Name: "db"})
+		StartTiming(context.Background(), "db", "")()
+		require.Empty(t, Timings(context.Background()))
+	})
+}
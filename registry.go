@@ -0,0 +1,156 @@
+package fuego
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Routes returns the routes registered on the engine, by any adaptor, in registration order.
+// Hidden routes, absent from the OpenAPI spec, are included.
+func (e *Engine) Routes() []BaseRoute {
+	e.routesMu.RLock()
+	defer e.routesMu.RUnlock()
+
+	return slices.Clone(e.routes)
+}
+
+// RouteByOperationID returns the route with the given OpenAPI operation ID.
+func (e *Engine) RouteByOperationID(operationID string) (BaseRoute, bool) {
+	e.routesMu.RLock()
+	defer e.routesMu.RUnlock()
+
+	for _, route := range e.routes {
+		if route.Operation != nil && route.Operation.OperationID == operationID {
+			return route, true
+		}
+	}
+	return BaseRoute{}, false
+}
+
+func (e *Engine) registerRoute(route BaseRoute) {
+	e.routesMu.Lock()
+	defer e.routesMu.Unlock()
+
+	// Versions selected from the request share their paths, and so their URLs
+	if route.Name != "" && slices.ContainsFunc(e.routes, func(r BaseRoute) bool { return r.Name == route.Name && r.Path != route.Path }) {
+		slog.Warn("route name already used, URLs are built from the first route", "name", route.Name, "path", route.Path)
+	}
+	e.routes = append(e.routes, route)
+}
+
+// RouteDescription is the summary of a registered route, as listed by [Engine.RoutesHandler].
+type RouteDescription struct {
+	Method               string   `json:"method"`
+	Path                 string   `json:"path"`
+	OperationID          string   `json:"operationId,omitempty"`
+	Name                 string   `json:"name,omitempty"`
+	Handler              string   `json:"handler"`
+	Middlewares          []string `json:"middlewares,omitempty"`
+	Params               []string `json:"params,omitempty"`
+	RequestContentTypes  []string `json:"requestContentTypes,omitempty"`
+	ResponseContentTypes []string `json:"responseContentTypes,omitempty"`
+	Hidden               bool     `json:"hidden,omitempty"`
+	Version              string   `json:"version,omitempty"`
+	Adaptor              string   `json:"adaptor"`
+}
+
+// Describe returns the summary of the route.
+func (r BaseRoute) Describe() RouteDescription {
+	description := RouteDescription{
+		Method:               r.Method,
+		Path:                 r.Path,
+		Name:                 r.Name,
+		Handler:              r.FullName,
+		RequestContentTypes:  r.RequestContentTypes,
+		ResponseContentTypes: r.ResponseContentTypes,
+		Hidden:               r.Hidden,
+		Version:              r.Version,
+		Adaptor:              r.Adaptor,
+	}
+	if description.Method == "" {
+		description.Method = "*"
+	}
+	if r.Operation != nil {
+		description.OperationID = r.Operation.OperationID
+	}
+	for _, middleware := range r.Middlewares {
+		description.Middlewares = append(description.Middlewares, FuncName(middleware))
+	}
+	for name, param := range r.Params {
+		description.Params = append(description.Params, string(param.Type)+":"+name)
+	}
+	slices.Sort(description.Params)
+
+	return description
+}
+
+// PrintRoutes writes the table of the registered routes, like:
+//
+//	METHOD  PATH           HANDLER          MIDDLEWARES  ADAPTOR
+//	GET     /pets/{id}     main.getPet      1            net/http
+//	GET     /debug/routes  main.listRoutes  1            net/http  (hidden)
+func (e *Engine) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tMIDDLEWARES\tADAPTOR\t")
+	for _, route := range e.Routes() {
+		description := route.Describe()
+		hidden := ""
+		if description.Hidden {
+			hidden = "(hidden)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			description.Method,
+			description.Path,
+			description.Handler,
+			len(description.Middlewares),
+			description.Adaptor,
+			hidden,
+		)
+	}
+	return tw.Flush()
+}
+
+// RoutesHandler returns a handler listing the registered routes, as JSON,
+// or as a table if the client prefers text/plain, like curl -H "Accept: text/plain".
+// It is not registered by default, as it exposes the internals of the application:
+// mount it on a protected, hidden route.
+//
+//	fuego.GetStd(s, "/debug/routes", s.RoutesHandler().ServeHTTP, option.Hide(), option.Middleware(adminOnly))
+func (e *Engine) RoutesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddVary(w.Header(), "Accept")
+		if NegotiateContentType(r.Header.Get("Accept"), []string{"application/json", "text/plain"}) == "text/plain" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_ = e.PrintRoutes(w)
+			return
+		}
+
+		routes := e.Routes()
+		descriptions := make([]RouteDescription, 0, len(routes))
+		for _, route := range routes {
+			descriptions = append(descriptions, route.Describe())
+		}
+		_ = SendJSON(w, r, descriptions)
+	})
+}
+
+// adaptorName returns the name of the router adaptor of the registerer, from its package:
+// "net/http" for fuego, "gin" for fuegogin, etc.
+func adaptorName(registerer any) string {
+	t := reflect.TypeOf(registerer)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := path.Base(t.PkgPath())
+	if name == "fuego" {
+		return "net/http"
+	}
+	return strings.TrimPrefix(name, "fuego")
+}
@@ -0,0 +1,100 @@
+package fuego
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutes(t *testing.T) {
+	s := NewServer()
+
+	Get(s, "/pets/{id}", func(c ContextNoBody) (ans, error) {
+		return ans{}, nil
+	}, OptionOperationID("getPet"), OptionMiddleware(dummyMiddleware))
+	Post(s, "/pets", func(c ContextWithBody[ans]) (ans, error) {
+		return ans{}, nil
+	})
+	GetStd(s, "/debug/routes", s.RoutesHandler().ServeHTTP, OptionHide())
+	Handle(s, "/static/", http.FileServer(http.Dir(".")))
+
+	routes := s.Routes()
+	require.Len(t, routes, 4)
+
+	t.Run("registration order", func(t *testing.T) {
+		assert.Equal(t, "/pets/{id}", routes[0].Path)
+		assert.Equal(t, "/pets", routes[1].Path)
+		assert.Equal(t, "/debug/routes", routes[2].Path)
+	})
+
+	t.Run("describe", func(t *testing.T) {
+		description := routes[0].Describe()
+		assert.Equal(t, http.MethodGet, description.Method)
+		assert.Equal(t, "getPet", description.OperationID)
+		assert.Equal(t, "net/http", description.Adaptor)
+		assert.Contains(t, description.Params, "header:Accept")
+		assert.Contains(t, description.Middlewares, "github.com/go-fuego/fuego.dummyMiddleware")
+		assert.False(t, description.Hidden)
+
+		assert.True(t, routes[2].Describe().Hidden)
+	})
+
+	t.Run("standard handlers", func(t *testing.T) {
+		description := routes[3].Describe()
+		assert.Equal(t, "/static/", description.Path)
+		assert.Equal(t, "*", description.Method)
+		assert.Equal(t, "net/http", description.Adaptor)
+		assert.True(t, description.Hidden)
+	})
+
+	t.Run("by operation ID", func(t *testing.T) {
+		route, ok := s.RouteByOperationID("getPet")
+		require.True(t, ok)
+		assert.Equal(t, "/pets/{id}", route.Path)
+
+		route, ok = s.RouteByOperationID("GET_/debug/routes")
+		require.True(t, ok, "hidden routes can be looked up")
+		assert.True(t, route.Hidden)
+
+		_, ok = s.RouteByOperationID("unknown")
+		assert.False(t, ok)
+	})
+
+	t.Run("returns a copy", func(t *testing.T) {
+		routes[0].Path = "/modified"
+		assert.Equal(t, "/pets/{id}", s.Routes()[0].Path)
+	})
+
+	t.Run("handler as JSON", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var descriptions []RouteDescription
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &descriptions))
+		require.Len(t, descriptions, 4)
+		assert.Equal(t, "getPet", descriptions[0].OperationID)
+		assert.Equal(t, http.MethodPost, descriptions[1].Method)
+	})
+
+	t.Run("handler as table", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/debug/routes", nil)
+		r.Header.Set("Accept", "text/plain")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 5)
+		assert.True(t, strings.HasPrefix(lines[0], "METHOD"))
+		assert.Contains(t, lines[1], "/pets/{id}")
+		assert.Contains(t, lines[3], "(hidden)")
+	})
+}
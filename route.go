@@ -1,8 +1,12 @@
 package fuego
 
 import (
+	"context"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -30,8 +34,11 @@ func NewBaseRoute(method, path string, handler any, e *Engine, options ...RouteO
 		OpenAPI:              e.OpenAPI,
 		RequestContentTypes:  e.requestContentTypes,
 		ResponseContentTypes: e.responseContentTypes,
-		contentTypeSerDes:    make(map[string]SerDes),
+		contentTypeSerDes:    make(map[string]SerDes, len(e.contentTypeSerDes)),
+
+		restrictResponseContentTypes: e.restrictResponseContentTypes,
 	}
+	maps.Copy(baseRoute.contentTypeSerDes, e.contentTypeSerDes)
 
 	for _, o := range options {
 		o(&baseRoute)
@@ -77,6 +84,45 @@ type BaseRoute struct {
 	// If true, the route will not be documented in the OpenAPI spec
 	Hidden bool
 
+	// If true, responses have an ETag and conditional requests are handled. See [OptionETag].
+	ETag bool
+
+	// Returns the current ETag of the resource, to check the If-Match header before the controller. See [OptionIfMatch].
+	CurrentETag func(r *http.Request) (string, error)
+
+	// Deadline of the requests, 0 for none. See [OptionTimeout].
+	Timeout time.Duration
+	// Status code sent when the deadline passes: 503 Service Unavailable or 504 Gateway Timeout. See [OptionTimeout].
+	TimeoutStatusCode int
+
+	// Cross-Origin Resource Sharing policy, nil for none. See [OptionCORS].
+	CORS *CORSConfig
+
+	// Name of the route, to build its URL with [Engine.URLFor]. Defaults to the operation ID. See [OptionName].
+	Name string
+
+	// Error handler of the route, nil for the one of the engine. See [OptionErrorHandler].
+	ErrorHandler func(context.Context, error) error
+
+	// Serializers of the responses and errors of the route, nil for the ones of the server.
+	// See [OptionSerializer] and [OptionErrorSerializer].
+	Serializer      Sender
+	ErrorSerializer ErrorSender
+
+	// Request body limits of the route, nil for the ones of the server.
+	// See [OptionMaxBodySize] and [OptionDisallowUnknownFields].
+	MaxBodySize           *int64
+	DisallowUnknownFields *bool
+
+	// Deprecation of the route, nil if not deprecated at runtime. See [OptionDeprecation].
+	Deprecation *Deprecation
+
+	// Version of the API of the route, "" if not versioned. See [Version].
+	Version string
+
+	// Router adaptor that registered the route, like "net/http" or "gin". Set at registration.
+	Adaptor string
+
 	// Override the default description
 	overrideDescription bool
 
@@ -85,6 +131,42 @@ type BaseRoute struct {
 
 	// Serialization/deserialization for various content types for this route
 	contentTypeSerDes map[string]SerDes
+
+	// If true, only the ResponseContentTypes and the content types of the SerDes are sent,
+	// as set with [OptionResponseContentType] or [WithResponseContentType].
+	restrictResponseContentTypes bool
+
+	// Response headers documented on the default status code, see [ParamDefaultStatusCode].
+	defaultStatusCodeHeaders openapi3.Headers
+
+	// If true, the route is an endpoint of Fuego itself (metrics, health or OpenAPI), not measured by [WithMetrics].
+	internal bool
+}
+
+// responseContentTypes returns the content types the route can send for the data, by order of preference.
+func (r *BaseRoute) responseContentTypes(data any) []string {
+	serdes := slices.Sorted(maps.Keys(r.contentTypeSerDes))
+	if r.restrictResponseContentTypes {
+		return slices.Concat(r.ResponseContentTypes, serdes)
+	}
+	return slices.Concat([]string{InferAcceptHeaderFromType(data)}, r.ResponseContentTypes, serdes, sentContentTypes)
+}
+
+// errorContentTypes returns the content types the route can send for the errors, by order of preference.
+func (r *BaseRoute) errorContentTypes() []string {
+	serdes := slices.Sorted(maps.Keys(r.contentTypeSerDes))
+	if r.restrictResponseContentTypes {
+		return slices.Concat(r.ResponseContentTypes, serdes)
+	}
+	return slices.Concat(errorContentTypes[:2], serdes, errorContentTypes[2:])
+}
+
+// timeoutStatusCode returns the status code sent when the deadline of the route passes, 503 by default.
+func (r *BaseRoute) timeoutStatusCode() int {
+	if r.TimeoutStatusCode == 0 {
+		return http.StatusServiceUnavailable
+	}
+	return r.TimeoutStatusCode
 }
 
 func (r *BaseRoute) GenerateDefaultDescription() {
@@ -19,3 +19,11 @@ type SerDes interface {
 	// If the input is not valid, an error is returned.
 	Deserialize(ctx context.Context, input io.Reader) (any, error)
 }
+
+// TypedDeserializer can be implemented by a [SerDes] to deserialize directly into the expected body type.
+// Generic formats (MessagePack, CBOR...) should implement it, as they cannot guess the type to return from [SerDes.Deserialize].
+// When implemented, it is preferred over [SerDes.Deserialize] and the body is transformed and validated like JSON bodies.
+type TypedDeserializer interface {
+	// DeserializeInto deserializes the input into v, which is a pointer to the expected type.
+	DeserializeInto(ctx context.Context, input io.Reader, v any) error
+}
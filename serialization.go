@@ -9,7 +9,6 @@ import (
 	"log/slog"
 	"net/http"
 	"reflect"
-	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -92,9 +91,32 @@ type Sender func(http.ResponseWriter, *http.Request, any) error
 // The format is determined by the Accept header.
 // If Accept header `*/*` is found Send will Attempt to send
 // HTML, and then JSON.
-func Send(w http.ResponseWriter, r *http.Request, ans any) (err error) {
-	for _, header := range parseAcceptHeader(r.Header) {
-		switch inferAcceptHeader(header, ans) {
+// Iterators and channels are streamed with [SendStream].
+// Readers, files and []byte are sent as binary with [SendBinary].
+// Slices of structs can be sent as CSV with [SendCSV].
+func Send(w http.ResponseWriter, r *http.Request, ans any) error {
+	return send(w, r, ans, sendContentTypes(ans))
+}
+
+// send sends a response in the best of the offered content types acceptable for the Accept header.
+// Offers not supported by [Send] are skipped.
+func send(w http.ResponseWriter, r *http.Request, ans any, offers []string) (err error) {
+	if isStream(ans) {
+		return SendStream(w, r, ans)
+	}
+	if isBinary(ans) {
+		return SendBinary(w, r, ans)
+	}
+
+	accept := r.Header.Get("Accept")
+	AddVary(w.Header(), "Accept")
+	for i, contentType := range acceptableContentTypes(accept, offers) {
+		// if serialization fails, only fall back to the content types explicitly asked for
+		if i > 0 && !acceptsExplicitly(accept, contentType) {
+			continue
+		}
+
+		switch contentType {
 		case "application/xml":
 			err = SendXML(w, r, ans)
 		case "text/html":
@@ -103,11 +125,10 @@ func Send(w http.ResponseWriter, r *http.Request, ans any) (err error) {
 			err = SendText(w, r, ans)
 		case "application/json":
 			err = SendJSON(w, r, ans)
-		case "application/x-yaml", "text/yaml; charset=utf-8", "application/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
+		case "application/yaml", "application/x-yaml", "text/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
 			err = SendYAML(w, r, ans)
-		default:
-			// if we don't support the header, try the next one
-			continue
+		case ContentTypeCSV:
+			err = SendCSV(w, r, ans)
 		}
 
 		if err == nil {
@@ -119,9 +140,33 @@ func Send(w http.ResponseWriter, r *http.Request, ans any) (err error) {
 		return err
 	}
 
-	return NotAcceptableError{
-		Detail: "no supported Accept header was provided from: " + r.Header.Get("Accept"),
+	return notAcceptable(accept, offers)
+}
+
+// sentContentTypes are the content types supported by [Send], by order of preference.
+var sentContentTypes = []string{
+	"application/json",
+	"application/xml",
+	"text/html",
+	"text/plain",
+	"application/yaml",
+	"application/x-yaml",
+	"text/yaml",
+	ContentTypeCSV,
+}
+
+// sendContentTypes returns the content types [Send] can offer for the given value:
+// the type inferred from the value first, then the other supported content types.
+func sendContentTypes(ans any) []string {
+	inferred := InferAcceptHeaderFromType(ans)
+	offers := make([]string, 0, len(sentContentTypes))
+	offers = append(offers, inferred)
+	for _, contentType := range sentContentTypes {
+		if contentType != inferred {
+			offers = append(offers, contentType)
+		}
 	}
+	return offers
 }
 
 // SendYAML sends a YAML response.
@@ -164,9 +209,13 @@ func SendYAMLError(w http.ResponseWriter, r *http.Request, err error) {
 // SendJSON sends a JSON response.
 // Declared as a variable to be able to override it for clients that need to customize serialization.
 // If serialization fails, it does NOT write to the response writer. It has to be passed to SendJSONError.
+// The JSON codec set with [WithJSONCodec] is used.
 var SendJSON = func(w http.ResponseWriter, r *http.Request, ans any) error {
 	w.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(w).Encode(ans)
+	data, err := jsonCodecFromContext(requestContext(r)).Marshal(ans)
+	if err == nil {
+		_, err = w.Write(append(data, '\n'))
+	}
 	if err != nil {
 		slog.ErrorContext(requestContext(r), "Cannot serialize returned response to JSON", "error", err, "errtype", fmt.Sprintf("%T", err))
 		var unsupportedType *json.UnsupportedTypeError
@@ -185,24 +234,38 @@ type ErrorSender = func(http.ResponseWriter, *http.Request, error)
 // SendError sends an error.
 // Declared as a variable to be able to override it for clients that need to customize serialization.
 var SendError = func(w http.ResponseWriter, r *http.Request, err error) {
-	for _, header := range parseAcceptHeader(r.Header) {
-		switch inferAcceptHeader(header, nil) {
-		case "application/xml":
-			SendXMLError(w, r, err)
-		case "text/html":
-			SendHTMLError(w, r, err)
-		case "text/plain":
-			SendTextError(w, r, err)
-		case "application/json":
-			SendJSONError(w, r, err)
-		case "application/x-yaml", "text/yaml; charset=utf-8", "application/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
-			SendYAMLError(w, r, err)
-		default:
-			continue
-		}
-		return
+	sendError(w, r, err, errorContentTypes)
+}
+
+// sendError sends an error in the best of the offered content types acceptable for the Accept header,
+// or in JSON if none is.
+func sendError(w http.ResponseWriter, r *http.Request, err error, offers []string) {
+	AddVary(w.Header(), "Accept")
+	switch NegotiateContentType(r.Header.Get("Accept"), offers) {
+	case "application/xml":
+		SendXMLError(w, r, err)
+	case "text/html":
+		SendHTMLError(w, r, err)
+	case "text/plain":
+		SendTextError(w, r, err)
+	case "application/yaml", "application/x-yaml", "text/yaml": // https://www.rfc-editor.org/rfc/rfc9512.html
+		SendYAMLError(w, r, err)
+	default:
+		SendJSONError(w, r, err)
 	}
-	SendJSONError(w, r, err)
+}
+
+// errorContentTypes are the content types supported by [SendError], by order of preference.
+// JSON is used when none of them is acceptable.
+var errorContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"text/html",
+	"text/plain",
+	"application/yaml",
+	"application/x-yaml",
+	"text/yaml",
 }
 
 // SendJSONError sends a JSON error response.
@@ -384,26 +447,3 @@ func InferAcceptHeaderFromType(ans any) string {
 
 	return "application/json"
 }
-
-func inferAcceptHeader(accept string, ans any) string {
-	if accept == "" || accept == "*/*" {
-		return InferAcceptHeaderFromType(ans)
-	}
-	return accept
-}
-
-func parseAcceptHeader(header http.Header) []string {
-	accept := header.Get("Accept")
-	if accept == "" {
-		return []string{""}
-	}
-	vals := strings.Split(accept, ",")
-	for i, v := range vals {
-		// ignore quality parameters if they exist
-		index := strings.Index(v, ";")
-		if index > 0 {
-			vals[i] = v[:index]
-		}
-	}
-	return vals
-}
@@ -16,12 +16,13 @@ import (
 // It is blocking.
 // It returns an error if the server could not start (it could not bind to the port for example).
 // It also generates the OpenAPI spec and outputs it to a file, the UI, and a handler (if enabled).
+// On SIGINT or SIGTERM, the server is shut down gracefully, see [WithShutdown].
 func (s *Server) Run() error {
 	return s.RunContext(context.Background())
 }
 
 // RunContext runs [Run] but with Context.
-// When context is canceled the server is shutdown.
+// When context is canceled the server is shut down gracefully, see [WithShutdown].
 func (s *Server) RunContext(ctx context.Context) error {
 	if err := s.setup(); err != nil {
 		return err
@@ -40,7 +41,7 @@ func (s *Server) RunTLS(certFile, keyFile string) error {
 }
 
 // RunTLSContext runs [RunTLS] but with Context.
-// When context is canceled the server is shutdown.
+// When context is canceled the server is shut down gracefully, see [WithShutdown].
 func (s *Server) RunTLSContext(ctx context.Context, certFile, keyFile string) error {
 	s.isTLS = true
 	if err := s.setup(); err != nil {
@@ -51,20 +52,10 @@ func (s *Server) RunTLSContext(ctx context.Context, certFile, keyFile string) er
 	})
 }
 
-// serveWithContext runs the server and shuts it down when the context is canceled.
-func (s *Server) serveWithContext(ctx context.Context, serve func() error) error {
-	errCh := make(chan error, 1)
-	go func() { errCh <- serve() }()
-
-	select {
-	case err := <-errCh:
+func (s *Server) setup() error {
+	if err := s.checkTemplateURLs(s.template); err != nil {
 		return err
-	case <-ctx.Done():
-		return s.Shutdown(context.WithoutCancel(ctx))
 	}
-}
-
-func (s *Server) setup() error {
 	if err := s.setupDefaultListener(); err != nil {
 		return err
 	}
@@ -120,24 +111,95 @@ func (s *Server) url() string {
 // Uses Server for configuration.
 // Uses Route for route configuration. Optional.
 func HTTPHandler[ReturnType, Body, Params any](s *Server, controller func(c Context[Body, Params]) (ReturnType, error), route BaseRoute) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+	options := s.readOptions(route)
+	serializer, errorSerializer := s.serializers(route)
+	errorHandler := s.errorHandler(route)
+
+	newContext := func(w http.ResponseWriter, r *http.Request) *netHttpContext[Body, Params] {
 		var templates *template.Template
 		if s.template != nil {
 			templates = template.Must(s.template.Clone())
 		}
 
+		if s.jsonCodec != nil {
+			r = r.WithContext(withJSONCodec(r.Context(), s.jsonCodec))
+		}
+		r = r.WithContext(withServerTimings(r.Context()))
+
 		// CONTEXT INITIALIZATION
-		ctx := NewNetHTTPContext[Body, Params](route, w, r, readOptions{
-			DisallowUnknownFields: s.DisallowUnknownFields,
-			MaxBodySize:           s.maxBodySize,
-		})
-		ctx.serializer = s.Serialize
-		ctx.errorSerializer = s.SerializeError
+		ctx := NewNetHTTPContext[Body, Params](route, w, r, options)
+		ctx.URLBuilder = s.Engine.URLFor
+		ctx.serializer = serializer
+		ctx.errorSerializer = errorSerializer
 		ctx.fs = s.fs
 		ctx.templates = templates
 
-		Flow(s.Engine, ctx, controller)
+		return ctx
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		Flow(s.Engine, newContext(w, r), controller)
+	}
+
+	if route.Timeout <= 0 {
+		return handler
+	}
+
+	return withTimeout(http.HandlerFunc(handler), route.Timeout, route.timeoutStatusCode(), func(w http.ResponseWriter, r *http.Request, err error) {
+		observeError(r.Context(), err)
+		ctx := newContext(w, r)
+		ctx.SerializeError(errorHandler(r.Context(), err))
+	}).ServeHTTP
+}
+
+// readOptions returns the request body options of the server, overridden by the ones of the route or its group.
+func (s *Server) readOptions(route BaseRoute) readOptions {
+	options := readOptions{
+		DisallowUnknownFields: s.DisallowUnknownFields,
+		MaxBodySize:           s.maxBodySize,
+		JSONCodec:             s.jsonCodec,
+	}
+	if route.DisallowUnknownFields != nil {
+		options.DisallowUnknownFields = *route.DisallowUnknownFields
+	}
+	if route.MaxBodySize != nil {
+		options.MaxBodySize = *route.MaxBodySize
+	}
+	return options
+}
+
+// serializers returns the serializers of the server, overridden by the ones of the route or its group.
+// They are nil for the default serializers, which negotiate among the response content types of the route.
+func (s *Server) serializers(route BaseRoute) (Sender, ErrorSender) {
+	var serializer Sender
+	var errorSerializer ErrorSender
+	// The serializers can also be overridden by setting the fields after NewServer
+	if s.customSerializer || !sameFunc(s.Serialize, Send) {
+		serializer = s.Serialize
+	}
+	if s.customErrorSerializer || !sameFunc(s.SerializeError, SendError) {
+		errorSerializer = s.SerializeError
+	}
+	if route.Serializer != nil {
+		serializer = route.Serializer
+	}
+	if route.ErrorSerializer != nil {
+		errorSerializer = route.ErrorSerializer
 	}
+	return serializer, errorSerializer
+}
+
+// sameFunc reports whether two functions are the same top-level function.
+func sameFunc(a, b any) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// errorHandler returns the error handler of the engine, overridden by the one of the route or its group.
+func (s *Server) errorHandler(route BaseRoute) func(context.Context, error) error {
+	if route.ErrorHandler != nil {
+		return route.ErrorHandler
+	}
+	return s.ErrorHandler
 }
 
 // ContextFlowable contains the logic for the flow of a Fuego controller.
@@ -153,61 +215,115 @@ type ContextFlowable[B, P any] interface {
 	SerializeError(err error)
 }
 
+// routeErrorHandlerCtx is implemented by the contexts of the adaptors supporting [OptionErrorHandler].
+type routeErrorHandlerCtx interface {
+	routeErrorHandler() func(context.Context, error) error
+}
+
 // Flow is generic handler for Fuego controllers.
 func Flow[B, T, P any](s *Engine, ctx ContextFlowable[B, P], controller func(c Context[B, P]) (T, error)) {
 	ctx.SetHeader("X-Powered-By", "Fuego")
 
+	errorHandler := s.ErrorHandler
+	if routeCtx, ok := ctx.(routeErrorHandlerCtx); ok && routeCtx.routeErrorHandler() != nil {
+		errorHandler = routeCtx.routeErrorHandler()
+	}
+
+	timings := serverTimingsFrom(ctx.Context())
+	if timings == nil {
+		timings = &serverTimings{}
+	}
+	// writeTimings writes the timings recorded since the last call in the Server-Timing header,
+	// or in the Server-Timing trailer once the body is written.
+	writeTimings := func() {
+		if value := timings.unwritten(); value != "" && !s.disableServerTiming {
+			ctx.SetHeader("Server-Timing", value)
+		}
+	}
+
+	// fail notifies the error observers and sends the error through the error handler.
+	fail := func(err error) {
+		writeTimings()
+		observeError(ctx.Context(), err)
+		ctx.SerializeError(errorHandler(ctx, err))
+	}
+
 	timeCtxInit := time.Now()
 
 	// PARAMS VALIDATION
 	err := ValidateParams(ctx)
 	if err != nil {
-		ctx.SetHeader("Trailer", "Server-Timing")
-		err = s.ErrorHandler(ctx, err)
-		ctx.SerializeError(err)
+		recordTiming(ctx.Context(), timings, Timing{"fuegoReqInit", "", time.Since(timeCtxInit)}, timeCtxInit)
+		fail(err)
+		return
+	}
+
+	// PRECONDITIONS
+	err = checkRouteIfMatch(ctx, ctx.Request())
+	if err != nil {
+		recordTiming(ctx.Context(), timings, Timing{"fuegoReqInit", "", time.Since(timeCtxInit)}, timeCtxInit)
+		fail(err)
 		return
 	}
 
 	timeController := time.Now()
-	ctx.SetHeader("Server-Timing", Timing{"fuegoReqInit", "", timeController.Sub(timeCtxInit)}.String())
+	recordTiming(ctx.Context(), timings, Timing{"fuegoReqInit", "", timeController.Sub(timeCtxInit)}, timeCtxInit)
 
 	// CONTROLLER
 	ans, err := controller(ctx)
+	recordTiming(ctx.Context(), timings, Timing{"controller", "", time.Since(timeController)}, timeController)
 
 	if !isNilError(err) {
-		ctx.SetHeader("Trailer", "Server-Timing")
-		err = s.ErrorHandler(ctx, err)
-		ctx.SerializeError(err)
+		fail(err)
 		return
 	}
-	ctx.SetHeader("Server-Timing", Timing{"controller", "", time.Since(timeController)}.String())
+
+	// CONDITIONAL REQUEST
+	if etag, ok := etagOf(ans); ok && routeHasETag(ctx) {
+		ctx.SetHeader("ETag", etag)
+		if notModified(ctx.Request(), etag) {
+			writeTimings()
+			ctx.SetStatus(http.StatusNotModified)
+			return
+		}
+	}
 
 	ctx.SetDefaultStatusCode()
 
 	if reflect.TypeOf(ans) == nil {
+		writeTimings()
 		return
 	}
 
-	ctx.SetHeader("Trailer", "Server-Timing")
-
 	// TRANSFORM OUT
 	timeTransformOut := time.Now()
 	ans, err = transformOut(ctx.Context(), ans)
 	if err != nil {
-		err = s.ErrorHandler(ctx, err)
-		ctx.SerializeError(err)
+		fail(err)
 		return
 	}
 	timeAfterTransformOut := time.Now()
-	ctx.SetHeader("Server-Timing", Timing{"transformOut", "transformOut", timeAfterTransformOut.Sub(timeTransformOut)}.String())
+	recordTiming(ctx.Context(), timings, Timing{"transformOut", "transformOut", timeAfterTransformOut.Sub(timeTransformOut)}, timeTransformOut)
+
+	writeTimings()
+	if !s.disableServerTiming {
+		ctx.SetHeader("Trailer", "Server-Timing")
+	}
 
 	// SERIALIZATION
 	err = ctx.Serialize(ans)
 	if err != nil {
-		err = s.ErrorHandler(ctx, err)
-		ctx.SerializeError(err)
+		observeError(ctx.Context(), err)
+		ctx.SerializeError(errorHandler(ctx, err))
 	}
-	ctx.SetHeader("Server-Timing", Timing{"serialize", "", time.Since(timeAfterTransformOut)}.String())
+	recordTiming(ctx.Context(), timings, Timing{"serialize", "", time.Since(timeAfterTransformOut)}, timeAfterTransformOut)
+	writeTimings()
+}
+
+// recordTiming records the timing of a phase of [Flow] for the Server-Timing header, and notifies the phase observers.
+func recordTiming(ctx context.Context, timings *serverTimings, timing Timing, start time.Time) {
+	timings.add(timing)
+	observePhase(ctx, timing.Name, start, timing.Dur)
 }
 
 // check if err isNil. If error is of kind pointer
@@ -38,11 +38,15 @@ type Server struct {
 
 	template *template.Template // TODO: use preparsed templates
 
-	// Custom serializer that overrides the default one.
+	// Used to serialize the response. Defaults to [Send],
+	// restricted to the response content types of the route.
 	Serialize Sender
 	// Used to serialize the error response. Defaults to [SendError].
 	SerializeError ErrorSender
 
+	// Whether the serializers were overridden by [WithSerializer], [WithErrorSerializer] or [WithXML].
+	customSerializer, customErrorSerializer bool
+
 	startTime time.Time
 
 	Security Security
@@ -55,12 +59,28 @@ type Server struct {
 
 	loggingConfig LoggingConfig
 
+	shutdownConfig ShutdownConfig
+
+	// Health endpoints, nil if disabled. See [WithHealthChecks].
+	healthConfig *HealthConfig
+
+	// Metrics of the routes, nil if disabled. See [WithMetrics].
+	metrics *metrics
+
 	// routeOptions is used to store the options
 	// that will be applied of the route.
 	routeOptions []RouteOption
 
 	middlewares []func(http.Handler) http.Handler
 
+	// corsRoutes answers the preflight requests of the routes with a CORS policy.
+	corsRoutes *corsRoutes
+
+	// Version of the API served by the group, see [Version].
+	version *versionedAPI
+	// versionRoutes selects the version from the request, nil to select it from the path. See [WithVersioning].
+	versionRoutes *versionRoutes
+
 	maxBodySize int64
 	// If true, the server will return an error if the request body contains unknown fields. Useful for quick debugging in development.
 	DisallowUnknownFields  bool
@@ -98,7 +118,12 @@ func NewServer(options ...ServerOption) *Server {
 
 		Security: NewSecurity(),
 
-		loggingConfig: defaultLoggingConfig,
+		Serialize:      Send,
+		SerializeError: SendError,
+
+		loggingConfig:  defaultLoggingConfig,
+		shutdownConfig: defaultShutdownConfig,
+		corsRoutes:     newCORSRoutes(),
 	}
 
 	// Default options that can be overridden
@@ -106,8 +131,6 @@ func NewServer(options ...ServerOption) *Server {
 		WithAddr("localhost:9999"),
 		WithDisallowUnknownFields(true),
 		WithMaxBodySize(maxBodySize),
-		WithSerializer(Send),
-		WithErrorSerializer(SendError),
 		WithRouteOptions(
 			OptionAddResponse(http.StatusBadRequest, "Bad Request _(validation or deserialization error)_", Response{Type: HTTPError{}}),
 			OptionAddResponse(http.StatusInternalServerError, "Internal Server Error _(panics)_", Response{Type: HTTPError{}}),
@@ -140,6 +163,14 @@ func NewServer(options ...ServerOption) *Server {
 		)
 	}
 
+	// Registered before the logger so that probes do not flood the logs.
+	if s.healthConfig != nil {
+		s.registerHealthRoutes()
+	}
+	if s.metrics != nil {
+		GetStd(s, s.metrics.config.Path, s.metrics.ServeHTTP, optionInternal(), OptionHide())
+	}
+
 	if !s.loggingConfig.Disabled() {
 		s.middlewares = append(s.middlewares, newDefaultLogger(s).middleware)
 	}
@@ -148,13 +179,25 @@ func NewServer(options ...ServerOption) *Server {
 }
 
 func (s *Server) SpecHandler(_ *Engine) {
-	Get(s, s.OpenAPI.Config.SpecURL, s.Engine.SpecHandler(), OptionHide(), OptionMiddleware(s.OpenAPI.Config.SwaggerMiddlewares...))
+	Get(s, s.OpenAPI.Config.SpecURL, s.Engine.SpecHandler(), optionInternal(), OptionHide(), OptionMiddleware(s.OpenAPI.Config.SwaggerMiddlewares...))
 	s.printOpenAPIMessage(fmt.Sprintf("JSON spec: %s%s", s.url(), s.OpenAPI.Config.SpecURL))
+
+	for _, v := range s.versionedAPIs() {
+		Get(s, v.OpenAPI.Config.SpecURL, func(c ContextNoBody) (openapi3.T, error) {
+			return *v.OpenAPI.Description(), nil
+		}, optionInternal(), OptionHide(), OptionMiddleware(s.OpenAPI.Config.SwaggerMiddlewares...))
+		s.printOpenAPIMessage(fmt.Sprintf("JSON spec of %s: %s%s", v.Name, s.url(), v.OpenAPI.Config.SpecURL))
+	}
 }
 
 func (s *Server) UIHandler(_ *Engine) {
-	GetStd(s, s.OpenAPI.Config.SwaggerURL+"/", s.OpenAPI.Config.UIHandler(s.OpenAPI.Config.SpecURL).ServeHTTP, OptionHide(), OptionMiddleware(s.OpenAPI.Config.SwaggerMiddlewares...))
+	GetStd(s, s.OpenAPI.Config.SwaggerURL+"/", s.OpenAPI.Config.UIHandler(s.OpenAPI.Config.SpecURL).ServeHTTP, optionInternal(), OptionHide(), OptionMiddleware(s.OpenAPI.Config.SwaggerMiddlewares...))
 	s.printOpenAPIMessage(fmt.Sprintf("OpenAPI UI: %s%s/index.html", s.url(), s.OpenAPI.Config.SwaggerURL))
+
+	for _, v := range s.versionedAPIs() {
+		GetStd(s, v.OpenAPI.Config.SwaggerURL+"/", s.OpenAPI.Config.UIHandler(v.OpenAPI.Config.SpecURL).ServeHTTP, optionInternal(), OptionHide(), OptionMiddleware(s.OpenAPI.Config.SwaggerMiddlewares...))
+		s.printOpenAPIMessage(fmt.Sprintf("OpenAPI UI of %s: %s%s/index.html", v.Name, s.url(), v.OpenAPI.Config.SwaggerURL))
+	}
 }
 
 // WithTemplateFS sets the filesystem used to load templates.
@@ -190,6 +233,8 @@ func WithTemplateFS(fsys fs.FS) ServerOption {
 //			AllowCredentials: true,
 //		}).Handler),
 //	)
+//
+// The built-in [OptionCORS] knows the methods registered on each path to answer the preflight requests.
 func WithGlobalMiddlewares(middlewares ...func(http.Handler) http.Handler) ServerOption {
 	return func(c *Server) {
 		c.globalMiddlewares = append(c.globalMiddlewares, middlewares...)
@@ -262,13 +307,14 @@ func WithoutAutoGroupTags() ServerOption {
 
 // WithTemplates loads the templates used to render HTML.
 // To be used with [WithTemplateFS]. If not set, it will use the os filesystem, at folder "./templates".
+// Templates using the functions provided by fuego, like urlFor, must be parsed with [TemplateFuncs].
 func WithTemplates(templates *template.Template) ServerOption {
 	return func(s *Server) {
 		if s.fs == nil {
 			s.fs = os.DirFS("./templates")
 			slog.Warn("No template filesystem set. Using os filesystem at './templates'.")
 		}
-		s.template = templates
+		s.template = templates.Funcs(s.Engine.templateFuncs())
 
 		slog.Debug("Loaded templates", "templates", s.template.DefinedTemplates())
 	}
@@ -337,6 +383,7 @@ func WithXML() ServerOption {
 	return func(c *Server) {
 		c.Serialize = SendXML
 		c.SerializeError = SendXMLError
+		c.customSerializer, c.customErrorSerializer = true, true
 	}
 }
 
@@ -352,13 +399,19 @@ func WithLogHandler(handler slog.Handler) ServerOption {
 // WithSerializer sets a custom serializer of type Sender that overrides the default one.
 // Please send a PR if you think the default serializer should be improved, instead of jumping to this option.
 func WithSerializer(serializer Sender) ServerOption {
-	return func(c *Server) { c.Serialize = serializer }
+	return func(c *Server) {
+		c.Serialize = serializer
+		c.customSerializer = true
+	}
 }
 
 // WithErrorSerializer sets a custom serializer of type ErrorSender that overrides the default one.
 // Please send a PR if you think the default serializer should be improved, instead of jumping to this option.
 func WithErrorSerializer(serializer ErrorSender) ServerOption {
-	return func(c *Server) { c.SerializeError = serializer }
+	return func(c *Server) {
+		c.SerializeError = serializer
+		c.customErrorSerializer = true
+	}
 }
 
 // WithoutStartupMessages disables the startup message
@@ -361,6 +361,33 @@ func TestCustomSerialization(t *testing.T) {
 	require.Equal(t, "custom serialization", w.Body.String())
 }
 
+func TestDefaultSerializers(t *testing.T) {
+	s := NewServer()
+	require.NotNil(t, s.Serialize)
+	require.NotNil(t, s.SerializeError)
+
+	t.Run("can be called directly", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		require.NoError(t, s.Serialize(w, httptest.NewRequest("GET", "/", nil), ans{Ans: "Hello World"}))
+		require.JSONEq(t, `{"ans":"Hello World"}`, w.Body.String())
+	})
+
+	t.Run("can be overridden after NewServer", func(t *testing.T) {
+		s := NewServer()
+		s.Serialize = func(w http.ResponseWriter, r *http.Request, a any) error {
+			_, err := w.Write([]byte("custom serialization"))
+			return err
+		}
+		Get(s, "/", func(c ContextNoBody) (ans, error) {
+			return ans{Ans: "Hello World"}, nil
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		require.Equal(t, "custom serialization", w.Body.String())
+	})
+}
+
 func TestGroupParams(t *testing.T) {
 	s := NewServer()
 	group := Group(s, "/api",
@@ -0,0 +1,275 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// ContentTypeNDJSON is the content type used to stream newline-delimited JSON.
+	// See https://github.com/ndjson/ndjson-spec
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// StreamErrorTrailer is the trailer set when an error happens after the stream has started.
+	// At that point the status code is already sent, so the error can only be reported in the trailer
+	// (and, for NDJSON, as a last `{"error": ...}` line).
+	StreamErrorTrailer = "X-Stream-Error"
+
+	streamFlushEvery    = 64
+	streamFlushInterval = 200 * time.Millisecond
+)
+
+// streamError is the last line written to a NDJSON stream when an error happens mid-stream.
+type streamError struct {
+	Error HTTPError `json:"error"`
+}
+
+var (
+	errorType = reflect.TypeFor[error]()
+	boolType  = reflect.TypeFor[bool]()
+)
+
+// streamElemType returns the element type of a streamable type.
+// Streamable types are [iter.Seq], [iter.Seq2] with an error as second value, and receivable channels.
+func streamElemType(t reflect.Type) (reflect.Type, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	switch t.Kind() {
+	case reflect.Chan:
+		if t.ChanDir()&reflect.RecvDir == 0 {
+			return nil, false
+		}
+		return t.Elem(), true
+	case reflect.Func:
+		if t.NumIn() != 1 || t.NumOut() != 0 {
+			return nil, false
+		}
+		yield := t.In(0)
+		if yield.Kind() != reflect.Func || yield.NumOut() != 1 || yield.Out(0) != boolType {
+			return nil, false
+		}
+		switch yield.NumIn() {
+		case 1:
+			return yield.In(0), true
+		case 2:
+			if yield.In(1) != errorType {
+				return nil, false
+			}
+			return yield.In(0), true
+		}
+	}
+
+	return nil, false
+}
+
+// isStream returns true if the value can be streamed by [Send].
+func isStream(ans any) bool {
+	_, ok := streamElemType(reflect.TypeOf(ans))
+	return ok
+}
+
+// rangeStream iterates over a streamable value, calling yield for each element.
+// It stops when yield returns false, when the stream is exhausted or when the context is canceled.
+func rangeStream(ctx context.Context, stream any, yield func(elem any, err error) bool) {
+	v := reflect.ValueOf(stream)
+	if v.IsNil() {
+		return
+	}
+
+	if v.Kind() == reflect.Chan {
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: v},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		}
+		for {
+			chosen, elem, ok := reflect.Select(cases)
+			if chosen == 1 || !ok {
+				return
+			}
+			if !yield(elem.Interface(), nil) {
+				return
+			}
+		}
+	}
+
+	yieldType := v.Type().In(0)
+	reflectYield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+		if ctx.Err() != nil {
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+
+		var err error
+		if len(args) == 2 {
+			err, _ = args[1].Interface().(error)
+		}
+
+		return []reflect.Value{reflect.ValueOf(yield(args[0].Interface(), err))}
+	})
+	v.Call([]reflect.Value{reflectYield})
+}
+
+// SendStream streams an [iter.Seq], an [iter.Seq2] with an error as second value, or a channel.
+// The format is determined by the Accept header:
+//   - `application/x-ndjson`: one JSON value per line
+//   - `application/json` (default): a JSON array, written element by element
+//   - `text/csv`: one row per element, for streams of structs (see [SendCSV])
+//
+// The response is flushed periodically and the stream stops when the request context is canceled.
+// Errors happening before the first element is written are returned, so they can be sent with the right status code.
+// After that, they are reported in the [StreamErrorTrailer] trailer and, for NDJSON, as a last `{"error": ...}` line.
+// A JSON array is left unterminated, so that clients parsing it fail instead of reading a truncated list.
+func SendStream(w http.ResponseWriter, r *http.Request, stream any) error {
+	if !isStream(stream) {
+		return fmt.Errorf("cannot stream type %T: expected iter.Seq, iter.Seq2[T, error] or a channel", stream)
+	}
+
+	elemType, _ := streamElemType(reflect.TypeOf(stream))
+	AddVary(w.Header(), "Accept")
+	contentType := negotiateStreamContentType(r, elemType)
+	if contentType == "" {
+		return NotAcceptableError{
+			Detail: "streams can only be sent as " + ContentTypeNDJSON + ", " + ContentTypeCSV + " (for structs) or application/json, got: " + r.Header.Get("Accept"),
+		}
+	}
+	ndjson := contentType == ContentTypeNDJSON
+
+	ctx := requestContext(r)
+	codec := jsonCodecFromContext(ctx)
+	if contentType == ContentTypeCSV {
+		return writeCSV(w, r, elemType, true, func(yield func(any, error) bool) {
+			rangeStream(ctx, stream, func(elem any, err error) bool {
+				if err == nil {
+					elem, err = transformOut(ctx, elem)
+				}
+				return yield(elem, err)
+			})
+		})
+	}
+	flusher, _ := w.(http.Flusher)
+	started := false
+	lastFlush := time.Now()
+	unflushed := 0
+
+	var streamErr error
+	rangeStream(ctx, stream, func(elem any, err error) bool {
+		if err == nil {
+			elem, err = transformOut(ctx, elem)
+		}
+		var data []byte
+		if err == nil {
+			data, err = codec.Marshal(elem)
+		}
+		if err != nil {
+			streamErr = err
+			return false
+		}
+
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Add("Trailer", StreamErrorTrailer)
+			if !ndjson {
+				data = append([]byte("["), data...)
+			}
+		} else if !ndjson {
+			data = append([]byte(","), data...)
+		}
+		if ndjson {
+			data = append(data, '\n')
+		}
+
+		if _, err = w.Write(data); err != nil {
+			slog.DebugContext(ctx, "Stream interrupted", "error", err)
+			return false
+		}
+
+		unflushed++
+		if flusher != nil && (unflushed >= streamFlushEvery || time.Since(lastFlush) >= streamFlushInterval) {
+			flusher.Flush()
+			lastFlush = time.Now()
+			unflushed = 0
+		}
+		return true
+	})
+
+	if !started {
+		if streamErr != nil {
+			return streamErr
+		}
+		w.Header().Set("Content-Type", contentType)
+		if ndjson {
+			return nil
+		}
+		_, err := w.Write([]byte("[]"))
+		return err
+	}
+
+	if streamErr != nil {
+		httpError := setStreamErrorTrailer(ctx, w, streamErr)
+		if ndjson {
+			data, err := codec.Marshal(streamError{Error: httpError})
+			if err == nil {
+				_, _ = w.Write(append(data, '\n'))
+			}
+		}
+	}
+	if !ndjson && streamErr == nil {
+		_, _ = w.Write([]byte("]"))
+	}
+
+	if ctx.Err() != nil {
+		slog.DebugContext(ctx, "Stream canceled by the client", "error", ctx.Err())
+	}
+
+	return nil
+}
+
+// setStreamErrorTrailer reports an error happening after the stream has started in the [StreamErrorTrailer] trailer.
+// Like [SendError], the status code comes from the [ErrorWithStatus] in the error chain.
+func setStreamErrorTrailer(ctx context.Context, w http.ResponseWriter, err error) HTTPError {
+	err = HandleHTTPError(ctx, err)
+
+	httpError := HTTPError{Err: err}
+	if v := castHTTPError(err); v != nil {
+		httpError = *v
+	}
+	var errorStatus ErrorWithStatus
+	if errors.As(err, &errorStatus) {
+		httpError.Status = errorStatus.StatusCode()
+	}
+	httpError.Status = httpError.StatusCode()
+	if httpError.Title == "" {
+		httpError.Title = http.StatusText(httpError.Status)
+	}
+	w.Header().Set(StreamErrorTrailer, httpError.PublicError())
+	return httpError
+}
+
+// negotiateStreamContentType returns the content type to use for a stream, or an empty string if none is acceptable.
+func negotiateStreamContentType(r *http.Request, elemType reflect.Type) string {
+	offers := []string{"application/json", ContentTypeNDJSON}
+	if isCSVStruct(elemType) {
+		offers = append(offers, ContentTypeCSV)
+	}
+	return NegotiateContentType(r.Header.Get("Accept"), offers)
+}
+
+// newStreamContent documents a stream: a JSON array of elements, and one element per line for NDJSON.
+func newStreamContent(arraySchema SchemaTag) openapi3.Content {
+	content := openapi3.Content{
+		"application/json": openapi3.NewMediaType().WithSchemaRef(&arraySchema.SchemaRef),
+	}
+	if arraySchema.Value != nil && arraySchema.Value.Items != nil {
+		content[ContentTypeNDJSON] = openapi3.NewMediaType().WithSchemaRef(arraySchema.Value.Items)
+	}
+	return content
+}
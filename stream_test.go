@@ -0,0 +1,222 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func responsesSeq(n int) iter.Seq[response] {
+	return func(yield func(response) bool) {
+		for i := range n {
+			if !yield(response{Message: "hello", Code: i}) {
+				return
+			}
+		}
+	}
+}
+
+func TestSendStream(t *testing.T) {
+	t.Run("iter.Seq as JSON array by default", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, responsesSeq(3))
+		require.NoError(t, err)
+
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `[{"message":"hello","code":0},{"message":"hello","code":1},{"message":"hello","code":2}]`, w.Body.String())
+	})
+
+	t.Run("iter.Seq as NDJSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", ContentTypeNDJSON)
+
+		err := Send(w, r, responsesSeq(2))
+		require.NoError(t, err)
+
+		require.Equal(t, ContentTypeNDJSON, w.Header().Get("Content-Type"))
+		require.Equal(t, `{"message":"hello","code":0}`+"\n"+`{"message":"hello","code":1}`+"\n", w.Body.String())
+	})
+
+	t.Run("empty stream", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, responsesSeq(0))
+		require.NoError(t, err)
+		require.Equal(t, "[]", w.Body.String())
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		close(ch)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, (<-chan int)(ch))
+		require.NoError(t, err)
+		require.Equal(t, "[1,2,3]", w.Body.String())
+	})
+
+	t.Run("error before the first element is returned", func(t *testing.T) {
+		seq := func(yield func(response, error) bool) {
+			yield(response{}, NotFoundError{Title: "nothing"})
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, iter.Seq2[response, error](seq))
+		require.ErrorAs(t, err, &NotFoundError{})
+		require.Empty(t, w.Body.String())
+	})
+
+	t.Run("mid-stream error in NDJSON", func(t *testing.T) {
+		seq := func(yield func(response, error) bool) {
+			if !yield(response{Message: "first"}, nil) {
+				return
+			}
+			yield(response{}, errors.New("database is down"))
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", ContentTypeNDJSON)
+
+		err := Send(w, r, iter.Seq2[response, error](seq))
+		require.NoError(t, err)
+		require.Equal(t, `{"message":"first","code":0}`+"\n"+`{"error":{"title":"Internal Server Error","status":500}}`+"\n", w.Body.String())
+		require.Equal(t, StreamErrorTrailer, w.Header().Get("Trailer"))
+		require.Equal(t, "500 Internal Server Error", w.Header().Get(StreamErrorTrailer))
+	})
+
+	t.Run("mid-stream error in JSON array leaves the array unterminated", func(t *testing.T) {
+		seq := func(yield func(response, error) bool) {
+			if !yield(response{Message: "first"}, nil) {
+				return
+			}
+			yield(response{}, ConflictError{Detail: "conflict"})
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := Send(w, r, iter.Seq2[response, error](seq))
+		require.NoError(t, err)
+		require.Equal(t, `[{"message":"first","code":0}`, w.Body.String())
+		require.False(t, json.Valid(w.Body.Bytes()), "clients must not parse a truncated array")
+		require.Equal(t, "409 Conflict (conflict)", w.Header().Get(StreamErrorTrailer))
+	})
+
+	t.Run("mid-stream errors with a status", func(t *testing.T) {
+		for _, tc := range []struct {
+			err     error
+			trailer string
+		}{
+			{BadRequestError{Title: "invalid cursor"}, "400 invalid cursor"},
+			{fmt.Errorf("listing: %w", NotFoundError{Detail: "no more pages"}), "404 Not Found (no more pages)"},
+			{teapotError{}, "418 I'm a teapot"},
+		} {
+			seq := func(yield func(response, error) bool) {
+				if !yield(response{Message: "first"}, nil) {
+					return
+				}
+				yield(response{}, tc.err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", ContentTypeNDJSON)
+
+			err := Send(w, r, iter.Seq2[response, error](seq))
+			require.NoError(t, err)
+			require.Equal(t, tc.trailer, w.Header().Get(StreamErrorTrailer))
+			require.Contains(t, w.Body.String(), `"status":`+tc.trailer[:3])
+		}
+	})
+
+	t.Run("stops when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		seq := func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if i == 2 {
+					cancel()
+				}
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		r.Header.Set("Accept", ContentTypeNDJSON)
+
+		err := Send(w, r, iter.Seq[int](seq))
+		require.NoError(t, err)
+		require.Equal(t, "0\n1\n", w.Body.String())
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "application/xml")
+
+		err := Send(w, r, responsesSeq(1))
+		require.ErrorAs(t, err, &NotAcceptableError{})
+	})
+}
+
+func TestStreamController(t *testing.T) {
+	s := NewServer()
+
+	route := Get(s, "/stream", func(c ContextNoBody) (iter.Seq[response], error) {
+		return responsesSeq(2), nil
+	})
+
+	t.Run("streams the response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		r.Header.Set("Accept", ContentTypeNDJSON)
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, `{"message":"hello","code":0}`+"\n"+`{"message":"hello","code":1}`+"\n", w.Body.String())
+	})
+
+	t.Run("documents the element schema", func(t *testing.T) {
+		content := route.Operation.Responses.Value("200").Value.Content
+		contentTypes := make([]string, 0, len(content))
+		for contentType := range content {
+			contentTypes = append(contentTypes, contentType)
+		}
+		slices.Sort(contentTypes)
+		assert.Equal(t, []string{"application/json", ContentTypeNDJSON, ContentTypeCSV}, contentTypes)
+
+		require.True(t, content["application/json"].Schema.Value.Type.Is("array"))
+		assert.Equal(t, "#/components/schemas/response", content["application/json"].Schema.Value.Items.Ref)
+		assert.Equal(t, "#/components/schemas/response", content[ContentTypeNDJSON].Schema.Ref)
+	})
+}
+
+// teapotError is an error with a status code, but not based on [HTTPError].
+type teapotError struct{}
+
+func (teapotError) Error() string   { return "teapot" }
+func (teapotError) StatusCode() int { return http.StatusTeapot }
@@ -1,8 +1,10 @@
 package testingfromoutside_test
 
 import (
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/rs/cors"
 	"github.com/stretchr/testify/require"
@@ -38,3 +40,162 @@ func TestCors(t *testing.T) {
 		require.Equal(t, 200, w.Code)
 	})
 }
+
+func TestOptionCORS(t *testing.T) {
+	s := fuego.NewServer(
+		fuego.WithoutLogger(),
+		fuego.WithRouteOptions(
+			fuego.OptionCORS(fuego.CORSConfig{
+				AllowedOrigins: []string{"https://app.example.com"},
+				MaxAge:         time.Hour,
+			}),
+		),
+	)
+
+	fuego.Get(s, "/pets", func(c fuego.ContextNoBody) (string, error) {
+		return "pets", nil
+	}, fuego.OptionResponseHeader("X-Total-Count", "Number of pets"))
+	fuego.Post(s, "/pets", func(c fuego.ContextNoBody) (string, error) {
+		return "created", nil
+	})
+	fuego.Delete(s, "/pets/{id}", func(c fuego.ContextNoBody) (string, error) {
+		return "deleted", nil
+	})
+
+	partners := fuego.Group(s, "/partners", fuego.OptionCORS(fuego.CORSConfig{
+		AllowedOrigins:   []string{"https://*.partner.com"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"X-Request-Id"},
+		AllowCredentials: true,
+	}))
+	fuego.Use(partners, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				fuego.SendError(w, r, fuego.UnauthorizedError{Title: "Unauthorized"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	fuego.Put(partners, "/orders", func(c fuego.ContextNoBody) (string, error) {
+		return "updated", nil
+	})
+
+	fuego.Get(s, "/internal", func(c fuego.ContextNoBody) (string, error) {
+		return "internal", nil
+	}, func(r *fuego.BaseRoute) { r.CORS = nil })
+
+	preflight := func(t *testing.T, path, origin, method string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodOptions, path, nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		r.Header.Set("Access-Control-Request-Headers", "content-type")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("answers preflight requests with the registered methods", func(t *testing.T) {
+		w := preflight(t, "/pets", "https://app.example.com", http.MethodPost)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "GET, HEAD, POST", w.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "content-type", w.Header().Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+		w = preflight(t, "/pets/123", "https://app.example.com", http.MethodDelete)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("rejects unknown origins", func(t *testing.T) {
+		w := preflight(t, "/pets", "https://evil.com", http.MethodGet)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+		require.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("adds CORS headers to the responses", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/pets", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "pets", w.Body.String())
+		require.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "X-Total-Count", w.Header().Get("Access-Control-Expose-Headers"))
+		require.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("group policy with credentials", func(t *testing.T) {
+		w := preflight(t, "/partners/orders", "https://shop.partner.com", http.MethodPut)
+
+		require.Equal(t, http.StatusNoContent, w.Code, "preflight requests do not run the route middlewares")
+		require.Equal(t, "https://shop.partner.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, "PUT", w.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+
+		require.Empty(t, preflight(t, "/partners/orders", "https://app.example.com", http.MethodPut).Header().Get("Access-Control-Allow-Origin"))
+
+		r := httptest.NewRequest(http.MethodPut, "/partners/orders", nil)
+		r.Header.Set("Origin", "https://shop.partner.com")
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusUnauthorized, w.Code, "errors of the middlewares are readable by the browser")
+		require.Equal(t, "https://shop.partner.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "X-Request-Id", w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("routes without CORS policy", func(t *testing.T) {
+		w := preflight(t, "/internal", "https://app.example.com", http.MethodGet)
+
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("OPTIONS requests that are not preflight requests", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodOptions, "/pets", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		require.Equal(t, "GET, HEAD, POST, OPTIONS", w.Header().Get("Allow"))
+	})
+}
+
+func TestOptionCORSWithOptionsRoute(t *testing.T) {
+	s := fuego.NewServer(fuego.WithoutLogger())
+
+	fuego.Options(s, "/items", func(c fuego.ContextNoBody) (string, error) {
+		c.SetHeader("Allow", "GET, OPTIONS")
+		return "", nil
+	})
+	fuego.Get(s, "/items", func(c fuego.ContextNoBody) (string, error) {
+		return "items", nil
+	}, fuego.OptionCORS(fuego.CORSConfig{}))
+
+	r := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "GET, HEAD", w.Header().Get("Access-Control-Allow-Methods"))
+
+	r = httptest.NewRequest(http.MethodOptions, "/items", nil)
+	w = httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "GET, OPTIONS", w.Header().Get("Allow"))
+}
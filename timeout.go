@@ -0,0 +1,193 @@
+package fuego
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OptionTimeout sets a deadline to the requests of the route, or of all the routes of a group.
+// The last timeout set wins, so a route can override the one of its group.
+//
+//   - The context of the request has the deadline: controllers and the functions they call
+//     should observe c.Context().Done() to stop working.
+//   - If the deadline passes before the response is written, a 503 Service Unavailable [ServiceUnavailableError]
+//     is sent through the error handler and the error serializer, and the late writes of the controller are discarded.
+//     Pass http.StatusGatewayTimeout as statusCode to send a 504 Gateway Timeout instead.
+//   - If the response was already started, the deadline only cancels the context.
+//
+// The 503 or 504 response is documented in the OpenAPI spec.
+func OptionTimeout(timeout time.Duration, statusCode ...int) RouteOption {
+	status := http.StatusServiceUnavailable
+	if len(statusCode) > 1 {
+		panic("OptionTimeout: only one status code is allowed")
+	}
+	if len(statusCode) == 1 {
+		status = statusCode[0]
+	}
+	if status != http.StatusServiceUnavailable && status != http.StatusGatewayTimeout {
+		panic(fmt.Sprintf("OptionTimeout: status code must be 503 or 504, got %d", status))
+	}
+
+	return func(r *BaseRoute) {
+		r.Timeout = timeout
+		r.TimeoutStatusCode = status
+	}
+}
+
+// withTimeout runs the handler with a deadline, and sends a [ServiceUnavailableError], or a 504 Gateway Timeout
+// [HTTPError] if statusCode is 504, with sendError if the deadline passes before the handler starts writing the response.
+func withTimeout(h http.Handler, timeout time.Duration, statusCode int, sendError ErrorSender) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{ctx: ctx, w: w, header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			h.ServeHTTP(tw, r.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case <-done:
+		case <-ctx.Done():
+			tw.mu.Lock()
+			started := tw.wroteHeader
+			tw.timedOut = !started
+			tw.mu.Unlock()
+			if started {
+				// Too late to send an error: lets the handler finish the response
+				select {
+				case p := <-panicked:
+					panic(p)
+				case <-done:
+				}
+			}
+		}
+
+		if tw.finish() {
+			return
+		}
+
+		err := HTTPError{
+			Err:    ctx.Err(),
+			Title:  "Request Timeout",
+			Detail: fmt.Sprintf("the request was not completed within %s", timeout),
+		}
+		if statusCode == http.StatusGatewayTimeout {
+			err.Status = statusCode
+			sendError(w, r, err)
+			return
+		}
+		sendError(w, r, ServiceUnavailableError(err))
+	})
+}
+
+// timeoutWriter is the [http.ResponseWriter] given to a handler with a deadline.
+// The headers are only copied to the response when it is started, and writes after the deadline are discarded.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	ctx         context.Context
+	w           http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+}
+
+var _ http.ResponseWriter = &timeoutWriter{}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+func (tw *timeoutWriter) WriteHeader(statusCode int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.writeHeader(statusCode)
+}
+
+// writeHeader is called with the lock held.
+func (tw *timeoutWriter) writeHeader(statusCode int) {
+	if !tw.wroteHeader && tw.ctx.Err() != nil {
+		// The deadline passed before the response was started
+		tw.timedOut = true
+	}
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+
+	dst := tw.w.Header()
+	for name, values := range tw.header {
+		dst[name] = values
+	}
+	tw.w.WriteHeader(statusCode)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.writeHeader(http.StatusOK)
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	return tw.w.Write(p)
+}
+
+// finish starts the response if the handler did not write anything,
+// and copies the values of the trailers, set after the response was started.
+// Called once the handler is done, or the deadline passed. Reports false if the request timed out.
+func (tw *timeoutWriter) finish() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.writeHeader(http.StatusOK)
+	if tw.timedOut {
+		return false
+	}
+
+	for _, declared := range tw.header.Values("Trailer") {
+		for name := range strings.SplitSeq(declared, ",") {
+			name = strings.TrimSpace(name)
+			if values, ok := tw.header[http.CanonicalHeaderKey(name)]; ok {
+				tw.w.Header()[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+	}
+	for name, values := range tw.header {
+		if strings.HasPrefix(name, http.TrailerPrefix) {
+			tw.w.Header()[name] = values
+		}
+	}
+
+	return true
+}
+
+func (tw *timeoutWriter) Flush() {
+	_ = tw.FlushError()
+}
+
+func (tw *timeoutWriter) FlushError() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.writeHeader(http.StatusOK)
+	if tw.timedOut {
+		return http.ErrHandlerTimeout
+	}
+
+	return http.NewResponseController(tw.w).Flush()
+}
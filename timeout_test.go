@@ -0,0 +1,183 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout(t *testing.T) {
+	s := NewServer()
+
+	lateWrite := make(chan error, 1)
+
+	Get(s, "/fast", func(c ContextNoBody) (ans, error) {
+		c.SetHeader("X-Test", "fast")
+		return ans{Ans: "fast"}, nil
+	}, OptionTimeout(time.Second))
+	Get(s, "/slow", func(c ContextNoBody) (ans, error) {
+		<-c.Context().Done()
+		return ans{}, c.Context().Err()
+	}, OptionTimeout(10*time.Millisecond))
+	Get(s, "/ignores-deadline", func(c ContextNoBody) (ans, error) {
+		time.Sleep(50 * time.Millisecond)
+		return ans{Ans: "too late"}, nil
+	}, OptionTimeout(10*time.Millisecond))
+	Get(s, "/streaming", func(c ContextNoBody) (any, error) {
+		w := c.Response()
+		_, _ = w.Write([]byte("started"))
+		http.NewResponseController(w).Flush()
+		<-c.Context().Done()
+		return nil, nil
+	}, OptionTimeout(10*time.Millisecond))
+	GetStd(s, "/std", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.Header().Set("X-Late", "true")
+		_, err := w.Write([]byte("late"))
+		lateWrite <- err
+	}, OptionTimeout(10*time.Millisecond))
+	GetStd(s, "/std-panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}, OptionTimeout(time.Second))
+
+	group := Group(s, "/group", OptionTimeout(10*time.Millisecond))
+	Get(group, "/inherited", func(c ContextNoBody) (string, error) {
+		<-c.Context().Done()
+		return "", c.Context().Err()
+	})
+	Get(group, "/overridden", func(c ContextNoBody) (string, error) {
+		select {
+		case <-c.Context().Done():
+			return "", c.Context().Err()
+		case <-time.After(50 * time.Millisecond):
+			return "overridden", nil
+		}
+	}, OptionTimeout(time.Second))
+
+	get := func(t *testing.T, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("responds before the deadline", func(t *testing.T) {
+		w := get(t, "/fast")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "fast", w.Header().Get("X-Test"))
+		require.JSONEq(t, `{"ans":"fast"}`, w.Body.String())
+	})
+
+	t.Run("sends a 503 after the deadline", func(t *testing.T) {
+		w := get(t, "/slow")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Contains(t, w.Header().Get("Content-Type"), "json")
+		require.Contains(t, w.Body.String(), `"title":"Request Timeout"`)
+		require.Contains(t, w.Body.String(), `"status":503`)
+	})
+
+	t.Run("discards the response of a controller ignoring the deadline", func(t *testing.T) {
+		w := get(t, "/ignores-deadline")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.NotContains(t, w.Body.String(), "too late")
+	})
+
+	t.Run("does not interrupt a started response", func(t *testing.T) {
+		w := get(t, "/streaming")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "started")
+		require.True(t, w.Flushed)
+	})
+
+	t.Run("discards the late writes of std controllers", func(t *testing.T) {
+		w := get(t, "/std")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.ErrorIs(t, <-lateWrite, http.ErrHandlerTimeout)
+		require.Empty(t, w.Header().Get("X-Late"))
+		require.NotContains(t, w.Body.String(), "late")
+	})
+
+	t.Run("propagates panics", func(t *testing.T) {
+		require.PanicsWithValue(t, "boom", func() { get(t, "/std-panic") })
+	})
+
+	t.Run("group timeout", func(t *testing.T) {
+		require.Equal(t, http.StatusServiceUnavailable, get(t, "/group/inherited").Code)
+
+		w := get(t, "/group/overridden")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "overridden", w.Body.String())
+	})
+
+	t.Run("documents the 503 response", func(t *testing.T) {
+		operation := s.OpenAPI.Description().Paths.Find("/slow").Get
+		assert.NotNil(t, operation.Responses.Value("503"))
+		operation = s.OpenAPI.Description().Paths.Find("/std").Get
+		assert.NotNil(t, operation.Responses.Value("503"))
+		operation = s.OpenAPI.Description().Paths.Find("/group/overridden").Get
+		assert.NotNil(t, operation.Responses.Value("503"))
+	})
+}
+
+func TestTimeoutWithErrorHandler(t *testing.T) {
+	s := NewServer(
+		WithEngineOptions(
+			WithErrorHandler(func(ctx context.Context, err error) error {
+				if errors.Is(err, context.DeadlineExceeded) {
+					return HTTPError{Err: err, Title: "Gateway Timeout", Status: http.StatusGatewayTimeout}
+				}
+				return ErrorHandler(ctx, err)
+			}),
+		),
+	)
+
+	Get(s, "/slow", func(c ContextNoBody) (string, error) {
+		<-c.Context().Done()
+		return "", nil
+	}, OptionTimeout(10*time.Millisecond))
+
+	r := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusGatewayTimeout, w.Code)
+	require.Contains(t, w.Body.String(), "Gateway Timeout")
+}
+
+func TestTimeoutStatusCode(t *testing.T) {
+	require.Panics(t, func() { OptionTimeout(time.Second, http.StatusRequestTimeout) })
+	require.Panics(t, func() { OptionTimeout(time.Second, http.StatusServiceUnavailable, http.StatusGatewayTimeout) })
+
+	s := NewServer()
+
+	route := Get(s, "/slow", func(c ContextNoBody) (string, error) {
+		<-c.Context().Done()
+		return "", c.Context().Err()
+	}, OptionTimeout(10*time.Millisecond, http.StatusGatewayTimeout))
+	GetStd(s, "/std", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}, OptionTimeout(10*time.Millisecond, http.StatusGatewayTimeout))
+
+	for _, path := range []string{"/slow", "/std"} {
+		t.Run(path, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusGatewayTimeout, w.Code)
+			require.Contains(t, w.Body.String(), "Request Timeout")
+		})
+	}
+
+	t.Run("documents the 504 response", func(t *testing.T) {
+		require.NotNil(t, route.Operation.Responses.Value("504"))
+		require.Nil(t, route.Operation.Responses.Value("503"))
+	})
+}
@@ -0,0 +1,324 @@
+package fuego
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"html/template"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// PathArgs are the values of the path parameters of a route, used to build its URL with [Engine.URLFor].
+// Values are formatted with [encoding.TextMarshaler], [fmt.Stringer] or [fmt.Sprint], in this order.
+//
+//	fuego.PathArgs{"id": 3}
+type PathArgs = map[string]any
+
+// ErrRouteNotFound is returned when building the URL of a route that is not registered.
+var ErrRouteNotFound = errors.New("route not found")
+
+// URLFor builds the URL of the route with the given name, see [OptionName].
+// The path parameters are replaced by the given args, and the query is appended.
+// The query can be nil, [url.Values], or a struct with `query` tags, like the params of a [Context].
+//
+//	url, err := s.URLFor("getRecipe", fuego.PathArgs{"id": 3}, url.Values{"lang": {"fr"}})
+//	// /recipes/3?lang=fr
+func (e *Engine) URLFor(name string, args PathArgs, query any) (string, error) {
+	route, ok := e.routeByName(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+
+	path, err := buildPath(route.Path, args)
+	if err != nil {
+		return "", fmt.Errorf("route %q: %w", name, err)
+	}
+
+	values, err := queryValues(query)
+	if err != nil {
+		return "", fmt.Errorf("route %q: %w", name, err)
+	}
+	if encoded := values.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+
+	return path, nil
+}
+
+// MustURLFor works like [Engine.URLFor], but panics if there is an error.
+// Use it at startup to fail fast, for example when declaring redirections.
+func (e *Engine) MustURLFor(name string, args PathArgs, query any) string {
+	u, err := e.URLFor(name, args, query)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (e *Engine) routeByName(name string) (BaseRoute, bool) {
+	e.routesMu.RLock()
+	defer e.routesMu.RUnlock()
+
+	for _, route := range e.routes {
+		if route.Name == name {
+			return route, true
+		}
+	}
+	return BaseRoute{}, false
+}
+
+// buildPath replaces the wildcards of a [net/http.ServeMux] pattern, like /recipes/{id} or /files/{path...}, by the given args.
+func buildPath(pattern string, args PathArgs) (string, error) {
+	var b strings.Builder
+	used := map[string]bool{}
+	for {
+		start := strings.Index(pattern, "{")
+		if start < 0 {
+			b.WriteString(pattern)
+			break
+		}
+		end := strings.Index(pattern[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("invalid path pattern %q", pattern)
+		}
+		b.WriteString(pattern[:start])
+		wildcard := pattern[start+1 : start+end]
+		pattern = pattern[start+end+1:]
+
+		if wildcard == "$" {
+			continue
+		}
+		name, multiple := strings.CutSuffix(wildcard, "...")
+		arg, ok := args[name]
+		if !ok {
+			return "", fmt.Errorf("missing path parameter %q", name)
+		}
+		used[name] = true
+
+		value := formatArg(arg)
+		if multiple {
+			// The pattern already has the slash before the wildcard
+			segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+	}
+
+	if len(used) != len(args) {
+		var unknown []string
+		for name := range maps.Keys(args) {
+			if !used[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		slices.Sort(unknown)
+		return "", fmt.Errorf("unknown path parameters %v", unknown)
+	}
+
+	return b.String(), nil
+}
+
+// queryValues converts the query given to [Engine.URLFor] to url.Values.
+func queryValues(query any) (url.Values, error) {
+	switch query := query.(type) {
+	case nil:
+		return nil, nil
+	case url.Values:
+		return query, nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range query {
+			values.Set(key, value)
+		}
+		return values, nil
+	}
+
+	v := reflect.ValueOf(query)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("query must be url.Values or a struct with query tags, got %T", query)
+	}
+
+	values := url.Values{}
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get("query")
+		if tag == "" || !field.IsExported() || v.Field(i).IsZero() {
+			continue
+		}
+		fieldValue := v.Field(i)
+		switch fieldValue.Kind() {
+		case reflect.Slice, reflect.Array:
+			for j := range fieldValue.Len() {
+				values.Add(tag, formatArg(fieldValue.Index(j).Interface()))
+			}
+		default:
+			values.Set(tag, formatArg(fieldValue.Interface()))
+		}
+	}
+	return values, nil
+}
+
+func formatArg(arg any) string {
+	switch arg := arg.(type) {
+	case string:
+		return arg
+	case encoding.TextMarshaler:
+		text, err := arg.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return arg.String()
+	}
+	return fmt.Sprint(arg)
+}
+
+// urlForTemplateFunc is the urlFor template function.
+// Arguments after the route name are key/value pairs: path parameters of the route, then query parameters.
+//
+//	<a href="{{ urlFor "getRecipe" "id" .ID "lang" "fr" }}">
+func (e *Engine) urlForTemplateFunc(name string, pairs ...any) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("urlFor %q: odd number of key/value arguments", name)
+	}
+
+	route, ok := e.routeByName(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+	wildcards := pathWildcards(route.Path)
+
+	args := PathArgs{}
+	query := url.Values{}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("urlFor %q: key must be a string, got %T", name, pairs[i])
+		}
+		if slices.Contains(wildcards, key) {
+			args[key] = pairs[i+1]
+		} else {
+			query.Add(key, formatArg(pairs[i+1]))
+		}
+	}
+
+	return e.URLFor(name, args, query)
+}
+
+// pathWildcards returns the names of the wildcards of the path pattern.
+func pathWildcards(pattern string) []string {
+	var wildcards []string
+	for _, segment := range strings.Split(pattern, "{")[1:] {
+		wildcard, _, _ := strings.Cut(segment, "}")
+		if wildcard != "$" {
+			wildcards = append(wildcards, strings.TrimSuffix(wildcard, "..."))
+		}
+	}
+	return wildcards
+}
+
+// TemplateFuncs returns the template functions provided by fuego, to parse templates before
+// giving them to [WithTemplates]. They are bound to the server routes by [WithTemplates].
+//
+//	tmpl := template.Must(template.New("").Funcs(fuego.TemplateFuncs()).ParseFS(templates, "*.html"))
+//
+// Available functions:
+//   - urlFor: builds the URL of a named route, see [Engine.URLFor].
+//     Arguments after the route name are path parameters and query parameters, as key/value pairs:
+//     {{ urlFor "getRecipe" "id" .ID "lang" "fr" }}
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"urlFor": func(name string, _ ...any) (string, error) {
+			return "", fmt.Errorf("urlFor %q: templates are not bound to a server, use fuego.WithTemplates", name)
+		},
+	}
+}
+
+func (e *Engine) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"urlFor": e.urlForTemplateFunc,
+	}
+}
+
+// checkTemplateURLs checks that the routes named in the urlFor calls of the templates exist,
+// so that a typo fails at startup rather than when rendering the page.
+func (e *Engine) checkTemplateURLs(templates *template.Template) error {
+	if templates == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, tmpl := range templates.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+		for _, name := range urlForNames(tmpl.Tree.Root) {
+			if _, ok := e.routeByName(name); !ok {
+				errs = append(errs, fmt.Errorf("template %q: urlFor: %w: %q", tmpl.Name(), ErrRouteNotFound, name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// urlForNames returns the route names given as string literals to urlFor in the template tree.
+func urlForNames(node parse.Node) []string {
+	var names []string
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, n := range node.Nodes {
+			names = append(names, urlForNames(n)...)
+		}
+	case *parse.ActionNode:
+		names = append(names, urlForNames(node.Pipe)...)
+	case *parse.PipeNode:
+		if node == nil {
+			return nil
+		}
+		for _, cmd := range node.Cmds {
+			names = append(names, urlForNames(cmd)...)
+		}
+	case *parse.CommandNode:
+		if len(node.Args) >= 2 {
+			if ident, ok := node.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "urlFor" {
+				if name, ok := node.Args[1].(*parse.StringNode); ok {
+					names = append(names, name.Text)
+				}
+			}
+		}
+		for _, arg := range node.Args {
+			names = append(names, urlForNames(arg)...)
+		}
+	case *parse.IfNode:
+		names = append(names, urlForNames(&node.BranchNode)...)
+	case *parse.RangeNode:
+		names = append(names, urlForNames(&node.BranchNode)...)
+	case *parse.WithNode:
+		names = append(names, urlForNames(&node.BranchNode)...)
+	case *parse.BranchNode:
+		names = append(names, urlForNames(node.Pipe)...)
+		names = append(names, urlForNames(node.List)...)
+		names = append(names, urlForNames(node.ElseList)...)
+	case *parse.TemplateNode:
+		names = append(names, urlForNames(node.Pipe)...)
+	}
+	return names
+}
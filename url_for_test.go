@@ -0,0 +1,156 @@
+package fuego
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLFor(t *testing.T) {
+	s := NewServer(WithBasePath("/v1"))
+	api := Group(s, "/api")
+
+	Get(api, "/recipes/{id}", func(c ContextNoBody) (ans, error) {
+		return ans{}, nil
+	}, OptionName("getRecipe"))
+	Get(api, "/recipes", func(c ContextNoBody) (ans, error) {
+		return ans{}, nil
+	})
+	GetStd(s, "/files/{path...}", func(w http.ResponseWriter, r *http.Request) {}, OptionName("file"))
+	GetStd(s, "/{$}", func(w http.ResponseWriter, r *http.Request) {}, OptionName("home"))
+
+	t.Run("path parameters and base path", func(t *testing.T) {
+		u, err := s.URLFor("getRecipe", PathArgs{"id": 3}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/api/recipes/3", u)
+	})
+
+	t.Run("default name is the operation ID", func(t *testing.T) {
+		u, err := s.URLFor("GET_/v1/api/recipes", nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/api/recipes", u)
+	})
+
+	t.Run("escaping and wildcards", func(t *testing.T) {
+		u, err := s.URLFor("getRecipe", PathArgs{"id": "a b/c"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/api/recipes/a%20b%2Fc", u)
+
+		u, err = s.URLFor("file", PathArgs{"path": "docs/a b.txt"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/files/docs/a%20b.txt", u)
+
+		u, err = s.URLFor("file", PathArgs{"path": "/docs/a.txt"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/files/docs/a.txt", u, "no double slash")
+
+		u, err = s.URLFor("home", nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/", u)
+	})
+
+	t.Run("query", func(t *testing.T) {
+		u, err := s.URLFor("getRecipe", PathArgs{"id": 3}, url.Values{"lang": {"fr"}})
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/api/recipes/3?lang=fr", u)
+
+		type params struct {
+			Lang  string   `query:"lang"`
+			Page  int      `query:"page"`
+			Tags  []string `query:"tag"`
+			Other string
+		}
+		u, err = s.URLFor("getRecipe", PathArgs{"id": 3}, params{Lang: "fr", Tags: []string{"a", "b"}, Other: "ignored"})
+		require.NoError(t, err)
+		assert.Equal(t, "/v1/api/recipes/3?lang=fr&tag=a&tag=b", u)
+
+		_, err = s.URLFor("getRecipe", PathArgs{"id": 3}, "lang=fr")
+		require.Error(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := s.URLFor("unknown", nil, nil)
+		require.ErrorIs(t, err, ErrRouteNotFound)
+
+		_, err = s.URLFor("getRecipe", nil, nil)
+		require.ErrorContains(t, err, `missing path parameter "id"`)
+
+		_, err = s.URLFor("getRecipe", PathArgs{"id": 3, "other": 4}, nil)
+		require.ErrorContains(t, err, "unknown path parameters [other]")
+
+		assert.Panics(t, func() { s.MustURLFor("unknown", nil, nil) })
+	})
+
+	t.Run("from the context", func(t *testing.T) {
+		Get(s, "/redirect", func(c ContextNoBody) (any, error) {
+			u, err := c.URLFor("getRecipe", PathArgs{"id": 3}, nil)
+			if err != nil {
+				return nil, err
+			}
+			return c.Redirect(http.StatusSeeOther, u)
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/redirect", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/v1/api/recipes/3", w.Header().Get("Location"))
+	})
+
+	t.Run("mock context without engine", func(t *testing.T) {
+		_, err := NewMockContextNoBody().URLFor("getRecipe", PathArgs{"id": 3}, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestURLForTemplates(t *testing.T) {
+	templates := template.Must(template.New("").Funcs(TemplateFuncs()).Parse(
+		`{{ define "link" }}<a href="{{ urlFor "getRecipe" "id" .ID "lang" "fr" }}">{{ .Name }}</a>{{ end }}`,
+	))
+	s := NewServer(WithTemplates(templates))
+
+	Get(s, "/recipes/{id}", func(c ContextNoBody) (ans, error) {
+		return ans{}, nil
+	}, OptionName("getRecipe"))
+	Get(s, "/link", func(c ContextNoBody) (CtxRenderer, error) {
+		return c.Render("link", H{"ID": 3, "Name": "Pizza"})
+	})
+
+	t.Run("render", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/link", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `<a href="/recipes/3?lang=fr">Pizza</a>`, w.Body.String())
+	})
+
+	t.Run("known names pass the startup check", func(t *testing.T) {
+		require.NoError(t, s.checkTemplateURLs(s.template))
+	})
+
+	t.Run("unknown names fail at startup", func(t *testing.T) {
+		templates := template.Must(template.New("").Funcs(TemplateFuncs()).Parse(
+			`{{ define "page" }}{{ if .OK }}<a href="{{ urlFor "getRecipee" "id" 1 }}">{{ end }}{{ end }}`,
+		))
+		s := NewServer(WithTemplates(templates), WithoutStartupMessages())
+		Get(s, "/recipes/{id}", func(c ContextNoBody) (ans, error) {
+			return ans{}, nil
+		}, OptionName("getRecipe"))
+
+		err := s.setup()
+		require.ErrorIs(t, err, ErrRouteNotFound)
+		require.ErrorContains(t, err, `"getRecipee"`)
+	})
+
+	t.Run("unbound template funcs", func(t *testing.T) {
+		_, err := TemplateFuncs()["urlFor"].(func(string, ...any) (string, error))("getRecipe")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,466 @@
+package fuego
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// APIVersion is a version of the API, served by the routes of a [Version] group
+// and documented in its own OpenAPI description.
+type APIVersion struct {
+	// Name of the version, like "v1". It prefixes the paths of the version with path versioning,
+	// and is the value expected in the request otherwise, see [WithVersioning].
+	Name string
+
+	// Description of the version, in its OpenAPI description. Defaults to the description of the API.
+	Description string
+
+	// Date from which the version is deprecated. Zero if the version is not deprecated.
+	// The responses of a deprecated version have the Deprecation header (RFC 9745)
+	// and its operations are deprecated in its OpenAPI description.
+	Deprecation time.Time
+
+	// Date after which the version will not be served anymore, sent in the Sunset header (RFC 8594).
+	// A version with a sunset date is deprecated, from its registration time if Deprecation is zero.
+	Sunset time.Time
+
+	// Link to the documentation of the deprecation, like a migration guide,
+	// sent in the Link header with the "deprecation" relation.
+	DeprecationLink string
+}
+
+func (v APIVersion) deprecated() bool {
+	return !v.Deprecation.IsZero() || !v.Sunset.IsZero()
+}
+
+// VersionSelector reads the version requested by the client, for the versions not selected by the path.
+// See [VersionFromHeader] and [VersionFromMediaType].
+type VersionSelector struct {
+	// Request header holding the version. Added to the Vary header of the responses.
+	Header string
+	// Version returns the version requested in the value of the header, "" for the default version.
+	Version func(value string) string
+}
+
+// VersionFromHeader selects the version with a request header, like "API-Version: v2".
+func VersionFromHeader(name string) VersionSelector {
+	return VersionSelector{
+		Header:  name,
+		Version: strings.TrimSpace,
+	}
+}
+
+// VersionFromMediaType selects the version with the media type of the Accept header,
+// like "Accept: application/vnd.acme.v2+json" for the vendor "acme",
+// or with its version parameter, like "Accept: application/json; version=v2".
+// Vendor media types are served as the media type of their suffix, application/json for +json.
+func VersionFromMediaType(vendor string) VersionSelector {
+	prefix := "application/vnd." + vendor + "."
+	return VersionSelector{
+		Header: "Accept",
+		Version: func(accept string) string {
+			for _, mediaRange := range strings.Split(accept, ",") {
+				mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+				if err != nil {
+					continue
+				}
+				if version := params["version"]; version != "" {
+					return version
+				}
+				if version, ok := strings.CutPrefix(mediaType, prefix); ok {
+					version, _, _ = strings.Cut(version, "+")
+					return version
+				}
+			}
+			return ""
+		},
+	}
+}
+
+// VersioningConfig configures how the version of the API is selected, see [WithVersioning].
+type VersioningConfig struct {
+	// Selects the version from the request. All versions are served on the same paths.
+	Selector VersionSelector
+	// Version served to the requests without version. Defaults to the last declared version.
+	Default string
+}
+
+// WithVersioning selects the versions of the API with a request header or the media type
+// instead of the path. See [Version].
+//
+//	s := fuego.NewServer(
+//		fuego.WithVersioning(fuego.VersioningConfig{
+//			Selector: fuego.VersionFromHeader("API-Version"),
+//			Default:  "v1",
+//		}),
+//	)
+func WithVersioning(config VersioningConfig) ServerOption {
+	return func(s *Server) {
+		if config.Selector.Header == "" || config.Selector.Version == nil {
+			panic("versioning selector must have a header and a version function")
+		}
+		s.versionRoutes = &versionRoutes{config: config, patterns: map[string]*versionPattern{}}
+	}
+}
+
+// Version creates a group serving a version of the API. Its routes are documented in the OpenAPI description
+// of the version, served next to the one of the API, like /swagger/v1/openapi.json with the UI at /swagger/v1/.
+//
+// By default, the version prefixes the paths of its routes, like /v1/pets.
+// With [WithVersioning], all the versions are served on the same paths, and the version is selected from the request.
+//
+//	v1 := fuego.Version(s, fuego.APIVersion{Name: "v1", Deprecation: deprecationDate, Sunset: sunsetDate})
+//	v2 := fuego.Version(s, fuego.APIVersion{Name: "v2"})
+//	fuego.Get(v1, "/pets", v1.ListPets)
+//	fuego.Get(v2, "/pets", v2.ListPets)
+func Version(s *Server, version APIVersion, routeOptions ...RouteOption) *Server {
+	newServer := *s
+	if s.versionRoutes == nil {
+		newServer.basePath += "/" + version.Name
+	}
+
+	v := s.Engine.addVersion(version, newServer.basePath)
+	newServer.version = v
+	newServer.routeOptions = slices.Concat([]RouteOption{v.routeOption}, s.routeOptions, routeOptions)
+	if version.deprecated() {
+		newServer.middlewares = append(slices.Clone(s.middlewares), v.middleware)
+	}
+
+	return &newServer
+}
+
+// versionedAPI is a version of the API registered on the engine.
+type versionedAPI struct {
+	APIVersion
+	OpenAPI *OpenAPI
+	// prefix of the paths of the version, removed to compare versions.
+	prefix string
+}
+
+// routeOption documents the route in the OpenAPI description of the version.
+func (v *versionedAPI) routeOption(r *BaseRoute) {
+	r.OpenAPI = v.OpenAPI
+	r.Version = v.Name
+	if v.deprecated() {
+		r.Operation.Deprecated = true
+	}
+}
+
+// middleware sends the deprecation headers of the version.
+func (v *versionedAPI) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setDeprecationHeaders(w.Header(), v.Deprecation, v.Sunset, v.DeprecationLink)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// addVersion registers a version of the API with its own OpenAPI description,
+// configured like the one of the engine.
+func (e *Engine) addVersion(version APIVersion, prefix string) *versionedAPI {
+	if version.Name == "" {
+		panic("version name cannot be empty")
+	}
+	if e.VersionOpenAPI(version.Name) != nil {
+		panic(fmt.Sprintf("version %q is already declared", version.Name))
+	}
+
+	openAPI := NewOpenAPI()
+	openAPI.Config = e.OpenAPI.Config
+	openAPI.Config.JSONFilePath = versionedFilePath(e.OpenAPI.Config.JSONFilePath, version.Name)
+	openAPI.Config.SpecURL = path.Join(path.Dir(e.OpenAPI.Config.SpecURL), version.Name, path.Base(e.OpenAPI.Config.SpecURL))
+	openAPI.Config.SwaggerURL = e.OpenAPI.Config.SwaggerURL + "/" + version.Name
+	openAPI.globalOpenAPIResponses = slices.Clone(e.OpenAPI.globalOpenAPIResponses)
+
+	info := *e.OpenAPI.Description().Info
+	info.Version = version.Name
+	if version.Description != "" {
+		info.Description = version.Description
+	}
+	openAPI.Description().Info = &info
+	openAPI.Description().Components.SecuritySchemes = e.OpenAPI.Description().Components.SecuritySchemes
+
+	if version.deprecated() && version.Deprecation.IsZero() {
+		version.Deprecation = time.Now()
+	}
+	v := &versionedAPI{APIVersion: version, OpenAPI: openAPI, prefix: prefix}
+
+	e.versionsMu.Lock()
+	defer e.versionsMu.Unlock()
+	e.versions = append(e.versions, v)
+
+	return v
+}
+
+// versionedFilePath returns the path of the JSON file of a version, like doc/openapi.v1.json.
+func versionedFilePath(filePath, version string) string {
+	ext := path.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "." + version + ext
+}
+
+// Versions returns the versions of the API declared with [Version], in declaration order.
+func (e *Engine) Versions() []APIVersion {
+	e.versionsMu.RLock()
+	defer e.versionsMu.RUnlock()
+
+	versions := make([]APIVersion, 0, len(e.versions))
+	for _, v := range e.versions {
+		versions = append(versions, v.APIVersion)
+	}
+	return versions
+}
+
+// VersionOpenAPI returns the OpenAPI description of a version of the API, nil if the version is not declared.
+func (e *Engine) VersionOpenAPI(name string) *OpenAPI {
+	if v := e.version(name); v != nil {
+		return v.OpenAPI
+	}
+	return nil
+}
+
+func (e *Engine) version(name string) *versionedAPI {
+	e.versionsMu.RLock()
+	defer e.versionsMu.RUnlock()
+
+	for _, v := range e.versions {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// versionedAPIs returns the declared versions.
+func (e *Engine) versionedAPIs() []*versionedAPI {
+	e.versionsMu.RLock()
+	defer e.versionsMu.RUnlock()
+
+	return slices.Clone(e.versions)
+}
+
+// outputVersionsOpenAPI outputs the OpenAPI descriptions of the versions, with the servers and security schemes of the API.
+func (e *Engine) outputVersionsOpenAPI() {
+	for _, v := range e.versionedAPIs() {
+		description := v.OpenAPI.Description()
+		description.Servers = e.OpenAPI.Description().Servers
+		if description.Components.SecuritySchemes == nil {
+			description.Components.SecuritySchemes = e.OpenAPI.Description().Components.SecuritySchemes
+		}
+		e.outputOpenAPI(v.OpenAPI)
+	}
+}
+
+// versionRoutes serves the versions of a path selected from the request, see [WithVersioning].
+type versionRoutes struct {
+	config   VersioningConfig
+	mu       sync.RWMutex
+	patterns map[string]*versionPattern
+	// versions registered, in registration order.
+	versions []string
+}
+
+// versionPattern is the handler of a mux pattern served by several versions.
+type versionPattern struct {
+	routes   *versionRoutes
+	handlers map[string]http.Handler
+}
+
+// register records the handler of the version for the pattern, and mounts the pattern on the mux.
+func (v *versionRoutes) register(mux *http.ServeMux, pattern, version string, handler http.Handler) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !slices.Contains(v.versions, version) {
+		v.versions = append(v.versions, version)
+	}
+
+	p := v.patterns[pattern]
+	if p == nil {
+		p = &versionPattern{routes: v, handlers: map[string]http.Handler{}}
+		v.patterns[pattern] = p
+		mux.Handle(pattern, p)
+	}
+	p.handlers[version] = handler
+}
+
+func (p *versionPattern) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	selector := p.routes.config.Selector
+	AddVary(w.Header(), selector.Header)
+
+	version := selector.Version(r.Header.Get(selector.Header))
+
+	p.routes.mu.RLock()
+	if version == "" {
+		version = p.routes.config.Default
+		if version == "" {
+			version = p.routes.versions[len(p.routes.versions)-1]
+		}
+	}
+	handler := p.handlers[version]
+	p.routes.mu.RUnlock()
+
+	if handler == nil {
+		SendError(w, r, NotFoundError{
+			Title:  "Version Not Found",
+			Detail: fmt.Sprintf("version %q is not available for %s %s", version, r.Method, r.URL.Path),
+		})
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// VersionChangelog lists the operations that differ between two versions of the API.
+// Operations are identified by their method and path, without the prefix of the version.
+type VersionChangelog struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	Added      []string `json:"added,omitempty"`
+	Removed    []string `json:"removed,omitempty"`
+	Changed    []string `json:"changed,omitempty"` // parameters, request body or responses changed
+	Deprecated []string `json:"deprecated,omitempty"`
+}
+
+// VersionChangelog compares the OpenAPI descriptions of two versions of the API.
+// Call it once all the routes are registered.
+func (e *Engine) VersionChangelog(from, to string) (VersionChangelog, error) {
+	fromVersion, toVersion := e.version(from), e.version(to)
+	if fromVersion == nil {
+		return VersionChangelog{}, fmt.Errorf("version %q is not declared", from)
+	}
+	if toVersion == nil {
+		return VersionChangelog{}, fmt.Errorf("version %q is not declared", to)
+	}
+
+	fromOperations := fromVersion.operations()
+	toOperations := toVersion.operations()
+
+	changelog := VersionChangelog{From: from, To: to}
+	for key, operation := range toOperations {
+		previous, ok := fromOperations[key]
+		switch {
+		case !ok:
+			changelog.Added = append(changelog.Added, key)
+		case operation.signature != previous.signature:
+			changelog.Changed = append(changelog.Changed, key)
+		}
+		if operation.Deprecated && (!ok || !previous.Deprecated) {
+			changelog.Deprecated = append(changelog.Deprecated, key)
+		}
+	}
+	for key := range fromOperations {
+		if _, ok := toOperations[key]; !ok {
+			changelog.Removed = append(changelog.Removed, key)
+		}
+	}
+
+	slices.Sort(changelog.Added)
+	slices.Sort(changelog.Removed)
+	slices.Sort(changelog.Changed)
+	slices.Sort(changelog.Deprecated)
+
+	return changelog, nil
+}
+
+// String formats the changelog as Markdown.
+func (c VersionChangelog) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Changes from %s to %s\n", c.From, c.To)
+	for _, section := range []struct {
+		title      string
+		operations []string
+	}{
+		{"Added", c.Added},
+		{"Removed", c.Removed},
+		{"Changed", c.Changed},
+		{"Deprecated", c.Deprecated},
+	} {
+		if len(section.operations) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", section.title)
+		for _, operation := range section.operations {
+			fmt.Fprintf(&b, "- `%s`\n", operation)
+		}
+	}
+	return b.String()
+}
+
+type versionOperation struct {
+	Deprecated bool
+	// signature of the parameters, request body and responses, with the component schemas resolved.
+	signature string
+}
+
+// operations returns the operations of the version, by method and path without the prefix of the version.
+func (v *versionedAPI) operations() map[string]versionOperation {
+	description := v.OpenAPI.Description()
+	operations := map[string]versionOperation{}
+	for p, pathItem := range description.Paths.Map() {
+		p = strings.TrimPrefix(p, v.prefix)
+		if p == "" {
+			p = "/"
+		}
+		for method, operation := range pathItem.Operations() {
+			operations[method+" "+p] = versionOperation{
+				Deprecated: operation.Deprecated,
+				signature:  operationSignature(operation, description.Components.Schemas),
+			}
+		}
+	}
+	return operations
+}
+
+// operationSignature describes the parameters, request body and responses of the operation.
+func operationSignature(operation *openapi3.Operation, schemas openapi3.Schemas) string {
+	var lines []string
+	for _, parameter := range operation.Parameters {
+		if parameter.Value == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("parameter %s %s %t %s",
+			parameter.Value.In, parameter.Value.Name, parameter.Value.Required, schemaSignature(parameter.Value.Schema, schemas)))
+	}
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		for contentType, mediaType := range operation.RequestBody.Value.Content {
+			lines = append(lines, fmt.Sprintf("body %s %s", contentType, schemaSignature(mediaType.Schema, schemas)))
+		}
+	}
+	if operation.Responses != nil {
+		for code, response := range operation.Responses.Map() {
+			if response.Value == nil {
+				continue
+			}
+			lines = append(lines, "response "+code)
+			for contentType, mediaType := range response.Value.Content {
+				lines = append(lines, fmt.Sprintf("response %s %s %s", code, contentType, schemaSignature(mediaType.Schema, schemas)))
+			}
+		}
+	}
+	slices.Sort(lines)
+	return strings.Join(lines, "\n")
+}
+
+// schemaSignature returns the JSON of the schema, resolving the reference to a component schema.
+func schemaSignature(schema *openapi3.SchemaRef, schemas openapi3.Schemas) string {
+	if schema == nil {
+		return ""
+	}
+	var value any = schema
+	if name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/"); ok && schemas[name] != nil {
+		value = schemas[name].Value
+	}
+	signature, err := json.Marshal(value)
+	if err != nil {
+		return schema.Ref
+	}
+	return string(signature)
+}
@@ -0,0 +1,245 @@
+package fuego
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type petV1 struct {
+	Name string `json:"name"`
+}
+
+type petV2 struct {
+	Name    string `json:"name"`
+	Species string `json:"species"`
+}
+
+func TestVersionByPath(t *testing.T) {
+	deprecation := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewServer(WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})))
+	v1 := Version(s, APIVersion{Name: "v1", Deprecation: deprecation, Sunset: sunset, DeprecationLink: "https://example.com/migrate"})
+	v2 := Version(s, APIVersion{Name: "v2", Description: "Second version"})
+
+	Get(v1, "/pets", func(c ContextNoBody) ([]petV1, error) {
+		return []petV1{{Name: "Rex"}}, nil
+	})
+	Get(v1, "/owners", func(c ContextNoBody) ([]string, error) {
+		return nil, nil
+	})
+	Get(v2, "/pets", func(c ContextNoBody) ([]petV2, error) {
+		return []petV2{{Name: "Rex", Species: "dog"}}, nil
+	})
+	Get(v2, "/pets/{id}", func(c ContextNoBody) (petV2, error) {
+		return petV2{}, nil
+	})
+	s.OutputOpenAPISpec()
+
+	t.Run("routes are prefixed by the version", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v2/pets", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"name":"Rex","species":"dog"}]`, w.Body.String())
+		assert.Empty(t, w.Header().Get("Deprecation"))
+	})
+
+	t.Run("deprecated version has deprecation headers", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/pets", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "@1767225600", w.Header().Get("Deprecation"))
+		assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+		assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"; type="text/html"`, w.Header().Get("Link"))
+	})
+
+	t.Run("each version has its own OpenAPI description", func(t *testing.T) {
+		assert.Nil(t, s.OpenAPI.Description().Paths.Find("/v1/pets"), "versions are not in the API description")
+
+		v1Doc := s.VersionOpenAPI("v1").Description()
+		require.NotNil(t, v1Doc.Paths.Find("/v1/pets"))
+		assert.Nil(t, v1Doc.Paths.Find("/v2/pets"))
+		assert.True(t, v1Doc.Paths.Find("/v1/pets").Get.Deprecated)
+		assert.Equal(t, "v1", v1Doc.Info.Version)
+		assert.Contains(t, v1Doc.Components.Schemas, "petV1")
+		assert.NotContains(t, v1Doc.Components.Schemas, "petV2")
+
+		v2Doc := s.VersionOpenAPI("v2").Description()
+		require.NotNil(t, v2Doc.Paths.Find("/v2/pets"))
+		assert.False(t, v2Doc.Paths.Find("/v2/pets").Get.Deprecated)
+		assert.Equal(t, "Second version", v2Doc.Info.Description)
+		assert.Contains(t, v2Doc.Components.Schemas, "petV2")
+		assert.Contains(t, v2Doc.Components.Schemas, "HTTPError", "default responses are documented in the version")
+
+		assert.Nil(t, s.VersionOpenAPI("v3"))
+		assert.Equal(t, []string{"v1", "v2"}, []string{s.Versions()[0].Name, s.Versions()[1].Name})
+	})
+
+	t.Run("spec and UI of the versions", func(t *testing.T) {
+		s.RegisterOpenAPIRoutes(s)
+
+		r := httptest.NewRequest(http.MethodGet, "/swagger/v1/openapi.json", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var doc struct {
+			Info  struct{ Version string }
+			Paths map[string]any
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		assert.Equal(t, "v1", doc.Info.Version)
+		assert.Contains(t, doc.Paths, "/v1/pets")
+
+		r = httptest.NewRequest(http.MethodGet, "/swagger/v2/index.html", nil)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "/swagger/v2/openapi.json")
+	})
+
+	t.Run("changelog", func(t *testing.T) {
+		changelog, err := s.VersionChangelog("v1", "v2")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"GET /pets/{id}"}, changelog.Added)
+		assert.Equal(t, []string{"GET /owners"}, changelog.Removed)
+		assert.Equal(t, []string{"GET /pets"}, changelog.Changed)
+		assert.Empty(t, changelog.Deprecated)
+		assert.Contains(t, changelog.String(), "### Added\n\n- `GET /pets/{id}`")
+
+		_, err = s.VersionChangelog("v1", "v3")
+		require.Error(t, err)
+	})
+
+	t.Run("duplicate version", func(t *testing.T) {
+		assert.Panics(t, func() { Version(s, APIVersion{Name: "v1"}) })
+	})
+
+	t.Run("version with only a sunset date is deprecated", func(t *testing.T) {
+		s := NewServer(WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})))
+		v1 := Version(s, APIVersion{Name: "v1", Sunset: sunset})
+		Get(v1, "/pets", func(c ContextNoBody) ([]petV1, error) {
+			return nil, nil
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/pets", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Deprecation"), "deprecated since the registration of the version")
+		assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	})
+}
+
+func TestVersionByHeader(t *testing.T) {
+	s := NewServer(
+		WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})),
+		WithVersioning(VersioningConfig{Selector: VersionFromHeader("API-Version"), Default: "v1"}),
+	)
+	v1 := Version(s, APIVersion{Name: "v1"})
+	v2 := Version(s, APIVersion{Name: "v2"})
+
+	Get(v1, "/pets", func(c ContextNoBody) (string, error) {
+		return "v1", nil
+	})
+	Get(v2, "/pets", func(c ContextNoBody) (string, error) {
+		return "v2", nil
+	})
+	Get(v2, "/owners", func(c ContextNoBody) (string, error) {
+		return "v2", nil
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		version string
+		code    int
+		body    string
+	}{
+		{name: "default version", path: "/pets", code: http.StatusOK, body: "v1"},
+		{name: "requested version", path: "/pets", version: "v2", code: http.StatusOK, body: "v2"},
+		{name: "unknown version", path: "/pets", version: "v3", code: http.StatusNotFound},
+		{name: "route not in the default version", path: "/owners", code: http.StatusNotFound},
+		{name: "route in the requested version", path: "/owners", version: "v2", code: http.StatusOK, body: "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.version != "" {
+				r.Header.Set("API-Version", tt.version)
+			}
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+
+			require.Equal(t, tt.code, w.Code)
+			assert.Equal(t, "API-Version", w.Header().Get("Vary"))
+			if tt.body != "" {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("versions share the paths in their own descriptions", func(t *testing.T) {
+		assert.NotNil(t, s.VersionOpenAPI("v1").Description().Paths.Find("/pets"))
+		assert.NotNil(t, s.VersionOpenAPI("v2").Description().Paths.Find("/pets"))
+		assert.Nil(t, s.VersionOpenAPI("v1").Description().Paths.Find("/owners"))
+	})
+}
+
+func TestVersionFromMediaType(t *testing.T) {
+	selector := VersionFromMediaType("acme")
+
+	assert.Equal(t, "Accept", selector.Header)
+	assert.Equal(t, "v2", selector.Version("application/vnd.acme.v2+json"))
+	assert.Equal(t, "v2", selector.Version("text/html, application/vnd.acme.v2+json;q=0.9"))
+	assert.Equal(t, "3", selector.Version("application/json; version=3"))
+	assert.Empty(t, selector.Version("application/json"))
+	assert.Empty(t, selector.Version("application/vnd.other.v2+json"))
+	assert.Empty(t, selector.Version(""))
+
+	t.Run("end to end", func(t *testing.T) {
+		s := NewServer(
+			WithEngineOptions(WithOpenAPIConfig(OpenAPIConfig{DisableLocalSave: true})),
+			WithVersioning(VersioningConfig{Selector: selector, Default: "v1"}),
+		)
+		Get(Version(s, APIVersion{Name: "v1"}), "/pets", func(c ContextNoBody) (ans, error) {
+			return ans{Ans: "v1"}, nil
+		})
+		Get(Version(s, APIVersion{Name: "v2"}), "/pets", func(c ContextNoBody) (ans, error) {
+			return ans{Ans: "v2"}, nil
+		})
+
+		tests := []struct {
+			accept      string
+			contentType string
+			body        string
+		}{
+			{accept: "application/vnd.acme.v2+json", contentType: "application/json", body: `{"ans":"v2"}`},
+			{accept: "application/vnd.acme.v2+xml", contentType: "application/xml", body: "<Ans>v2</Ans>"},
+			{accept: "application/json; version=v2", contentType: "application/json", body: `{"ans":"v2"}`},
+			{accept: "application/json", contentType: "application/json", body: `{"ans":"v1"}`},
+		}
+		for _, tt := range tests {
+			r := httptest.NewRequest(http.MethodGet, "/pets", nil)
+			r.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusOK, w.Code, tt.accept)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"), tt.accept)
+			assert.Contains(t, w.Body.String(), tt.body, tt.accept)
+		}
+	})
+}